@@ -1,8 +1,13 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/axosec/core/crypto/token"
@@ -10,9 +15,18 @@ import (
 	"github.com/axosec/vault/internal/api"
 	"github.com/axosec/vault/internal/config"
 	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/jwks"
+	"github.com/axosec/vault/internal/logging"
+	"github.com/axosec/vault/internal/metrics"
+	"github.com/axosec/vault/internal/migrate"
+	"github.com/axosec/vault/internal/ratelimit"
+	"github.com/axosec/vault/internal/server"
 	"github.com/axosec/vault/internal/service"
+	"github.com/axosec/vault/internal/tracing"
+	"github.com/axosec/vault/migrations"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
 )
@@ -31,57 +45,248 @@ var (
 // @BasePath  /v1
 
 func main() {
+	os.Exit(run())
+}
+
+// run starts the server and blocks until it stops. Every exit goes through a
+// return, so the deferred cleanup, the pool and tracing flush included, runs
+// before main exits with the returned code.
+func run() int {
+	// Log JSON at info until the configured level is known
+	logLevel := new(slog.LevelVar)
+	slog.SetDefault(logging.New(os.Stdout, logLevel))
+
 	// Version info
-	fmt.Printf(`Starting Axosec Vault
-Version: %s
-Commit: %s
-Built:  %s
+	slog.Info("starting axosec vault", "version", Version, "commit", GitCommit, "built", BuildDate)
 
-`, Version, GitCommit, BuildDate)
 	// Load config
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		fmt.Printf("failed to load config: %s\n", err)
-		return
+		slog.Error("failed to load config", "error", err)
+		return 1
+	}
+
+	if err := logLevel.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		slog.Error("invalid log level", "error", err)
+		return 1
+	}
+
+	// Setup tracing before the db pool so queries are traced
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, Version)
+	if err != nil {
+		slog.Error("failed to setup tracing", "error", err)
+		return 1
 	}
+	defer shutdownTracing(context.Background())
 
 	// Setup keys and JWT
 	privateKey, publicKey, err := token.LoadKeysFromFiles(cfg.JWT.PrivateKeyPath, cfg.JWT.PublicKeyPath)
 	if err != nil {
-		fmt.Println(err)
-		return
+		slog.Error("failed to load jwt keys", "error", err)
+		return 1
+	}
+
+	// User tokens are checked against the auth service's JWKS when configured,
+	// so it can rotate keys without restarting replicas
+	jwtOpts := jwks.Options{
+		Audience:           cfg.JWT.Audience,
+		Leeway:             time.Duration(cfg.JWT.LeewaySeconds) * time.Second,
+		RefreshInterval:    time.Duration(cfg.JWT.JWKSRefreshSeconds) * time.Second,
+		MinRefreshInterval: time.Duration(cfg.JWT.JWKSMinRefreshSeconds) * time.Second,
+		StaticMethods:      cfg.JWT.StaticAlgorithms,
 	}
 
-	jwtManager := token.NewJWTManager(privateKey, publicKey, cfg.JWT.Issuer)
+	var jwtVerifier *jwks.Verifier
+	if cfg.JWT.JWKSURL != "" {
+		jwtVerifier, err = jwks.New(context.Background(), cfg.JWT.JWKSURL, jwtOpts)
+		if err != nil {
+			// Keep starting, Run keeps fetching the keys
+			slog.Warn("initial jwks fetch failed", "source", cfg.JWT.JWKSURL, "error", err)
+		}
+	} else {
+		jwtVerifier = jwks.NewStatic(publicKey, jwtOpts)
+	}
 
 	// Setup db connection
 	connPool, err := db.NewConnection(cfg.Database)
 	if err != nil {
-		fmt.Printf("failed to connect to database: %s", err)
-		return
+		slog.Error("failed to connect to database", "error", err)
+		return 1
 	}
+	defer connPool.Close()
 
 	queries := db.New(connPool)
 
+	// Bring the schema up to date, replicas wait on an advisory lock
+	migrator, err := migrate.New(connPool, migrations.FS)
+	if err != nil {
+		slog.Error("failed to load migrations", "error", err)
+		return 1
+	}
+
+	if cfg.AutoMigrate {
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			slog.Error("failed to apply migrations", "error", err)
+			return 1
+		}
+		for _, m := range applied {
+			slog.Info("applied migration", "version", m.Version, "name", m.Name)
+		}
+	}
+
+	// Never run against a schema this binary was not built for
+	if err := migrator.Check(context.Background()); err != nil {
+		slog.Error("database schema does not match this build", "error", err)
+		return 1
+	}
+
+	auditChainService := service.NewAuditChainService(connPool, queries, privateKey, publicKey)
+
 	// Initialize services
 	vaultService := service.NewVaultService(connPool, queries)
+	orgService := service.NewOrgService(connPool, queries)
+	emergencyService := service.NewEmergencyService(connPool, queries, cfg.Emergency.DefaultWaitHours)
+	accountService := service.NewServiceAccountService(connPool, queries, cfg.APIToken.MaxLifetimeDays)
+	revocationService := service.NewRevocationService(connPool, queries, cfg.Revocation.CacheSeconds)
+	webhookService := service.NewWebhookService(connPool, queries, time.Duration(cfg.Webhook.TimeoutSeconds)*time.Second, cfg.Webhook.MaxAttempts)
+
+	// Background jobs stop once requests are drained, before the pool closes
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	defer func() {
+		stopJobs()
+		jobs.Wait()
+	}()
+
+	// Start background jobs
+	jobs.Go(func() {
+		emergencyService.RunReleaseJob(jobsCtx, time.Duration(cfg.Emergency.ReleaseIntervalSeconds)*time.Second)
+	})
+	jobs.Go(func() {
+		auditChainService.RunCheckpointJob(jobsCtx, time.Duration(cfg.Audit.CheckpointIntervalSeconds)*time.Second)
+	})
+	jobs.Go(func() {
+		webhookService.RunDeliveryJob(jobsCtx, time.Duration(cfg.Webhook.DeliveryIntervalSeconds)*time.Second)
+	})
+	jobs.Go(func() {
+		jwtVerifier.Run(jobsCtx)
+	})
+	jobs.Go(func() {
+		revocationService.RunCleanupJob(jobsCtx, time.Duration(cfg.Revocation.CleanupIntervalSeconds)*time.Second)
+	})
+
+	// Serve metrics on their own port so they are not exposed with the api
+	prometheus.MustRegister(metrics.NewPoolCollector(connPool), metrics.NewTotalsCollector(queries))
+	jobs.Go(func() {
+		if err := metrics.Serve(jobsCtx, cfg.MetricsPort); err != nil {
+			slog.Error("metrics server stopped", "error", err)
+		}
+	})
+
+	// Rate limiting
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit.Backend != "none" {
+		var store ratelimit.Store = ratelimit.NewMemoryStore()
+		if cfg.RateLimit.Backend == "postgres" {
+			store = ratelimit.NewPostgresStore(queries)
+		}
+
+		limiter = ratelimit.NewLimiter(store, map[ratelimit.Class]ratelimit.Limit{
+			ratelimit.ClassRead:  {Rate: cfg.RateLimit.ReadRate, Burst: cfg.RateLimit.ReadBurst},
+			ratelimit.ClassWrite: {Rate: cfg.RateLimit.WriteRate, Burst: cfg.RateLimit.WriteBurst},
+			ratelimit.ClassShare: {Rate: cfg.RateLimit.ShareRate, Burst: cfg.RateLimit.ShareBurst},
+			ratelimit.ClassIP:    {Rate: cfg.RateLimit.IPRate, Burst: cfg.RateLimit.IPBurst},
+		})
+
+		// Idle buckets are full once their window has passed
+		jobs.Go(func() {
+			ratelimit.RunCleanup(jobsCtx, store, max(limiter.MaxWindow(), time.Minute))
+		})
+	}
 
 	// Start http router
-	apiHandler := api.NewHandler(jwtManager, vaultService)
+	apiHandler := api.NewHandler(jwtVerifier, vaultService, orgService, emergencyService, webhookService, accountService, revocationService, limiter, cfg.AllowedOrigins, connPool, migrator)
 
-	r := gin.Default()
+	// Request logging and recovery are slog middlewares in RegisterRouters
+	r := gin.New()
 	r.Use(cors.New(cors.Config{
-		AllowOrigins: []string{"http://localhost:5173", "http://localhost:5174"},
-		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
-		AllowHeaders: []string{
-			"Content-Type",
-		},
+		AllowOrigins:     cfg.AllowedOrigins,
+		AllowMethods:     cfg.CORS.AllowedMethods,
+		AllowHeaders:     cfg.CORS.AllowedHeaders,
+		ExposeHeaders:    cfg.CORS.ExposedHeaders,
 		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+		MaxAge:           time.Duration(cfg.CORS.MaxAgeSeconds) * time.Second,
 	}))
+	// Client IPs feed the audit log and rate limits, so X-Forwarded-For is
+	// only read from the configured proxies
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		slog.Error("invalid trusted proxies", "error", err)
+		return 1
+	}
 	apiHandler.RegisterRouters(r)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	if err := r.Run(":" + cfg.ServerPort); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+
+	tlsConfig, err := server.NewTLSConfig(jobsCtx, cfg.TLS)
+	if err != nil {
+		slog.Error("failed to setup tls", "error", err)
+		return 1
+	}
+
+	ln, err := server.Listen(cfg.ServerPort, cfg.ServerSocket)
+	if err != nil {
+		slog.Error("failed to listen", "error", err)
+		return 1
+	}
+
+	srv := &http.Server{
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConfig,
 	}
+
+	sigCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stopSignals()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", ln.Addr().String(), "tls", tlsConfig != nil, "mtls", tlsConfig != nil && tlsConfig.ClientCAs != nil)
+		if tlsConfig != nil {
+			// The certificate comes from TLSConfig.GetCertificate
+			serveErr <- srv.ServeTLS(ln, "", "")
+			return
+		}
+		serveErr <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		slog.Error("failed to start server", "error", err)
+		return 1
+	case <-sigCtx.Done():
+	}
+
+	// A second signal skips the drain and kills the process
+	stopSignals()
+
+	// Fail readiness while still serving, so load balancers stop routing
+	// here before the listener closes
+	slog.Info("shutting down", "drain_delay_seconds", cfg.Shutdown.DrainDelaySeconds, "timeout_seconds", cfg.Shutdown.TimeoutSeconds)
+	apiHandler.Drain()
+	time.Sleep(time.Duration(cfg.Shutdown.DrainDelaySeconds) * time.Second)
+
+	// Stop accepting connections and let in-flight requests, e.g. UpdateItem
+	// transactions, finish within the timeout
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Shutdown.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("requests did not drain in time", "error", err)
+	}
+
+	stopJobs()
+	jobs.Wait()
+
+	slog.Info("shutdown complete")
+	return 0
 }
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/axosec/core/crypto/token"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/migrate"
+	"github.com/axosec/vault/internal/service"
+	"github.com/axosec/vault/migrations"
+	"github.com/google/uuid"
+)
+
+// parseUserID parses flags around the user id argument, so both
+// "user export <id> --out f" and "user export --out f <id>" work.
+func parseUserID(fs *flag.FlagSet, args []string) (uuid.UUID, error) {
+	if err := fs.Parse(args); err != nil {
+		return uuid.Nil, err
+	}
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		return uuid.Nil, errUsage
+	}
+
+	userID, err := uuid.Parse(rest[0])
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	if err := fs.Parse(rest[1:]); err != nil {
+		return uuid.Nil, err
+	}
+
+	if len(fs.Args()) > 0 {
+		return uuid.Nil, errUsage
+	}
+
+	return userID, nil
+}
+
+func runMigrate(ctx context.Context, e *env, args []string) (int, error) {
+	fs := e.flags("migrate")
+	dir := fs.String("dir", "", "read migrations from this directory instead of the embedded ones")
+	if err := fs.Parse(args); err != nil {
+		return 0, err
+	}
+
+	// up is the default, down takes an optional number of steps
+	action, rest := "up", fs.Args()
+	if len(rest) > 0 {
+		action, rest = rest[0], rest[1:]
+	}
+
+	steps := 1
+	if action == "down" && len(rest) > 0 {
+		n, err := strconv.Atoi(rest[0])
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid number of steps %q", rest[0])
+		}
+		steps, rest = n, rest[1:]
+	}
+
+	if err := fs.Parse(rest); err != nil {
+		return 0, err
+	}
+
+	if len(fs.Args()) > 0 {
+		return 0, errUsage
+	}
+
+	if err := e.connect(); err != nil {
+		return 0, err
+	}
+
+	var source iofs.FS = migrations.FS
+	if *dir != "" {
+		source = os.DirFS(*dir)
+	}
+
+	migrator, err := migrate.New(e.pool, source)
+	if err != nil {
+		return 0, err
+	}
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return e.printMigrations("Applied", applied)
+
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return 0, err
+		}
+		return e.printMigrations("Reverted", reverted)
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err := e.print(status, func(w io.Writer) {
+			for _, m := range status.Migrations {
+				mark := "pending"
+				if m.Applied {
+					mark = "applied"
+				}
+				fmt.Fprintf(w, "%-8s %d_%s\n", mark, m.Version, m.Name)
+			}
+			fmt.Fprintf(w, "Schema at version %d, latest is %d\n", status.Version, status.Latest)
+			if status.Dirty {
+				fmt.Fprintln(w, "Schema is DIRTY")
+			}
+		}); err != nil {
+			return 0, err
+		}
+
+		// Scripts can tell an up to date schema from the exit code
+		if status.Dirty || status.Version != status.Latest {
+			return exitProblems, nil
+		}
+		return exitOK, nil
+
+	default:
+		return 0, errUsage
+	}
+}
+
+func (e *env) printMigrations(verb string, done []migrate.Migration) (int, error) {
+	return e.print(done, func(w io.Writer) {
+		for _, m := range done {
+			fmt.Fprintf(w, "%s %d_%s\n", verb, m.Version, m.Name)
+		}
+		if len(done) == 0 {
+			fmt.Fprintln(w, "Nothing to do")
+		}
+	})
+}
+
+func runPurge(ctx context.Context, e *env, args []string) (int, error) {
+	fs := e.flags("purge")
+	days := fs.Int("days", 30, "purge resources trashed more than this many days ago")
+	if err := fs.Parse(args); err != nil {
+		return 0, err
+	}
+
+	if *days < 0 {
+		return 0, fmt.Errorf("--days must not be negative")
+	}
+
+	if err := e.connect(); err != nil {
+		return 0, err
+	}
+
+	before := time.Now().Add(-time.Duration(*days) * 24 * time.Hour)
+
+	result, err := e.adminService().Purge(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Purged %d items and %d folders trashed before %s\n", result.Items, result.Folders, before.Format(time.RFC3339))
+	})
+}
+
+func runStats(ctx context.Context, e *env, args []string) (int, error) {
+	if err := e.flags("stats").Parse(args); err != nil {
+		return 0, err
+	}
+
+	if err := e.connect(); err != nil {
+		return 0, err
+	}
+
+	stats, err := e.adminService().Stats(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.print(stats, func(w io.Writer) {
+		fmt.Fprintf(w, "Folders:             %d (%d in trash)\n", stats.Folders, stats.TrashedFolders)
+		fmt.Fprintf(w, "Items:               %d (%d in trash)\n", stats.Items, stats.TrashedItems)
+		fmt.Fprintf(w, "Grants:              %d\n", stats.Grants)
+		fmt.Fprintf(w, "Users:               %d\n", stats.Users)
+		fmt.Fprintf(w, "Organizations:       %d\n", stats.Organizations)
+		fmt.Fprintf(w, "Groups:              %d\n", stats.Groups)
+		fmt.Fprintf(w, "Service accounts:    %d (%d active tokens)\n", stats.ServiceAccounts, stats.ActiveAPITokens)
+		fmt.Fprintf(w, "Projects:            %d\n", stats.Projects)
+		fmt.Fprintf(w, "Audit events:        %d\n", stats.AuditEvents)
+		fmt.Fprintf(w, "Webhook deliveries:  %d pending, %d dead\n", stats.PendingWebhookDeliveries, stats.DeadWebhookDeliveries)
+	})
+}
+
+func runUserRevokeAll(ctx context.Context, e *env, args []string) (int, error) {
+	userID, err := parseUserID(e.flags("user revoke-all"), args)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := e.connect(); err != nil {
+		return 0, err
+	}
+
+	result, err := e.adminService().RevokeAllGrants(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Revoked %d grants and %d group memberships of %s\n", result.Grants, result.Groups, userID)
+	})
+}
+
+func runUserRevokeSessions(ctx context.Context, e *env, args []string) (int, error) {
+	userID, err := parseUserID(e.flags("user revoke-sessions"), args)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := e.connect(); err != nil {
+		return 0, err
+	}
+
+	// Nothing is looked up here, so the lookup cache is not used
+	revokedBefore, err := service.NewRevocationService(e.pool, e.q, 0).RevokeAllSessions(ctx, uuid.Nil, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	result := dto.SessionsRevoked{RevokedBefore: revokedBefore}
+	return e.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Tokens of %s issued before %s are rejected\n", userID, revokedBefore.Format(time.RFC3339))
+		fmt.Fprintln(w, "API replicas apply this once their revocation cache expires (REVOCATION_CACHE_SECONDS)")
+	})
+}
+
+func runUserExport(ctx context.Context, e *env, args []string) (int, error) {
+	fs := e.flags("user export")
+	out := fs.String("out", "", "write the export to this file instead of stdout")
+	userID, err := parseUserID(fs, args)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := e.connect(); err != nil {
+		return 0, err
+	}
+
+	export, err := e.adminService().ExportUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// The export is data, not a report, so it is JSON in both output modes
+	if *out == "" {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return exitOK, enc.Encode(export)
+	}
+
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(export); err != nil {
+		return 0, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	summary := struct {
+		UserID  uuid.UUID `json:"user_id"`
+		File    string    `json:"file"`
+		Folders int       `json:"folders"`
+		Items   int       `json:"items"`
+	}{userID, *out, len(export.Folders), len(export.Items)}
+
+	return e.print(summary, func(w io.Writer) {
+		fmt.Fprintf(w, "Exported %d folders and %d items of %s to %s\n", summary.Folders, summary.Items, userID, *out)
+	})
+}
+
+func runAuditVerify(ctx context.Context, e *env, args []string) (int, error) {
+	if err := e.flags("audit verify").Parse(args); err != nil {
+		return 0, err
+	}
+
+	if err := e.connect(); err != nil {
+		return 0, err
+	}
+
+	privateKey, publicKey, err := token.LoadKeysFromFiles(e.cfg.JWT.PrivateKeyPath, e.cfg.JWT.PublicKeyPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load jwt keys: %w", err)
+	}
+
+	report, err := service.NewAuditChainService(e.pool, e.q, privateKey, publicKey).Verify(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("audit verification failed: %w", err)
+	}
+
+	if _, err := e.print(report, func(w io.Writer) {
+		fmt.Fprintf(w, "Checked %d chained events against %d checkpoints\n", report.Checked, report.Checkpoints)
+		if report.Unchained > 0 {
+			fmt.Fprintf(w, "%d events predate the hash chain and were not checked\n", report.Unchained)
+		}
+
+		for _, b := range report.Breaks {
+			if b.EventID != nil {
+				fmt.Fprintf(w, "BREAK at event %s: %s\n", b.EventID, b.Reason)
+				continue
+			}
+			fmt.Fprintf(w, "BREAK at seq %d: %s\n", b.Seq, b.Reason)
+		}
+
+		if len(report.Breaks) == 0 {
+			fmt.Fprintln(w, "Audit chain intact")
+		} else {
+			fmt.Fprintf(w, "%d break(s) found\n", len(report.Breaks))
+		}
+	}); err != nil {
+		return 0, err
+	}
+
+	if len(report.Breaks) > 0 {
+		return exitProblems, nil
+	}
+
+	return exitOK, nil
+}
+
+func runFsck(ctx context.Context, e *env, args []string) (int, error) {
+	if err := e.flags("fsck").Parse(args); err != nil {
+		return 0, err
+	}
+
+	if err := e.connect(); err != nil {
+		return 0, err
+	}
+
+	report, err := e.adminService().Fsck(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := e.print(report, func(w io.Writer) {
+		for _, check := range report.Checks {
+			if len(check.IDs) == 0 {
+				fmt.Fprintf(w, "ok    %s\n", check.Name)
+				continue
+			}
+
+			fmt.Fprintf(w, "FAIL  %s: %d found. %s\n", check.Name, len(check.IDs), check.Description)
+			for _, id := range check.IDs {
+				fmt.Fprintf(w, "        %s\n", id)
+			}
+		}
+	}); err != nil {
+		return 0, err
+	}
+
+	if report.Problems > 0 {
+		return exitProblems, nil
+	}
+
+	return exitOK, nil
+}
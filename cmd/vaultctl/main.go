@@ -0,0 +1,191 @@
+// Command vaultctl runs operator tasks against the vault database: schema
+// migrations, trash purging, stats, per-user grant revocation and export,
+// audit chain verification and consistency checks.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/axosec/vault/internal/config"
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/logging"
+	"github.com/axosec/vault/internal/service"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	Version   = "dev"
+	GitCommit = "unknown"
+	BuildDate = "unknown"
+)
+
+// Exit codes, checks that find problems exit with exitProblems
+const (
+	exitOK       = 0
+	exitProblems = 1
+	exitFailure  = 2
+)
+
+const usage = `Usage: vaultctl [--json] <command> [flags]
+
+Commands:
+  migrate [up]            Apply pending database migrations
+  migrate down [n]        Revert the last n migrations, 1 by default
+  migrate status          List migrations and the schema version
+  purge                   Permanently delete trashed folders and items
+  stats                   Print vault statistics
+  user revoke-all <id>    Revoke every grant shared with a user
+  user revoke-sessions <id>
+                          Sign a user out everywhere by rejecting their tokens
+  user export <id>        Export a user's encrypted folders and items
+  audit verify            Verify the audit hash chain
+  fsck                    Check the database for inconsistencies
+  version                 Print the vaultctl version
+
+vaultctl reads the same environment and .env file as the API server.
+Run "vaultctl <command> -h" for the flags of a command.
+`
+
+var errUsage = errors.New("invalid usage")
+
+// env holds what commands share: the output mode and lazily opened resources.
+type env struct {
+	json   bool
+	stdout io.Writer
+	cfg    *config.Config
+	pool   *pgxpool.Pool
+	q      *db.Queries
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:]))
+}
+
+func run(ctx context.Context, args []string) int {
+	// Logs go to stderr so --json output stays parseable
+	slog.SetDefault(logging.New(os.Stderr, slog.LevelWarn))
+
+	e := &env{stdout: os.Stdout}
+
+	fs := flag.NewFlagSet("vaultctl", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	fs.BoolVar(&e.json, "json", false, "print results as JSON")
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitFailure
+	}
+
+	defer e.close()
+
+	code, err := dispatch(ctx, e, fs.Arg(0), fs.Args()[1:])
+	if err != nil {
+		if errors.Is(err, errUsage) {
+			fs.Usage()
+		} else if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "vaultctl: %s\n", err)
+		}
+		return exitFailure
+	}
+
+	return code
+}
+
+func dispatch(ctx context.Context, e *env, command string, args []string) (int, error) {
+	switch command {
+	case "migrate":
+		return runMigrate(ctx, e, args)
+	case "purge":
+		return runPurge(ctx, e, args)
+	case "stats":
+		return runStats(ctx, e, args)
+	case "fsck":
+		return runFsck(ctx, e, args)
+	case "version":
+		return e.print(map[string]string{"version": Version, "commit": GitCommit, "built": BuildDate}, func(w io.Writer) {
+			fmt.Fprintf(w, "vaultctl %s (commit %s, built %s)\n", Version, GitCommit, BuildDate)
+		})
+	case "user", "audit":
+		if len(args) == 0 {
+			return 0, errUsage
+		}
+		return dispatch(ctx, e, command+" "+args[0], args[1:])
+	case "user revoke-all":
+		return runUserRevokeAll(ctx, e, args)
+	case "user revoke-sessions":
+		return runUserRevokeSessions(ctx, e, args)
+	case "user export":
+		return runUserExport(ctx, e, args)
+	case "audit verify":
+		return runAuditVerify(ctx, e, args)
+	default:
+		return 0, errUsage
+	}
+}
+
+// flags returns a flag set for a command that also accepts --json after the
+// command name.
+func (e *env) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("vaultctl "+name, flag.ContinueOnError)
+	fs.BoolVar(&e.json, "json", e.json, "print results as JSON")
+	return fs
+}
+
+// connect loads the config and opens the database pool on first use.
+func (e *env) connect() error {
+	if e.pool != nil {
+		return nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	pool, err := db.NewConnection(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	e.cfg = cfg
+	e.pool = pool
+	e.q = db.New(pool)
+
+	return nil
+}
+
+func (e *env) close() {
+	if e.pool != nil {
+		e.pool.Close()
+	}
+}
+
+// print writes v as JSON in --json mode and calls text otherwise.
+func (e *env) print(v any, text func(w io.Writer)) (int, error) {
+	if !e.json {
+		text(e.stdout)
+		return exitOK, nil
+	}
+
+	enc := json.NewEncoder(e.stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return 0, err
+	}
+
+	return exitOK, nil
+}
+
+func (e *env) adminService() *service.AdminService {
+	return service.NewAdminService(e.pool, e.q)
+}
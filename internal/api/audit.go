@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/axosec/vault/internal/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListAuditEventsHandler godoc
+// @Summary      Audit Log
+// @Description  List audit events on resources the caller owns and in organizations where they are ADMIN or OWNER, newest first.
+// @Tags         Audit
+// @Produce      json
+// @Param        since          query  string false "Only events at or after this RFC 3339 time"
+// @Param        until          query  string false "Only events before this RFC 3339 time"
+// @Param        actor_id       query  string false "Actor UUID"
+// @Param        resource_id    query  string false "Resource UUID"
+// @Param        resource_type  query  string false "FOLDER, ITEM or ORGANIZATION"
+// @Param        action         query  string false "Action, e.g. READ or SHARED"
+// @Param        limit          query  int    false "Maximum number of events (default 100, max 500)"
+// @Success      200  {array}   dto.AuditEvent
+// @Failure      400  {object}  map[string]string "Invalid query"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /audit [get]
+func (h *Handler) ListAuditEventsHandler(c *gin.Context) {
+	var query dto.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	events, err := h.vaultService.ListAuditEvents(c.Request.Context(), userID, query)
+	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// respondEmergencyError maps emergency access service errors to HTTP responses.
+func respondEmergencyError(c *gin.Context, err error, fallback string) {
+	c.Error(err)
+
+	switch {
+	case errors.Is(err, service.ErrEmergencyContactNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Emergency contact not found"})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found or access denied"})
+	case errors.Is(err, service.ErrEmergencySelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot name yourself as emergency contact"})
+	case errors.Is(err, service.ErrEmergencyContactExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Emergency contact already exists"})
+	case errors.Is(err, service.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": "Action not allowed in the current state"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// CreateEmergencyContactHandler godoc
+// @Summary      Add Emergency Contact
+// @Description  Name a trusted user who may request access to pre-wrapped keys after a waiting period.
+// @Tags         Emergency Access
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreateEmergencyContactReq true "Contact details"
+// @Success      201  {object}  dto.EmergencyContact
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      409  {object}  map[string]string "Contact already exists"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /emergency/contacts [post]
+func (h *Handler) CreateEmergencyContactHandler(c *gin.Context) {
+	var req dto.CreateEmergencyContactReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	contact, err := h.emergencyService.CreateContact(c.Request.Context(), userID, req)
+	if err != nil {
+		respondEmergencyError(c, err, "Failed to create emergency contact")
+		return
+	}
+
+	c.JSON(http.StatusCreated, contact)
+}
+
+// ListEmergencyContactsHandler godoc
+// @Summary      List Emergency Contacts
+// @Description  List the caller's emergency contacts and the users who named the caller as theirs.
+// @Tags         Emergency Access
+// @Produce      json
+// @Success      200  {object}  dto.EmergencyContacts
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /emergency/contacts [get]
+func (h *Handler) ListEmergencyContactsHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	contacts, err := h.emergencyService.ListContacts(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch emergency contacts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, contacts)
+}
+
+// DeleteEmergencyContactHandler godoc
+// @Summary      Remove Emergency Contact
+// @Description  Remove an emergency contact along with its pre-wrapped keys. Keys already released stay until revoked.
+// @Tags         Emergency Access
+// @Param        id   path      string true "Contact UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Contact not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /emergency/contacts/{id} [delete]
+func (h *Handler) DeleteEmergencyContactHandler(c *gin.Context) {
+	contactID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.emergencyService.DeleteContact(c.Request.Context(), userID, contactID); err != nil {
+		respondEmergencyError(c, err, "Failed to delete emergency contact")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetEmergencyKeysHandler godoc
+// @Summary      Set Emergency Keys
+// @Description  Upload resource keys pre-wrapped for the emergency contact. The caller must own every resource.
+// @Tags         Emergency Access
+// @Accept       json
+// @Param        id   path      string true "Contact UUID"
+// @Param        request body dto.SetEmergencyKeysReq true "Wrapped keys"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Contact or resource not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /emergency/contacts/{id}/keys [put]
+func (h *Handler) SetEmergencyKeysHandler(c *gin.Context) {
+	contactID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	var req dto.SetEmergencyKeysReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.emergencyService.SetKeys(c.Request.Context(), userID, contactID, req); err != nil {
+		respondEmergencyError(c, err, "Failed to store emergency keys")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RequestEmergencyAccessHandler godoc
+// @Summary      Request Emergency Access
+// @Description  Start the waiting period. The keys are released when it ends unless the grantor rejects the request.
+// @Tags         Emergency Access
+// @Param        id   path      string true "Contact UUID"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Contact not found"
+// @Failure      409  {object}  map[string]string "Invalid state"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /emergency/contacts/{id}/request [post]
+func (h *Handler) RequestEmergencyAccessHandler(c *gin.Context) {
+	contactID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.emergencyService.RequestAccess(c.Request.Context(), userID, contactID); err != nil {
+		respondEmergencyError(c, err, "Failed to request emergency access")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ApproveEmergencyAccessHandler godoc
+// @Summary      Approve Emergency Access
+// @Description  Release a pending request immediately instead of waiting for the period to end.
+// @Tags         Emergency Access
+// @Param        id   path      string true "Contact UUID"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Contact not found"
+// @Failure      409  {object}  map[string]string "Invalid state"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /emergency/contacts/{id}/approve [post]
+func (h *Handler) ApproveEmergencyAccessHandler(c *gin.Context) {
+	contactID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.emergencyService.ApproveRequest(c.Request.Context(), userID, contactID); err != nil {
+		respondEmergencyError(c, err, "Failed to approve emergency access")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RejectEmergencyAccessHandler godoc
+// @Summary      Reject Emergency Access
+// @Description  Reject a pending request during its waiting period.
+// @Tags         Emergency Access
+// @Param        id   path      string true "Contact UUID"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Contact not found"
+// @Failure      409  {object}  map[string]string "Invalid state"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /emergency/contacts/{id}/reject [post]
+func (h *Handler) RejectEmergencyAccessHandler(c *gin.Context) {
+	contactID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.emergencyService.RejectRequest(c.Request.Context(), userID, contactID); err != nil {
+		respondEmergencyError(c, err, "Failed to reject emergency access")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -1,9 +1,11 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -17,12 +19,14 @@ import (
 // @Param        request body dto.CreateFolderReq true "Folder creation details"
 // @Success      201  {object}  dto.FolderResponse
 // @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Not a member of the organization"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /folders [post]
 func (h *Handler) CreateFolderHandler(c *gin.Context) {
 	var req dto.CreateFolderReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
 		return
 	}
 
@@ -30,6 +34,14 @@ func (h *Handler) CreateFolderHandler(c *gin.Context) {
 
 	folder, err := h.vaultService.CreateFolder(c.Request.Context(), userID, req)
 	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrOrgNotFound) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not a member of the organization"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create folder"})
 		return
 	}
@@ -50,6 +62,10 @@ func (h *Handler) ListFoldersHandler(c *gin.Context) {
 
 	folders, err := h.vaultService.ListFolders(c.Request.Context(), userID)
 	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch folders"})
 		return
 	}
@@ -66,25 +82,36 @@ func (h *Handler) ListFoldersHandler(c *gin.Context) {
 // @Param        request body dto.UpdateFolderReq true "New Encrypted Metadata"
 // @Success      200  {string}  string "OK"
 // @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Folder not found"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /folders/{id} [put]
 func (h *Handler) UpdateFolderHandler(c *gin.Context) {
 	idStr := c.Param("id")
 	folderID, err := uuid.Parse(idStr)
 	if err != nil {
+		c.Error(err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
 		return
 	}
 
 	var req dto.UpdateFolderReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
 		return
 	}
 
 	userID := c.MustGet("user_id").(uuid.UUID)
 
 	if err := h.vaultService.UpdateFolder(c.Request.Context(), userID, folderID, req); err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found or access denied"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update folder"})
 		return
 	}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListGrantsHandler godoc
+// @Summary      List Grants
+// @Description  List every user holding a key to a resource. Restricted to the resource owner.
+// @Tags         Management
+// @Produce      json
+// @Param        type path      string true "Resource Type (folder/item)"
+// @Param        id   path      string true "Resource UUID"
+// @Success      200  {array}   dto.Grant
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Resource not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /resources/{type}/{id}/grants [get]
+func (h *Handler) ListGrantsHandler(c *gin.Context) {
+	resourceID, resourceType, ok := parseResourceParams(c)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	grants, err := h.vaultService.ListGrants(c.Request.Context(), userID, resourceID, resourceType)
+	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found or access denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch grants"})
+		return
+	}
+
+	c.JSON(http.StatusOK, grants)
+}
+
+// ListSharedByMeHandler godoc
+// @Summary      Shared By Me
+// @Description  List the grants given to other users across all resources the caller owns.
+// @Tags         Management
+// @Produce      json
+// @Success      200  {array}   dto.SharedGrant
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /shared-by-me [get]
+func (h *Handler) ListSharedByMeHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	grants, err := h.vaultService.ListSharedByMe(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shared resources"})
+		return
+	}
+
+	c.JSON(http.StatusOK, grants)
+}
+
+// ListSharedWithMeHandler godoc
+// @Summary      Shared With Me
+// @Description  List every folder and item the caller can access but does not own.
+// @Tags         Management
+// @Produce      json
+// @Success      200  {object}  dto.SharedWithMe
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /shared-with-me [get]
+func (h *Handler) ListSharedWithMeHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	shared, err := h.vaultService.ListSharedWithMe(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shared resources"})
+		return
+	}
+
+	c.JSON(http.StatusOK, shared)
+}
+
+// UpdateGrantHandler godoc
+// @Summary      Update Grant
+// @Description  Change the access level of an existing grant. The last OWNER of a resource cannot be demoted.
+// @Tags         Management
+// @Accept       json
+// @Param        type    path      string true "Resource Type (folder/item)"
+// @Param        id      path      string true "Resource UUID"
+// @Param        user_id path      string true "Grantee UUID"
+// @Param        request body      dto.UpdateGrantReq true "New access level"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Resource or grant not found"
+// @Failure      409  {object}  map[string]string "Cannot demote the last owner"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /resources/{type}/{id}/grants/{user_id} [patch]
+func (h *Handler) UpdateGrantHandler(c *gin.Context) {
+	resourceID, resourceType, ok := parseResourceParams(c)
+	if !ok {
+		return
+	}
+
+	targetUserID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req dto.UpdateGrantReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	err = h.vaultService.UpdateGrant(c.Request.Context(), userID, resourceID, resourceType, targetUserID, req)
+	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found or access denied"})
+		case errors.Is(err, service.ErrGrantNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Grant not found"})
+		case errors.Is(err, service.ErrLastOwner):
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot demote the last owner of a resource"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update grant"})
+		}
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// TransferOwnershipHandler godoc
+// @Summary      Transfer Ownership
+// @Description  Make another user the owner of a resource. The target must already hold a key to it.
+// @Tags         Management
+// @Accept       json
+// @Param        type    path      string true "Resource Type (folder/item)"
+// @Param        id      path      string true "Resource UUID"
+// @Param        request body      dto.TransferOwnershipReq true "Transfer details"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Resource not found"
+// @Failure      409  {object}  map[string]string "Target has no key to the resource"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /resources/{type}/{id}/transfer [post]
+func (h *Handler) TransferOwnershipHandler(c *gin.Context) {
+	resourceID, resourceType, ok := parseResourceParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.TransferOwnershipReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	err := h.vaultService.TransferOwnership(c.Request.Context(), userID, resourceID, resourceType, req)
+	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrSelfTransfer):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot transfer ownership to yourself"})
+		case errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found or access denied"})
+		case errors.Is(err, service.ErrTargetHasNoKey):
+			c.JSON(http.StatusConflict, gin.H{"error": "Target user must already have access to the resource"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer ownership"})
+		}
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// LeaveShareHandler godoc
+// @Summary      Leave Share
+// @Description  Remove the caller's own non-OWNER grant to a resource shared with them.
+// @Tags         Management
+// @Param        type path      string true "Resource Type (folder/item)"
+// @Param        id   path      string true "Resource UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Grant not found"
+// @Failure      409  {object}  map[string]string "Owners cannot leave"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /resources/{type}/{id}/grants/me [delete]
+func (h *Handler) LeaveShareHandler(c *gin.Context) {
+	resourceID, resourceType, ok := parseResourceParams(c)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	err := h.vaultService.LeaveShare(c.Request.Context(), userID, resourceID, resourceType)
+	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrGrantNotFound), errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Grant not found"})
+		case errors.Is(err, service.ErrOwnerCannotLeave):
+			c.JSON(http.StatusConflict, gin.H{"error": "Owners cannot leave their own resource"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave share"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListResourceEventsHandler godoc
+// @Summary      List Resource Events
+// @Description  List recent events on a resource, such as recipients leaving a share. Restricted to the resource owner.
+// @Tags         Management
+// @Produce      json
+// @Param        type path      string true "Resource Type (folder/item)"
+// @Param        id   path      string true "Resource UUID"
+// @Success      200  {array}   dto.Event
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Resource not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /resources/{type}/{id}/events [get]
+func (h *Handler) ListResourceEventsHandler(c *gin.Context) {
+	resourceID, resourceType, ok := parseResourceParams(c)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	events, err := h.vaultService.ListResourceEvents(c.Request.Context(), userID, resourceID, resourceType)
+	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found or access denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
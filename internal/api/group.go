@@ -0,0 +1,313 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// parseGroupParams reads the :id and :group_id path parameters. It writes a
+// 400 response and returns false on failure.
+func parseGroupParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	groupID, err := uuid.Parse(c.Param("group_id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return orgID, groupID, true
+}
+
+// CreateGroupHandler godoc
+// @Summary      Create Group
+// @Description  Creates a group inside an organization. The caller uploads the new group key wrapped for themselves and becomes its first member. Requires ADMIN or OWNER.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string true "Organization UUID"
+// @Param        request body dto.CreateGroupReq true "Group details"
+// @Success      201  {object}  dto.GroupResponse
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/groups [post]
+func (h *Handler) CreateGroupHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	var req dto.CreateGroupReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	group, err := h.orgService.CreateGroup(c.Request.Context(), userID, orgID, req)
+	if err != nil {
+		respondOrgError(c, err, "Failed to create group")
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// ListGroupsHandler godoc
+// @Summary      List Groups
+// @Tags         Groups
+// @Produce      json
+// @Param        id   path      string true "Organization UUID"
+// @Success      200  {array}   dto.GroupResponse
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/groups [get]
+func (h *Handler) ListGroupsHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	groups, err := h.orgService.ListGroups(c.Request.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(c, err, "Failed to fetch groups")
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// DeleteGroupHandler godoc
+// @Summary      Delete Group
+// @Description  Delete a group along with its memberships and folder grants. Requires ADMIN or OWNER.
+// @Tags         Groups
+// @Param        id       path      string true "Organization UUID"
+// @Param        group_id path      string true "Group UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Group not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/groups/{group_id} [delete]
+func (h *Handler) DeleteGroupHandler(c *gin.Context) {
+	orgID, groupID, ok := parseGroupParams(c)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.DeleteGroup(c.Request.Context(), userID, orgID, groupID); err != nil {
+		respondOrgError(c, err, "Failed to delete group")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListGroupMembersHandler godoc
+// @Summary      List Group Members
+// @Tags         Groups
+// @Produce      json
+// @Param        id       path      string true "Organization UUID"
+// @Param        group_id path      string true "Group UUID"
+// @Success      200  {array}   dto.GroupMember
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Group not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/groups/{group_id}/members [get]
+func (h *Handler) ListGroupMembersHandler(c *gin.Context) {
+	orgID, groupID, ok := parseGroupParams(c)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	members, err := h.orgService.ListGroupMembers(c.Request.Context(), userID, orgID, groupID)
+	if err != nil {
+		respondOrgError(c, err, "Failed to fetch group members")
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddGroupMemberHandler godoc
+// @Summary      Add Group Member
+// @Description  Add an organization member to a group by uploading the group key wrapped for them. Requires ADMIN or OWNER.
+// @Tags         Groups
+// @Accept       json
+// @Param        id       path      string true "Organization UUID"
+// @Param        group_id path      string true "Group UUID"
+// @Param        request  body      dto.AddGroupMemberReq true "Member and wrapped group key"
+// @Success      201  {string}  string "Created"
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Group or member not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/groups/{group_id}/members [post]
+func (h *Handler) AddGroupMemberHandler(c *gin.Context) {
+	orgID, groupID, ok := parseGroupParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddGroupMemberReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.AddGroupMember(c.Request.Context(), userID, orgID, groupID, req); err != nil {
+		respondOrgError(c, err, "Failed to add group member")
+		return
+	}
+
+	c.Status(http.StatusCreated)
+}
+
+// RemoveGroupMemberHandler godoc
+// @Summary      Remove Group Member
+// @Description  Remove a member from a group. Members can always remove themselves.
+// @Tags         Groups
+// @Param        id       path      string true "Organization UUID"
+// @Param        group_id path      string true "Group UUID"
+// @Param        user_id  path      string true "Member UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Group or member not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/groups/{group_id}/members/{user_id} [delete]
+func (h *Handler) RemoveGroupMemberHandler(c *gin.Context) {
+	orgID, groupID, ok := parseGroupParams(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.RemoveGroupMember(c.Request.Context(), userID, orgID, groupID, memberID); err != nil {
+		respondOrgError(c, err, "Failed to remove group member")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ShareWithGroupHandler godoc
+// @Summary      Share Folder With Group
+// @Description  Grant every member of a group access to a folder by adding the folder key wrapped with the group key. Items of the folder are shared by adding their keys wrapped the same way. The folder must belong to the group's organization.
+// @Tags         Management
+// @Accept       json
+// @Param        request body dto.GroupShareReq true "Group share details"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid request, folder outside the group's organization or item outside the folder"
+// @Failure      404  {object}  map[string]string "Folder or group not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /share/group [post]
+func (h *Handler) ShareWithGroupHandler(c *gin.Context) {
+	var req dto.GroupShareReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.vaultService.ShareFolderWithGroup(c.Request.Context(), userID, req); err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found or access denied"})
+		case errors.Is(err, service.ErrGroupNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		case errors.Is(err, service.ErrGroupOrgMismatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Folder does not belong to the group's organization"})
+		case errors.Is(err, service.ErrItemNotInFolder):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Item is not in the shared folder"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share folder with group"})
+		}
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RevokeGroupAccessHandler godoc
+// @Summary      Revoke Group Access
+// @Description  Remove a group's access to a folder.
+// @Tags         Management
+// @Accept       json
+// @Param        request body dto.GroupRevokeReq true "Revocation details"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Folder or grant not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /share/group/revoke [post]
+func (h *Handler) RevokeGroupAccessHandler(c *gin.Context) {
+	var req dto.GroupRevokeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.vaultService.RevokeGroupAccess(c.Request.Context(), userID, req); err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found or access denied"})
+		case errors.Is(err, service.ErrGrantNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group has no access to this folder"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke group access"})
+		}
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -1,23 +1,65 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
 	"github.com/axosec/core/crypto/token"
+	"github.com/axosec/vault/internal/migrate"
+	"github.com/axosec/vault/internal/ratelimit"
 	"github.com/axosec/vault/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// readyTimeout bounds the database checks of the readiness probe
+const readyTimeout = 2 * time.Second
+
+// TokenValidator verifies user JWTs, e.g. a *jwks.Verifier.
+type TokenValidator interface {
+	Validate(tokenString string) (*token.Claims, error)
+}
+
 type Handler struct {
-	jwt          *token.JWTManager
-	vaultService *service.VaultService
+	jwt               TokenValidator
+	vaultService      *service.VaultService
+	orgService        *service.OrgService
+	emergencyService  *service.EmergencyService
+	webhookService    *service.WebhookService
+	accountService    *service.ServiceAccountService
+	revocationService *service.RevocationService
+	limiter           *ratelimit.Limiter
+	allowedOrigins    []string
+	pool              *pgxpool.Pool
+	migrator          *migrate.Migrator
+	draining          atomic.Bool
 }
 
-func NewHandler(jwt *token.JWTManager, vaultService *service.VaultService) *Handler {
+func NewHandler(jwt TokenValidator, vaultService *service.VaultService, orgService *service.OrgService, emergencyService *service.EmergencyService, webhookService *service.WebhookService, accountService *service.ServiceAccountService, revocationService *service.RevocationService, limiter *ratelimit.Limiter, allowedOrigins []string, pool *pgxpool.Pool, migrator *migrate.Migrator) *Handler {
 	return &Handler{
-		jwt:          jwt,
-		vaultService: vaultService,
+		jwt:               jwt,
+		vaultService:      vaultService,
+		orgService:        orgService,
+		emergencyService:  emergencyService,
+		webhookService:    webhookService,
+		accountService:    accountService,
+		revocationService: revocationService,
+		limiter:           limiter,
+		allowedOrigins:    allowedOrigins,
+		pool:              pool,
+		migrator:          migrator,
 	}
 }
 
+// Drain makes the readiness probe fail so load balancers stop sending new
+// requests while the server shuts down.
+func (h *Handler) Drain() {
+	h.draining.Store(true)
+}
+
 // @Summary      Helthcheck
 // @Description  returns ok if api up
 // @Success      200
@@ -26,13 +68,68 @@ func (h *Handler) Helth(c *gin.Context) {
 	c.JSON(200, gin.H{"status": "ok"})
 }
 
+// @Summary      Liveness probe
+// @Description  returns ok while the process serves requests, without checking dependencies
+// @Success      200
+// @Router       /live [get]
+func (h *Handler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// @Summary      Readiness probe
+// @Description  returns ok when the database is reachable and its schema is not dirty or older than this build, 503 otherwise or while draining
+// @Success      200
+// @Failure      503  {object}  map[string]string "Not ready"
+// @Router       /ready [get]
+func (h *Handler) Ready(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	if err := h.pool.Ping(ctx); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "database unreachable"})
+		return
+	}
+
+	// A newer schema means a rolling deploy already migrated, old replicas
+	// keep serving until they are replaced. main refuses to start on it
+	if err := h.migrator.Check(ctx); err != nil && !errors.Is(err, migrate.ErrSchemaNewer) {
+		c.Error(err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "schema mismatch"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
 func (h *Handler) RegisterRouters(e *gin.Engine) {
+	e.Use(
+		h.RequestIDMiddleware(),
+		h.TracingMiddleware(),
+		h.LoggingMiddleware(),
+		h.RecoveryMiddleware(),
+		h.MetricsMiddleware(),
+	)
+
 	v1 := e.Group("/v1")
+	v1.Use(h.RequestMetaMiddleware())
 
 	v1.GET("/health", h.Helth)
+	v1.GET("/live", h.Live)
+	v1.GET("/ready", h.Ready)
 
-	protected := v1.Group("/")
-	protected.Use(h.AuthenticatedMiddleware())
+	// Probes are not throttled, everything else is limited per client IP
+	// before authentication and per user after it
+	limited := v1.Group("/")
+	limited.Use(h.IPRateLimitMiddleware())
+
+	protected := limited.Group("/")
+	protected.Use(h.AuthenticatedMiddleware(), h.RateLimitMiddleware())
 	{
 		protected.POST("/folders", h.CreateFolderHandler)
 		protected.GET("/folders", h.ListFoldersHandler)
@@ -44,7 +141,87 @@ func (h *Handler) RegisterRouters(e *gin.Engine) {
 		protected.PUT("/items/:id", h.UpdateItemHandler)
 
 		protected.DELETE("/resources/:type/:id", h.DeleteResourceHandler)
+		protected.GET("/resources/:type/:id/grants", h.ListGrantsHandler)
+		protected.PATCH("/resources/:type/:id/grants/:user_id", h.UpdateGrantHandler)
+		protected.DELETE("/resources/:type/:id/grants/me", h.LeaveShareHandler)
+		protected.GET("/resources/:type/:id/events", h.ListResourceEventsHandler)
+		protected.POST("/resources/:type/:id/transfer", h.TransferOwnershipHandler)
+		protected.GET("/shared-by-me", h.ListSharedByMeHandler)
+		protected.GET("/shared-with-me", h.ListSharedWithMeHandler)
 		protected.POST("/share", h.ShareResourceHandler)
 		protected.POST("/share/revoke", h.RevokeAccessHandler)
+		protected.POST("/share/group", h.ShareWithGroupHandler)
+		protected.POST("/share/group/revoke", h.RevokeGroupAccessHandler)
+
+		protected.GET("/audit", h.ListAuditEventsHandler)
+
+		protected.POST("/projects", h.CreateProjectHandler)
+		protected.GET("/projects", h.ListProjectsHandler)
+		protected.DELETE("/projects/:id", h.DeleteProjectHandler)
+		protected.POST("/projects/:id/environments", h.CreateEnvironmentHandler)
+		protected.GET("/projects/:id/environments", h.ListEnvironmentsHandler)
+		protected.DELETE("/projects/:id/environments/:env", h.DeleteEnvironmentHandler)
+		protected.GET("/projects/:id/environments/:env/secrets", h.GetEnvironmentSecretsHandler)
+	}
+
+	// Service accounts only reach the vault routes above
+	users := protected.Group("/")
+	users.Use(h.UserOnlyMiddleware())
+	{
+		users.POST("/orgs", h.CreateOrgHandler)
+		users.GET("/orgs", h.ListOrgsHandler)
+		users.GET("/orgs/:id", h.GetOrgHandler)
+		users.PUT("/orgs/:id", h.UpdateOrgHandler)
+		users.DELETE("/orgs/:id", h.DeleteOrgHandler)
+		users.GET("/orgs/:id/members", h.ListOrgMembersHandler)
+		users.POST("/orgs/:id/members", h.AddOrgMemberHandler)
+		users.PATCH("/orgs/:id/members/:user_id", h.UpdateOrgMemberHandler)
+		users.DELETE("/orgs/:id/members/:user_id", h.RemoveOrgMemberHandler)
+
+		users.POST("/orgs/:id/groups", h.CreateGroupHandler)
+		users.GET("/orgs/:id/groups", h.ListGroupsHandler)
+		users.DELETE("/orgs/:id/groups/:group_id", h.DeleteGroupHandler)
+		users.GET("/orgs/:id/groups/:group_id/members", h.ListGroupMembersHandler)
+		users.POST("/orgs/:id/groups/:group_id/members", h.AddGroupMemberHandler)
+		users.DELETE("/orgs/:id/groups/:group_id/members/:user_id", h.RemoveGroupMemberHandler)
+
+		users.GET("/orgs/:id/recovery", h.GetRecoveryPolicyHandler)
+		users.PUT("/orgs/:id/recovery", h.SetRecoveryPolicyHandler)
+		users.DELETE("/orgs/:id/recovery", h.DisableRecoveryPolicyHandler)
+		users.PUT("/orgs/:id/recovery/enrollment", h.RecoveryEnrollHandler)
+		users.DELETE("/orgs/:id/recovery/enrollment", h.RecoveryUnenrollHandler)
+		users.GET("/orgs/:id/recovery/enrollments", h.ListRecoveryEnrollmentsHandler)
+		users.GET("/orgs/:id/recovery/events", h.ListRecoveryEventsHandler)
+		users.POST("/orgs/:id/recovery/requests", h.StartRecoveryHandler)
+		users.GET("/orgs/:id/recovery/requests", h.ListRecoveryRequestsHandler)
+		users.GET("/orgs/:id/recovery/requests/:request_id", h.GetRecoveryRequestHandler)
+		users.POST("/orgs/:id/recovery/requests/:request_id/approve", h.ApproveRecoveryHandler)
+		users.GET("/orgs/:id/recovery/requests/:request_id/keyring", h.GetRecoveryKeyringHandler)
+		users.POST("/orgs/:id/recovery/requests/:request_id/complete", h.CompleteRecoveryHandler)
+		users.POST("/orgs/:id/recovery/requests/:request_id/cancel", h.CancelRecoveryHandler)
+
+		users.POST("/emergency/contacts", h.CreateEmergencyContactHandler)
+		users.GET("/emergency/contacts", h.ListEmergencyContactsHandler)
+		users.DELETE("/emergency/contacts/:id", h.DeleteEmergencyContactHandler)
+		users.PUT("/emergency/contacts/:id/keys", h.SetEmergencyKeysHandler)
+		users.POST("/emergency/contacts/:id/request", h.RequestEmergencyAccessHandler)
+		users.POST("/emergency/contacts/:id/approve", h.ApproveEmergencyAccessHandler)
+		users.POST("/emergency/contacts/:id/reject", h.RejectEmergencyAccessHandler)
+
+		users.POST("/webhooks", h.CreateWebhookHandler)
+		users.GET("/webhooks", h.ListWebhooksHandler)
+		users.DELETE("/webhooks/:id", h.DeleteWebhookHandler)
+		users.GET("/webhooks/:id/deliveries", h.ListWebhookDeliveriesHandler)
+		users.POST("/webhooks/:id/deliveries/:delivery_id/replay", h.ReplayWebhookDeliveryHandler)
+
+		users.POST("/service-accounts", h.CreateServiceAccountHandler)
+		users.GET("/service-accounts", h.ListServiceAccountsHandler)
+		users.DELETE("/service-accounts/:id", h.DeleteServiceAccountHandler)
+		users.POST("/service-accounts/:id/tokens", h.CreateAPITokenHandler)
+		users.GET("/service-accounts/:id/tokens", h.ListAPITokensHandler)
+		users.DELETE("/service-accounts/:id/tokens/:token_id", h.RevokeAPITokenHandler)
+
+		users.POST("/sessions/revoke", h.RevokeSessionHandler)
+		users.POST("/sessions/revoke-all", h.RevokeAllSessionsHandler)
 	}
 }
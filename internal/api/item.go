@@ -1,9 +1,11 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -17,11 +19,13 @@ import (
 // @Param        request body dto.CreateItemReq true "Item creation details"
 // @Success      201  {object}  dto.ItemResponse
 // @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Not a member of the organization"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /items [post]
 func (h *Handler) CreateItemHandler(c *gin.Context) {
 	var req dto.CreateItemReq
 	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
 		return
 	}
@@ -30,6 +34,14 @@ func (h *Handler) CreateItemHandler(c *gin.Context) {
 
 	item, err := h.vaultService.CreateItem(c.Request.Context(), userID, req)
 	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrOrgNotFound) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not a member of the organization"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create item"})
 		return
 	}
@@ -56,6 +68,7 @@ func (h *Handler) ListItemsHandler(c *gin.Context) {
 	if folderIDStr != "" {
 		parsed, err := uuid.Parse(folderIDStr)
 		if err != nil {
+			c.Error(err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder_id format"})
 			return
 		}
@@ -64,6 +77,10 @@ func (h *Handler) ListItemsHandler(c *gin.Context) {
 
 	items, err := h.vaultService.ListItems(c.Request.Context(), userID, folderID)
 	if err != nil {
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
 		return
 	}
@@ -86,6 +103,7 @@ func (h *Handler) GetItemHandler(c *gin.Context) {
 	idStr := c.Param("id")
 	itemID, err := uuid.Parse(idStr)
 	if err != nil {
+		c.Error(err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
 		return
 	}
@@ -94,7 +112,15 @@ func (h *Handler) GetItemHandler(c *gin.Context) {
 
 	item, err := h.vaultService.GetItem(c.Request.Context(), userID, itemID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found or access denied"})
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found or access denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item"})
 		return
 	}
 
@@ -110,18 +136,22 @@ func (h *Handler) GetItemHandler(c *gin.Context) {
 // @Param        request body dto.UpdateItemReq true "Update payload"
 // @Success      200  {string}  string "OK"
 // @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Read-only access"
+// @Failure      404  {object}  map[string]string "Item not found"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /items/{id} [put]
 func (h *Handler) UpdateItemHandler(c *gin.Context) {
 	idStr := c.Param("id")
 	itemID, err := uuid.Parse(idStr)
 	if err != nil {
+		c.Error(err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
 		return
 	}
 
 	var req dto.UpdateItemReq
 	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
 		return
 	}
@@ -129,7 +159,18 @@ func (h *Handler) UpdateItemHandler(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
 
 	if err := h.vaultService.UpdateItem(c.Request.Context(), userID, itemID, req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update item"})
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found or access denied"})
+		case errors.Is(err, service.ErrReadOnly):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Updating an item requires WRITE or OWNER access"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update item"})
+		}
 		return
 	}
 
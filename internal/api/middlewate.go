@@ -1,21 +1,83 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/logging"
+	"github.com/axosec/vault/internal/metrics"
+	"github.com/axosec/vault/internal/ratelimit"
+	"github.com/axosec/vault/internal/service"
+	"github.com/axosec/vault/internal/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		return "", false
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", true
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+// AuthenticatedMiddleware accepts a JWT from the Authorization header or
+// the auth_token cookie. The header takes precedence: when it is present
+// the cookie is ignored, even if the header is invalid. Cookie requests
+// that change state must also pass the Origin check. Revoked tokens are
+// rejected, see RevocationService.
 func (h *Handler) AuthenticatedMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		auth_token, err := c.Cookie("auth_token")
-		if err != nil {
+		auth_token, isBearer := bearerToken(c)
+		if isBearer && strings.HasPrefix(auth_token, dto.APITokenPrefix) {
+			h.authenticateAPIToken(c, auth_token)
+			return
+		}
+
+		if !isBearer {
+			var err error
+			auth_token, err = c.Cookie("auth_token")
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+				return
+			}
+
+			if !safeMethod(c.Request.Method) && !h.trustedOrigin(c) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Cross-site request rejected"})
+				return
+			}
+		}
+
+		if auth_token == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
+
 		claims, err := h.jwt.Validate(auth_token)
 		if err != nil {
+			c.Error(err)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
@@ -26,8 +88,330 @@ func (h *Handler) AuthenticatedMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		var issuedAt *time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = &claims.IssuedAt.Time
+		}
+
+		// Fail closed, a token is only accepted once it is known not revoked
+		revoked, err := h.revocationService.IsRevoked(c.Request.Context(), userID, claims.ID, issuedAt)
+		if err != nil {
+			c.Error(err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
 		c.Set("user_id", userID)
+		c.Set("token_claims", claims)
 
 		c.Next()
 	}
 }
+
+// authenticateAPIToken resolves a service account token and runs the rest
+// of the chain as the service account, limited to the token's scope.
+func (h *Handler) authenticateAPIToken(c *gin.Context, token string) {
+	accountID, scope, err := h.accountService.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		c.Error(err)
+		if errors.Is(err, service.ErrInvalidAPIToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+		return
+	}
+
+	c.Set("user_id", accountID)
+	c.Set("service_account", true)
+	c.Request = c.Request.WithContext(service.WithTokenScope(c.Request.Context(), scope))
+
+	c.Next()
+}
+
+// UserOnlyMiddleware rejects service accounts on routes meant for people.
+func (h *Handler) UserOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("service_account") {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not available to service accounts"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// safeMethod reports whether method must not change state.
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// trustedOrigin is the CSRF check for cookie requests. The Origin header,
+// or the Referer when a browser omits it, must be the API itself or one of
+// the allowed origins. Requests carrying neither are rejected.
+func (h *Handler) trustedOrigin(c *gin.Context) bool {
+	origin := c.GetHeader("Origin")
+	if origin == "" {
+		referer, err := url.Parse(c.GetHeader("Referer"))
+		if err != nil || referer.Host == "" {
+			return false
+		}
+		origin = referer.Scheme + "://" + referer.Host
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	if u.Host == c.Request.Host {
+		return true
+	}
+
+	return slices.Contains(h.allowedOrigins, u.Scheme+"://"+u.Host)
+}
+
+// RequestMetaMiddleware stores the client IP and user agent on the request
+// context so services can attach them to audit events.
+func (h *Handler) RequestMetaMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := service.WithRequestMeta(c.Request.Context(), service.RequestMeta{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// MetricsMiddleware records request counts and latency per route template.
+func (h *Handler) MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// TracingMiddleware continues the W3C trace context of the incoming request
+// and wraps the handler in a server span named after the route template.
+func (h *Handler) TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx, span := tracing.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("status %d", status))
+		}
+	}
+}
+
+// maxRequestIDLength bounds client supplied X-Request-ID values
+const maxRequestIDLength = 128
+
+// validRequestID reports whether a client supplied request ID is safe to
+// echo and log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// RequestIDMiddleware keeps a valid X-Request-ID from the client or
+// generates one, echoes it in the response and stores it on the context.
+func (h *Handler) RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader("X-Request-ID")
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Header("X-Request-ID", requestID)
+		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
+
+// LoggingMiddleware logs every request along with the errors handlers
+// attached with c.Error. Server errors are logged at error level, client
+// errors at warn.
+func (h *Handler) LoggingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+
+		if userID, ok := c.Get("user_id"); ok {
+			attrs = append(attrs, slog.Any("user_id", userID))
+		}
+
+		if len(c.Errors) > 0 {
+			errs := make([]error, len(c.Errors))
+			for i, e := range c.Errors {
+				errs[i] = e.Err
+			}
+			attrs = append(attrs, slog.String("error", errors.Join(errs...).Error()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// RecoveryMiddleware turns a panic into a 500 and logs it with its stack.
+func (h *Handler) RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				slog.ErrorContext(c.Request.Context(), "panic serving request",
+					slog.Any("panic", recovered),
+					slog.String("stack", string(debug.Stack())),
+				)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// shareRoutes are writes limited as ratelimit.ClassShare. Other writes use
+// ClassWrite and GET requests ClassRead.
+var shareRoutes = map[string]bool{
+	"/v1/share":              true,
+	"/v1/share/revoke":       true,
+	"/v1/share/group":        true,
+	"/v1/share/group/revoke": true,
+	"/v1/resources/:type/:id/grants/:user_id": true,
+	"/v1/resources/:type/:id/grants/me":       true,
+	"/v1/resources/:type/:id/transfer":        true,
+	"/v1/emergency/contacts/:id/keys":         true,
+	"/v1/orgs/:id/groups/:group_id/members":   true,
+}
+
+func routeClass(c *gin.Context) ratelimit.Class {
+	switch {
+	case c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead:
+		return ratelimit.ClassRead
+	case shareRoutes[c.FullPath()]:
+		return ratelimit.ClassShare
+	default:
+		return ratelimit.ClassWrite
+	}
+}
+
+// durationSeconds rounds d up to whole seconds for rate limit headers.
+func durationSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// IPRateLimitMiddleware throttles requests per client IP before they are
+// authenticated, so failed authentication attempts are counted as well.
+// A nil limiter disables it and store failures let the request through.
+func (h *Handler) IPRateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.takeToken(c, ratelimit.ClassIP, "ip:"+c.ClientIP()) {
+			c.Next()
+		}
+	}
+}
+
+// RateLimitMiddleware throttles requests per user and route class, so it
+// must run after AuthenticatedMiddleware. A nil limiter disables it and
+// store failures let the request through.
+func (h *Handler) RateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.MustGet("user_id").(uuid.UUID)
+
+		if h.takeToken(c, routeClass(c), "user:"+userID.String()) {
+			c.Next()
+		}
+	}
+}
+
+// takeToken takes a token from the bucket of subject and sets the rate limit
+// headers. It aborts with 429 and returns false when the bucket is empty.
+func (h *Handler) takeToken(c *gin.Context, class ratelimit.Class, subject string) bool {
+	if h.limiter == nil {
+		return true
+	}
+
+	result, err := h.limiter.Take(c.Request.Context(), class, subject)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "rate limit check failed", "error", err)
+		return true
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", durationSeconds(result.Reset))
+
+	if !result.Allowed {
+		c.Header("Retry-After", durationSeconds(result.RetryAfter))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+		return false
+	}
+
+	return true
+}
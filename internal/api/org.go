@@ -0,0 +1,347 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// respondOrgError maps organization service errors to HTTP responses.
+func respondOrgError(c *gin.Context, err error, fallback string) {
+	c.Error(err)
+
+	switch {
+	case errors.Is(err, service.ErrOrgNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+	case errors.Is(err, service.ErrMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+	case errors.Is(err, service.ErrGroupNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+	case errors.Is(err, service.ErrInsufficientRole):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient organization role"})
+	case errors.Is(err, service.ErrLastOrgOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": "Organization must keep at least one owner"})
+	case errors.Is(err, service.ErrOrgNotEmpty):
+		c.JSON(http.StatusConflict, gin.H{"error": "Organization still owns folders or items"})
+	case errors.Is(err, service.ErrMemberExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member"})
+	case errors.Is(err, service.ErrRecoveryNotEnabled):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account recovery is not enabled"})
+	case errors.Is(err, service.ErrNotEnrolled):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member is not enrolled in account recovery"})
+	case errors.Is(err, service.ErrRecoveryRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recovery request not found"})
+	case errors.Is(err, service.ErrSecondAdminRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Recovery must be approved by a different admin"})
+	case errors.Is(err, service.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": "Action not allowed in the current state"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// CreateOrgHandler godoc
+// @Summary      Create Organization
+// @Description  Creates a new organization with the caller as its owner.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreateOrgReq true "Organization details"
+// @Success      201  {object}  dto.OrgResponse
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs [post]
+func (h *Handler) CreateOrgHandler(c *gin.Context) {
+	var req dto.CreateOrgReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	org, err := h.orgService.CreateOrg(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organization"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, org)
+}
+
+// ListOrgsHandler godoc
+// @Summary      List Organizations
+// @Description  List the organizations the caller is a member of.
+// @Tags         Organizations
+// @Produce      json
+// @Success      200  {array}   dto.OrgResponse
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs [get]
+func (h *Handler) ListOrgsHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	orgs, err := h.orgService.ListOrgs(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organizations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, orgs)
+}
+
+// GetOrgHandler godoc
+// @Summary      Get Organization
+// @Tags         Organizations
+// @Produce      json
+// @Param        id   path      string true "Organization UUID"
+// @Success      200  {object}  dto.OrgResponse
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id} [get]
+func (h *Handler) GetOrgHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	org, err := h.orgService.GetOrg(c.Request.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(c, err, "Failed to fetch organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+// UpdateOrgHandler godoc
+// @Summary      Update Organization
+// @Description  Rename an organization. Requires ADMIN or OWNER.
+// @Tags         Organizations
+// @Accept       json
+// @Param        id   path      string true "Organization UUID"
+// @Param        request body dto.UpdateOrgReq true "New organization details"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id} [put]
+func (h *Handler) UpdateOrgHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	var req dto.UpdateOrgReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.UpdateOrg(c.Request.Context(), userID, orgID, req); err != nil {
+		respondOrgError(c, err, "Failed to update organization")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DeleteOrgHandler godoc
+// @Summary      Delete Organization
+// @Description  Delete an organization that no longer owns any folders or items. Requires OWNER.
+// @Tags         Organizations
+// @Param        id   path      string true "Organization UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      409  {object}  map[string]string "Organization not empty"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id} [delete]
+func (h *Handler) DeleteOrgHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.DeleteOrg(c.Request.Context(), userID, orgID); err != nil {
+		respondOrgError(c, err, "Failed to delete organization")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListOrgMembersHandler godoc
+// @Summary      List Organization Members
+// @Tags         Organizations
+// @Produce      json
+// @Param        id   path      string true "Organization UUID"
+// @Success      200  {array}   dto.OrgMember
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/members [get]
+func (h *Handler) ListOrgMembersHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	members, err := h.orgService.ListMembers(c.Request.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(c, err, "Failed to fetch members")
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddOrgMemberHandler godoc
+// @Summary      Add Organization Member
+// @Description  Add a user to an organization. Requires ADMIN, or OWNER to add another owner.
+// @Tags         Organizations
+// @Accept       json
+// @Param        id   path      string true "Organization UUID"
+// @Param        request body dto.AddOrgMemberReq true "Member details"
+// @Success      201  {string}  string "Created"
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      409  {object}  map[string]string "Already a member"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/members [post]
+func (h *Handler) AddOrgMemberHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	var req dto.AddOrgMemberReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.AddMember(c.Request.Context(), userID, orgID, req); err != nil {
+		respondOrgError(c, err, "Failed to add member")
+		return
+	}
+
+	c.Status(http.StatusCreated)
+}
+
+// UpdateOrgMemberHandler godoc
+// @Summary      Update Organization Member
+// @Description  Change a member's role. Only owners can grant or remove the OWNER role.
+// @Tags         Organizations
+// @Accept       json
+// @Param        id      path      string true "Organization UUID"
+// @Param        user_id path      string true "Member UUID"
+// @Param        request body dto.UpdateOrgMemberReq true "New role"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization or member not found"
+// @Failure      409  {object}  map[string]string "Cannot demote the last owner"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/members/{user_id} [patch]
+func (h *Handler) UpdateOrgMemberHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req dto.UpdateOrgMemberReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.UpdateMemberRole(c.Request.Context(), userID, orgID, memberID, req); err != nil {
+		respondOrgError(c, err, "Failed to update member")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RemoveOrgMemberHandler godoc
+// @Summary      Remove Organization Member
+// @Description  Remove a member from an organization. Members can always remove themselves.
+// @Tags         Organizations
+// @Param        id      path      string true "Organization UUID"
+// @Param        user_id path      string true "Member UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization or member not found"
+// @Failure      409  {object}  map[string]string "Cannot remove the last owner"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/members/{user_id} [delete]
+func (h *Handler) RemoveOrgMemberHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.RemoveMember(c.Request.Context(), userID, orgID, memberID); err != nil {
+		respondOrgError(c, err, "Failed to remove member")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// respondProjectError maps project and environment errors to HTTP responses.
+func respondProjectError(c *gin.Context, err error, fallback string) {
+	c.Error(err)
+
+	if abortOutOfScope(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case errors.Is(err, service.ErrProjectNotEmpty):
+		c.JSON(http.StatusConflict, gin.H{"error": "Project still has environments"})
+	case errors.Is(err, service.ErrEnvironmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Environment not found"})
+	case errors.Is(err, service.ErrEnvironmentExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Environment already exists"})
+	case errors.Is(err, service.ErrInvalidEnvironmentName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Environment names may only contain letters, digits, '-' and '_'"})
+	case errors.Is(err, service.ErrOrgNotFound):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not a member of the organization"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// CreateProjectHandler godoc
+// @Summary      Create Project
+// @Description  Create a secrets project. Environments are added to it, each holding key/value secrets.
+// @Tags         Projects
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreateProjectReq true "Project details"
+// @Success      201  {object}  dto.Project
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Not a member of the organization"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /projects [post]
+func (h *Handler) CreateProjectHandler(c *gin.Context) {
+	var req dto.CreateProjectReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	project, err := h.vaultService.CreateProject(c.Request.Context(), userID, req)
+	if err != nil {
+		respondProjectError(c, err, "Failed to create project")
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+// ListProjectsHandler godoc
+// @Summary      List Projects
+// @Description  List projects the caller owns, belongs to through an organization, or holds an environment key of.
+// @Tags         Projects
+// @Produce      json
+// @Success      200  {array}   dto.Project
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /projects [get]
+func (h *Handler) ListProjectsHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	projects, err := h.vaultService.ListProjects(c.Request.Context(), userID)
+	if err != nil {
+		respondProjectError(c, err, "Failed to fetch projects")
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+// DeleteProjectHandler godoc
+// @Summary      Delete Project
+// @Description  Delete a project. Its environments must be deleted first.
+// @Tags         Projects
+// @Param        id   path      string true "Project UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Project not found"
+// @Failure      409  {object}  map[string]string "Project still has environments"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /projects/{id} [delete]
+func (h *Handler) DeleteProjectHandler(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.vaultService.DeleteProject(c.Request.Context(), userID, projectID); err != nil {
+		respondProjectError(c, err, "Failed to delete project")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateEnvironmentHandler godoc
+// @Summary      Create Environment
+// @Description  Add an environment such as dev, staging or prod to a project. The environment is backed by a new folder; secrets are created in it as items of type SECRET and it is shared like any folder.
+// @Tags         Projects
+// @Accept       json
+// @Produce      json
+// @Param        id      path  string                    true "Project UUID"
+// @Param        request body  dto.CreateEnvironmentReq  true "Environment details"
+// @Success      201  {object}  dto.Environment
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Project not found"
+// @Failure      409  {object}  map[string]string "Environment already exists"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /projects/{id}/environments [post]
+func (h *Handler) CreateEnvironmentHandler(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var req dto.CreateEnvironmentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	env, err := h.vaultService.CreateEnvironment(c.Request.Context(), userID, projectID, req)
+	if err != nil {
+		respondProjectError(c, err, "Failed to create environment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, env)
+}
+
+// ListEnvironmentsHandler godoc
+// @Summary      List Environments
+// @Description  List the environments of a project with the caller's access level to each.
+// @Tags         Projects
+// @Produce      json
+// @Param        id   path      string true "Project UUID"
+// @Success      200  {array}   dto.Environment
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Project not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /projects/{id}/environments [get]
+func (h *Handler) ListEnvironmentsHandler(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	envs, err := h.vaultService.ListEnvironments(c.Request.Context(), userID, projectID)
+	if err != nil {
+		respondProjectError(c, err, "Failed to fetch environments")
+		return
+	}
+
+	c.JSON(http.StatusOK, envs)
+}
+
+// DeleteEnvironmentHandler godoc
+// @Summary      Delete Environment
+// @Description  Delete an environment and the folder holding its secrets.
+// @Tags         Projects
+// @Param        id       path  string true "Project UUID"
+// @Param        env      path  string true "Environment UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Project or environment not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /projects/{id}/environments/{env} [delete]
+func (h *Handler) DeleteEnvironmentHandler(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	envID, err := uuid.Parse(c.Param("env"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid environment ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.vaultService.DeleteEnvironment(c.Request.Context(), userID, projectID, envID); err != nil {
+		respondProjectError(c, err, "Failed to delete environment")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetEnvironmentSecretsHandler godoc
+// @Summary      Get Environment Secrets
+// @Description  Fetch every secret of an environment the caller holds a key to in one call, e.g. for a service account loading its configuration. Each secret is audited as a read.
+// @Tags         Projects
+// @Produce      json
+// @Param        id    path  string true "Project UUID"
+// @Param        env   path  string true "Environment name, e.g. prod"
+// @Success      200  {object}  dto.EnvironmentSecrets
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Token scope does not allow this operation"
+// @Failure      404  {object}  map[string]string "Project or environment not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /projects/{id}/environments/{env}/secrets [get]
+func (h *Handler) GetEnvironmentSecretsHandler(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	secrets, err := h.vaultService.GetEnvironmentSecrets(c.Request.Context(), userID, projectID, c.Param("env"))
+	if err != nil {
+		respondProjectError(c, err, "Failed to fetch secrets")
+		return
+	}
+
+	c.JSON(http.StatusOK, secrets)
+}
@@ -0,0 +1,480 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/axosec/vault/internal/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// parseRecoveryParams reads the :id and :request_id path parameters. It writes
+// a 400 response and returns false on failure.
+func parseRecoveryParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	requestID, err := uuid.Parse(c.Param("request_id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return orgID, requestID, true
+}
+
+// GetRecoveryPolicyHandler godoc
+// @Summary      Get Recovery Policy
+// @Description  Returns the organization's recovery public key and whether the caller is enrolled.
+// @Tags         Account Recovery
+// @Produce      json
+// @Param        id   path      string true "Organization UUID"
+// @Success      200  {object}  dto.RecoveryPolicy
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Organization not found or recovery not enabled"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery [get]
+func (h *Handler) GetRecoveryPolicyHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	policy, err := h.orgService.GetRecoveryPolicy(c.Request.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(c, err, "Failed to fetch recovery policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// SetRecoveryPolicyHandler godoc
+// @Summary      Set Recovery Policy
+// @Description  Enable account recovery or rotate the recovery public key. Rotating drops existing enrollments and cancels open requests. Requires OWNER.
+// @Tags         Account Recovery
+// @Accept       json
+// @Param        id   path      string true "Organization UUID"
+// @Param        request body dto.SetRecoveryPolicyReq true "Recovery public key"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery [put]
+func (h *Handler) SetRecoveryPolicyHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	var req dto.SetRecoveryPolicyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.SetRecoveryPolicy(c.Request.Context(), userID, orgID, req); err != nil {
+		respondOrgError(c, err, "Failed to set recovery policy")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DisableRecoveryPolicyHandler godoc
+// @Summary      Disable Recovery Policy
+// @Description  Turn account recovery off and delete all enrollments. Requires OWNER.
+// @Tags         Account Recovery
+// @Param        id   path      string true "Organization UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization not found or recovery not enabled"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery [delete]
+func (h *Handler) DisableRecoveryPolicyHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.DisableRecoveryPolicy(c.Request.Context(), userID, orgID); err != nil {
+		respondOrgError(c, err, "Failed to disable recovery policy")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RecoveryEnrollHandler godoc
+// @Summary      Enroll in Recovery
+// @Description  Upload the caller's keyring wrapped with the organization recovery public key.
+// @Tags         Account Recovery
+// @Accept       json
+// @Param        id   path      string true "Organization UUID"
+// @Param        request body dto.RecoveryEnrollReq true "Wrapped keyring"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Organization not found or recovery not enabled"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery/enrollment [put]
+func (h *Handler) RecoveryEnrollHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	var req dto.RecoveryEnrollReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.Enroll(c.Request.Context(), userID, orgID, req); err != nil {
+		respondOrgError(c, err, "Failed to enroll in account recovery")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RecoveryUnenrollHandler godoc
+// @Summary      Leave Recovery
+// @Description  Delete the caller's escrowed keyring.
+// @Tags         Account Recovery
+// @Param        id   path      string true "Organization UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Not enrolled"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery/enrollment [delete]
+func (h *Handler) RecoveryUnenrollHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.Unenroll(c.Request.Context(), userID, orgID); err != nil {
+		respondOrgError(c, err, "Failed to leave account recovery")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListRecoveryEnrollmentsHandler godoc
+// @Summary      List Recovery Enrollments
+// @Description  List the members who escrowed their keyring. Requires ADMIN or OWNER.
+// @Tags         Account Recovery
+// @Produce      json
+// @Param        id   path      string true "Organization UUID"
+// @Success      200  {array}   dto.RecoveryEnrollment
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery/enrollments [get]
+func (h *Handler) ListRecoveryEnrollmentsHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	enrollments, err := h.orgService.ListRecoveryEnrollments(c.Request.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(c, err, "Failed to fetch recovery enrollments")
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollments)
+}
+
+// StartRecoveryHandler godoc
+// @Summary      Start Recovery
+// @Description  Open a recovery request for an enrolled member's re-enrolled device. A second admin must approve it. Requires ADMIN or OWNER.
+// @Tags         Account Recovery
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string true "Organization UUID"
+// @Param        request body dto.CreateRecoveryReq true "Member and device key"
+// @Success      201  {object}  dto.RecoveryRequest
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization not found or member not enrolled"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery/requests [post]
+func (h *Handler) StartRecoveryHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	var req dto.CreateRecoveryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	request, err := h.orgService.StartRecovery(c.Request.Context(), userID, orgID, req)
+	if err != nil {
+		respondOrgError(c, err, "Failed to start recovery")
+		return
+	}
+
+	c.JSON(http.StatusCreated, request)
+}
+
+// ListRecoveryRequestsHandler godoc
+// @Summary      List Recovery Requests
+// @Description  List the organization's recovery requests, newest first. Requires ADMIN or OWNER.
+// @Tags         Account Recovery
+// @Produce      json
+// @Param        id   path      string true "Organization UUID"
+// @Success      200  {array}   dto.RecoveryRequest
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery/requests [get]
+func (h *Handler) ListRecoveryRequestsHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	requests, err := h.orgService.ListRecoveryRequests(c.Request.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(c, err, "Failed to fetch recovery requests")
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetRecoveryRequestHandler godoc
+// @Summary      Get Recovery Request
+// @Description  Returns a recovery request to an org admin or the member being recovered. Once completed the member also receives the keyring re-wrapped for their device.
+// @Tags         Account Recovery
+// @Produce      json
+// @Param        id          path  string true "Organization UUID"
+// @Param        request_id  path  string true "Request UUID"
+// @Success      200  {object}  dto.RecoveryRequest
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Request not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery/requests/{request_id} [get]
+func (h *Handler) GetRecoveryRequestHandler(c *gin.Context) {
+	orgID, requestID, ok := parseRecoveryParams(c)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	request, err := h.orgService.GetRecoveryRequest(c.Request.Context(), userID, orgID, requestID)
+	if err != nil {
+		respondOrgError(c, err, "Failed to fetch recovery request")
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// ApproveRecoveryHandler godoc
+// @Summary      Approve Recovery
+// @Description  Confirm a pending recovery request. The caller must be an ADMIN or OWNER other than the one who started it.
+// @Tags         Account Recovery
+// @Param        id          path  string true "Organization UUID"
+// @Param        request_id  path  string true "Request UUID"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Insufficient role or same admin"
+// @Failure      404  {object}  map[string]string "Request not found"
+// @Failure      409  {object}  map[string]string "Invalid state"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery/requests/{request_id}/approve [post]
+func (h *Handler) ApproveRecoveryHandler(c *gin.Context) {
+	orgID, requestID, ok := parseRecoveryParams(c)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.ApproveRecovery(c.Request.Context(), userID, orgID, requestID); err != nil {
+		respondOrgError(c, err, "Failed to approve recovery")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// GetRecoveryKeyringHandler godoc
+// @Summary      Get Escrowed Keyring
+// @Description  Returns the member's keyring wrapped with the recovery key and the device public key to re-wrap it for. Only for approved requests. Requires ADMIN or OWNER.
+// @Tags         Account Recovery
+// @Produce      json
+// @Param        id          path  string true "Organization UUID"
+// @Param        request_id  path  string true "Request UUID"
+// @Success      200  {object}  dto.RecoveryKeyring
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Request not found"
+// @Failure      409  {object}  map[string]string "Request not approved"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery/requests/{request_id}/keyring [get]
+func (h *Handler) GetRecoveryKeyringHandler(c *gin.Context) {
+	orgID, requestID, ok := parseRecoveryParams(c)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	keyring, err := h.orgService.GetRecoveryKeyring(c.Request.Context(), userID, orgID, requestID)
+	if err != nil {
+		respondOrgError(c, err, "Failed to fetch escrowed keyring")
+		return
+	}
+
+	c.JSON(http.StatusOK, keyring)
+}
+
+// CompleteRecoveryHandler godoc
+// @Summary      Complete Recovery
+// @Description  Hand the keyring re-wrapped for the member's device back to the server. Requires ADMIN or OWNER.
+// @Tags         Account Recovery
+// @Accept       json
+// @Param        id          path  string true "Organization UUID"
+// @Param        request_id  path  string true "Request UUID"
+// @Param        request body dto.CompleteRecoveryReq true "Re-wrapped keyring"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Request not found"
+// @Failure      409  {object}  map[string]string "Invalid state"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery/requests/{request_id}/complete [post]
+func (h *Handler) CompleteRecoveryHandler(c *gin.Context) {
+	orgID, requestID, ok := parseRecoveryParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CompleteRecoveryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.CompleteRecovery(c.Request.Context(), userID, orgID, requestID, req); err != nil {
+		respondOrgError(c, err, "Failed to complete recovery")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// CancelRecoveryHandler godoc
+// @Summary      Cancel Recovery
+// @Description  Cancel an open recovery request. Requires ADMIN or OWNER.
+// @Tags         Account Recovery
+// @Param        id          path  string true "Organization UUID"
+// @Param        request_id  path  string true "Request UUID"
+// @Success      200  {string}  string "OK"
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Request not found"
+// @Failure      409  {object}  map[string]string "Invalid state"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery/requests/{request_id}/cancel [post]
+func (h *Handler) CancelRecoveryHandler(c *gin.Context) {
+	orgID, requestID, ok := parseRecoveryParams(c)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.orgService.CancelRecovery(c.Request.Context(), userID, orgID, requestID); err != nil {
+		respondOrgError(c, err, "Failed to cancel recovery")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ListRecoveryEventsHandler godoc
+// @Summary      List Recovery Events
+// @Description  List the recorded recovery steps for the organization, newest first. Requires ADMIN or OWNER.
+// @Tags         Account Recovery
+// @Produce      json
+// @Param        id   path      string true "Organization UUID"
+// @Success      200  {array}   dto.Event
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      403  {object}  map[string]string "Insufficient role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /orgs/{id}/recovery/events [get]
+func (h *Handler) ListRecoveryEventsHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	events, err := h.orgService.ListRecoveryEvents(c.Request.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(c, err, "Failed to fetch recovery events")
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
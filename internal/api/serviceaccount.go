@@ -0,0 +1,238 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// abortOutOfScope answers 403 when an API token's scope rejected the
+// operation and reports whether it did.
+func abortOutOfScope(c *gin.Context, err error) bool {
+	if !errors.Is(err, service.ErrOutOfScope) {
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": "Token scope does not allow this operation"})
+	return true
+}
+
+// respondServiceAccountError maps service account errors to HTTP responses.
+func respondServiceAccountError(c *gin.Context, err error, fallback string) {
+	c.Error(err)
+
+	switch {
+	case errors.Is(err, service.ErrServiceAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+	case errors.Is(err, service.ErrAPITokenNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found or already revoked"})
+	case errors.Is(err, service.ErrTokenExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token expiry must be in the future and within the allowed lifetime"})
+	case errors.Is(err, service.ErrOrgNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+	case errors.Is(err, service.ErrInsufficientRole):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient organization role"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// CreateServiceAccountHandler godoc
+// @Summary      Create Service Account
+// @Description  Create a service account owned by the caller or an organization. Share resources with it like with a user, using its id and public key.
+// @Tags         Service Accounts
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreateServiceAccountReq true "Service account details"
+// @Success      201  {object}  dto.ServiceAccount
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      403  {object}  map[string]string "Insufficient organization role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /service-accounts [post]
+func (h *Handler) CreateServiceAccountHandler(c *gin.Context) {
+	var req dto.CreateServiceAccountReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	account, err := h.accountService.CreateServiceAccount(c.Request.Context(), userID, req)
+	if err != nil {
+		respondServiceAccountError(c, err, "Failed to create service account")
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// ListServiceAccountsHandler godoc
+// @Summary      List Service Accounts
+// @Description  List the caller's service accounts, or an organization's when org_id is given.
+// @Tags         Service Accounts
+// @Produce      json
+// @Param        org_id  query  string false "Organization UUID"
+// @Success      200  {array}   dto.ServiceAccount
+// @Failure      400  {object}  map[string]string "Invalid query"
+// @Failure      403  {object}  map[string]string "Insufficient organization role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /service-accounts [get]
+func (h *Handler) ListServiceAccountsHandler(c *gin.Context) {
+	var query dto.ServiceAccountQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	accounts, err := h.accountService.ListServiceAccounts(c.Request.Context(), userID, query)
+	if err != nil {
+		respondServiceAccountError(c, err, "Failed to fetch service accounts")
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// DeleteServiceAccountHandler godoc
+// @Summary      Delete Service Account
+// @Description  Delete a service account and all of its tokens. Keys shared with it stay until revoked.
+// @Tags         Service Accounts
+// @Param        id   path      string true "Service account UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Service account not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /service-accounts/{id} [delete]
+func (h *Handler) DeleteServiceAccountHandler(c *gin.Context) {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service account ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.accountService.DeleteServiceAccount(c.Request.Context(), userID, accountID); err != nil {
+		respondServiceAccountError(c, err, "Failed to delete service account")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateAPITokenHandler godoc
+// @Summary      Issue API Token
+// @Description  Issue a token for the service account, used as "Authorization: Bearer <token>". Tokens are read-only unless read_only is false, and limited to folder_ids and item_ids when given. The token is only returned here.
+// @Tags         Service Accounts
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string true "Service account UUID"
+// @Param        request body dto.CreateAPITokenReq true "Token name, scope and expiry"
+// @Success      201  {object}  dto.APIToken
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Service account not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /service-accounts/{id}/tokens [post]
+func (h *Handler) CreateAPITokenHandler(c *gin.Context) {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service account ID"})
+		return
+	}
+
+	var req dto.CreateAPITokenReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	token, err := h.accountService.CreateToken(c.Request.Context(), userID, accountID, req)
+	if err != nil {
+		respondServiceAccountError(c, err, "Failed to issue token")
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+// ListAPITokensHandler godoc
+// @Summary      List API Tokens
+// @Description  List the tokens of a service account, including expired and revoked ones.
+// @Tags         Service Accounts
+// @Produce      json
+// @Param        id   path      string true "Service account UUID"
+// @Success      200  {array}   dto.APIToken
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Service account not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /service-accounts/{id}/tokens [get]
+func (h *Handler) ListAPITokensHandler(c *gin.Context) {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service account ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	tokens, err := h.accountService.ListTokens(c.Request.Context(), userID, accountID)
+	if err != nil {
+		respondServiceAccountError(c, err, "Failed to fetch tokens")
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RevokeAPITokenHandler godoc
+// @Summary      Revoke API Token
+// @Description  Revoke a token immediately.
+// @Tags         Service Accounts
+// @Param        id        path  string true "Service account UUID"
+// @Param        token_id  path  string true "Token UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Service account or token not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /service-accounts/{id}/tokens/{token_id} [delete]
+func (h *Handler) RevokeAPITokenHandler(c *gin.Context) {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service account ID"})
+		return
+	}
+
+	tokenID, err := uuid.Parse(c.Param("token_id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.accountService.RevokeToken(c.Request.Context(), userID, accountID, tokenID); err != nil {
+		respondServiceAccountError(c, err, "Failed to revoke token")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/axosec/core/crypto/token"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RevokeSessionHandler godoc
+// @Summary      Revoke Current Session
+// @Description  Revoke the token this request is made with. It is rejected by every replica from then on, at the latest once their revocation cache expires.
+// @Tags         Sessions
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Token has no jti claim"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /sessions/revoke [post]
+func (h *Handler) RevokeSessionHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	claims := c.MustGet("token_claims").(*token.Claims)
+
+	// Validate requires exp, so ExpiresAt is set
+	err := h.revocationService.RevokeToken(c.Request.Context(), userID, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		c.Error(err)
+		if errors.Is(err, service.ErrTokenWithoutID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Token has no jti claim, revoke all sessions instead"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeAllSessionsHandler godoc
+// @Summary      Revoke All Sessions
+// @Description  Reject every token of the caller issued until now, including the one this request is made with.
+// @Tags         Sessions
+// @Produce      json
+// @Success      200  {object}  dto.SessionsRevoked
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /sessions/revoke-all [post]
+func (h *Handler) RevokeAllSessionsHandler(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	revokedBefore, err := h.revocationService.RevokeAllSessions(c.Request.Context(), userID, userID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SessionsRevoked{RevokedBefore: revokedBefore})
+}
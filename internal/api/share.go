@@ -1,9 +1,11 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -16,32 +18,30 @@ import (
 // @Param        id   path      string true "Resource UUID"
 // @Success      204  {object}  nil
 // @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Resource not found"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /resources/{type}/{id} [delete]
 func (h *Handler) DeleteResourceHandler(c *gin.Context) {
-	idStr := c.Param("id")
-	resourceID, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
-		return
-	}
-
-	typeStr := c.Param("type")
-	var resourceType dto.ResourceType
-	switch typeStr {
-	case "folder":
-		resourceType = dto.TypeFolder
-	case "item":
-		resourceType = dto.TypeItem
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource type. Must be 'folder' or 'item'"})
+	resourceID, resourceType, ok := parseResourceParams(c)
+	if !ok {
 		return
 	}
 
 	userID := c.MustGet("user_id").(uuid.UUID)
 
 	if err := h.vaultService.DeleteResource(c.Request.Context(), userID, resourceID, resourceType); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete resource"})
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found or access denied"})
+		case errors.Is(err, service.ErrInvalidResourceType):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource type"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete resource"})
+		}
 		return
 	}
 
@@ -56,11 +56,14 @@ func (h *Handler) DeleteResourceHandler(c *gin.Context) {
 // @Param        request body dto.ShareParams true "Share details"
 // @Success      200  {string}  string "OK"
 // @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Resource not found"
+// @Failure      409  {object}  map[string]string "Cannot demote the last owner"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /share [post]
 func (h *Handler) ShareResourceHandler(c *gin.Context) {
 	var req dto.ShareParams
 	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share parameters"})
 		return
 	}
@@ -68,7 +71,18 @@ func (h *Handler) ShareResourceHandler(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
 
 	if err := h.vaultService.ShareResource(c.Request.Context(), userID, req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share resource"})
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found or access denied"})
+		case errors.Is(err, service.ErrLastOwner):
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot demote the last owner of a resource"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share resource"})
+		}
 		return
 	}
 
@@ -83,6 +97,8 @@ func (h *Handler) ShareResourceHandler(c *gin.Context) {
 // @Param        request body dto.RevokeReq true "Revocation details"
 // @Success      200  {string}  string "OK"
 // @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Resource not found"
+// @Failure      409  {object}  map[string]string "Cannot revoke the last owner"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /share/revoke [post]
 func (h *Handler) RevokeAccessHandler(c *gin.Context) {
@@ -93,6 +109,7 @@ func (h *Handler) RevokeAccessHandler(c *gin.Context) {
 
 	var req RevokeReq
 	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parameters"})
 		return
 	}
@@ -100,9 +117,41 @@ func (h *Handler) RevokeAccessHandler(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
 
 	if err := h.vaultService.RevokeAccess(c.Request.Context(), userID, req.TargetUserID, req.ResourceID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke access"})
+		c.Error(err)
+		if abortOutOfScope(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrAccessDenied):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found or access denied"})
+		case errors.Is(err, service.ErrLastOwner):
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot revoke the last owner of a resource"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke access"})
+		}
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
+
+// parseResourceParams reads the :type and :id path parameters shared by the
+// /resources routes. It writes a 400 response and returns false on failure.
+func parseResourceParams(c *gin.Context) (uuid.UUID, dto.ResourceType, bool) {
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+		return uuid.Nil, "", false
+	}
+
+	switch c.Param("type") {
+	case "folder":
+		return resourceID, dto.TypeFolder, true
+	case "item":
+		return resourceID, dto.TypeItem, true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource type. Must be 'folder' or 'item'"})
+		return uuid.Nil, "", false
+	}
+}
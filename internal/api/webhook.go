@@ -0,0 +1,196 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// respondWebhookError maps webhook service errors to HTTP responses.
+func respondWebhookError(c *gin.Context, err error, fallback string) {
+	c.Error(err)
+
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+	case errors.Is(err, service.ErrInvalidWebhookURL):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Webhook URL must be http or https and must not point to an internal address"})
+	case errors.Is(err, service.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+	case errors.Is(err, service.ErrOrgNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+	case errors.Is(err, service.ErrInsufficientRole):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient organization role"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// CreateWebhookHandler godoc
+// @Summary      Create Webhook
+// @Description  Subscribe a URL to audit events. Deliveries are signed with HMAC-SHA256 over "timestamp.body" in the X-Vault-Signature header; the secret is only returned here.
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreateWebhookReq true "Webhook details"
+// @Success      201  {object}  dto.WebhookResponse
+// @Failure      400  {object}  map[string]string "Invalid request or internal webhook URL"
+// @Failure      403  {object}  map[string]string "Insufficient organization role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /webhooks [post]
+func (h *Handler) CreateWebhookHandler(c *gin.Context) {
+	var req dto.CreateWebhookReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	webhook, err := h.webhookService.CreateWebhook(c.Request.Context(), userID, req)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// ListWebhooksHandler godoc
+// @Summary      List Webhooks
+// @Description  List the caller's webhooks, or an organization's when org_id is given.
+// @Tags         Webhooks
+// @Produce      json
+// @Param        org_id  query  string false "Organization UUID"
+// @Success      200  {array}   dto.WebhookResponse
+// @Failure      400  {object}  map[string]string "Invalid query"
+// @Failure      403  {object}  map[string]string "Insufficient organization role"
+// @Failure      404  {object}  map[string]string "Organization not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /webhooks [get]
+func (h *Handler) ListWebhooksHandler(c *gin.Context) {
+	var query dto.WebhookQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	webhooks, err := h.webhookService.ListWebhooks(c.Request.Context(), userID, query)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to fetch webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhookHandler godoc
+// @Summary      Delete Webhook
+// @Description  Delete a webhook and its delivery history.
+// @Tags         Webhooks
+// @Param        id   path      string true "Webhook UUID"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Webhook not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /webhooks/{id} [delete]
+func (h *Handler) DeleteWebhookHandler(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.webhookService.DeleteWebhook(c.Request.Context(), userID, webhookID); err != nil {
+		respondWebhookError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveriesHandler godoc
+// @Summary      List Webhook Deliveries
+// @Description  List deliveries of a webhook, newest first, including the last error of failed attempts.
+// @Tags         Webhooks
+// @Produce      json
+// @Param        id      path   string true  "Webhook UUID"
+// @Param        status  query  string false "PENDING, DELIVERED or DEAD"
+// @Success      200  {array}   dto.WebhookDelivery
+// @Failure      400  {object}  map[string]string "Invalid request"
+// @Failure      404  {object}  map[string]string "Webhook not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /webhooks/{id}/deliveries [get]
+func (h *Handler) ListWebhookDeliveriesHandler(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	var query dto.WebhookDeliveryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request.Context(), userID, webhookID, query)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to fetch webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// ReplayWebhookDeliveryHandler godoc
+// @Summary      Replay Webhook Delivery
+// @Description  Queue a delivery again with a fresh set of attempts, e.g. after it went DEAD. Pending deliveries cannot be replayed.
+// @Tags         Webhooks
+// @Param        id           path  string true "Webhook UUID"
+// @Param        delivery_id  path  string true "Delivery UUID"
+// @Success      202  {object}  nil
+// @Failure      400  {object}  map[string]string "Invalid UUID"
+// @Failure      404  {object}  map[string]string "Webhook or delivery not found, or delivery still pending"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /webhooks/{id}/deliveries/{delivery_id}/replay [post]
+func (h *Handler) ReplayWebhookDeliveryHandler(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.webhookService.ReplayDelivery(c.Request.Context(), userID, webhookID, deliveryID); err != nil {
+		respondWebhookError(c, err, "Failed to replay webhook delivery")
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
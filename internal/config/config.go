@@ -23,14 +23,126 @@ type JWTConfig struct {
 	PublicKeyPath  string `mapstructure:"JWT_PUBLIC_KEY_PATH" validate:"required"`
 	Issuer         string `mapstructure:"JWT_ISSUER" validate:"required"`
 	ExpirationTime int    `mapstructure:"JWT_EXPIRATION_HOURS" validate:"required,min=1"`
+	// Audience is required in the aud claim of user tokens when set
+	Audience      string `mapstructure:"JWT_AUDIENCE"`
+	LeewaySeconds int    `mapstructure:"JWT_LEEWAY_SECONDS" validate:"min=0"`
+	// JWKSURL is an http(s) URL or file path of the auth service's signing
+	// keys. Without it tokens are verified with the public key file
+	JWKSURL               string `mapstructure:"JWKS_URL"`
+	JWKSRefreshSeconds    int    `mapstructure:"JWKS_REFRESH_INTERVAL_SECONDS" validate:"required,min=1"`
+	JWKSMinRefreshSeconds int    `mapstructure:"JWKS_MIN_REFRESH_INTERVAL_SECONDS" validate:"required,min=1"`
+	// StaticAlgorithms are accepted for tokens checked with the public key
+	// file. JWKS keys allow the algorithms of their type and alg instead
+	StaticAlgorithms []string `mapstructure:"JWT_STATIC_ALGORITHMS" validate:"required,dive,oneof=RS256 RS384 RS512 PS256 PS384 PS512"`
+}
+
+// EmergencyConfig holds settings for emergency access requests
+type EmergencyConfig struct {
+	DefaultWaitHours       int `mapstructure:"EMERGENCY_DEFAULT_WAIT_HOURS" validate:"required,min=1"`
+	ReleaseIntervalSeconds int `mapstructure:"EMERGENCY_RELEASE_INTERVAL_SECONDS" validate:"required,min=1"`
+}
+
+// AuditConfig holds settings for the audit hash chain
+type AuditConfig struct {
+	CheckpointIntervalSeconds int `mapstructure:"AUDIT_CHECKPOINT_INTERVAL_SECONDS" validate:"required,min=1"`
+}
+
+// WebhookConfig holds settings for outbound webhook delivery
+type WebhookConfig struct {
+	DeliveryIntervalSeconds int `mapstructure:"WEBHOOK_DELIVERY_INTERVAL_SECONDS" validate:"required,min=1"`
+	MaxAttempts             int `mapstructure:"WEBHOOK_MAX_ATTEMPTS" validate:"required,min=1"`
+	TimeoutSeconds          int `mapstructure:"WEBHOOK_TIMEOUT_SECONDS" validate:"required,min=1"`
+}
+
+// TracingConfig holds OpenTelemetry trace export settings
+type TracingConfig struct {
+	// Exporter is none, otlp, stdout or file
+	Exporter     string  `mapstructure:"TRACING_EXPORTER" validate:"required,oneof=none otlp stdout file"`
+	OTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	OTLPInsecure bool    `mapstructure:"TRACING_OTLP_INSECURE"`
+	FilePath     string  `mapstructure:"TRACING_FILE_PATH"`
+	SampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO" validate:"min=0,max=1"`
+}
+
+// RateLimitConfig holds the token bucket limits per route class
+type RateLimitConfig struct {
+	// Backend is none, memory or postgres, postgres shares limits across replicas
+	Backend    string  `mapstructure:"RATE_LIMIT_BACKEND" validate:"required,oneof=none memory postgres"`
+	ReadRate   float64 `mapstructure:"RATE_LIMIT_READ_RATE" validate:"gt=0"`
+	ReadBurst  int     `mapstructure:"RATE_LIMIT_READ_BURST" validate:"min=1"`
+	WriteRate  float64 `mapstructure:"RATE_LIMIT_WRITE_RATE" validate:"gt=0"`
+	WriteBurst int     `mapstructure:"RATE_LIMIT_WRITE_BURST" validate:"min=1"`
+	ShareRate  float64 `mapstructure:"RATE_LIMIT_SHARE_RATE" validate:"gt=0"`
+	ShareBurst int     `mapstructure:"RATE_LIMIT_SHARE_BURST" validate:"min=1"`
+	// IPRate and IPBurst apply per client IP before authentication, so they
+	// also count failed logins and should sit above the per-user limits
+	IPRate  float64 `mapstructure:"RATE_LIMIT_IP_RATE" validate:"gt=0"`
+	IPBurst int     `mapstructure:"RATE_LIMIT_IP_BURST" validate:"min=1"`
+}
+
+// APITokenConfig holds limits for service account tokens
+type APITokenConfig struct {
+	MaxLifetimeDays int `mapstructure:"API_TOKEN_MAX_LIFETIME_DAYS" validate:"required,min=1"`
+}
+
+// CORSConfig holds the cross-origin settings, origins are AllowedOrigins
+type CORSConfig struct {
+	AllowedMethods []string `mapstructure:"CORS_ALLOWED_METHODS" validate:"required,dive,oneof=GET HEAD POST PUT PATCH DELETE OPTIONS"`
+	AllowedHeaders []string `mapstructure:"CORS_ALLOWED_HEADERS" validate:"required"`
+	ExposedHeaders []string `mapstructure:"CORS_EXPOSED_HEADERS"`
+	MaxAgeSeconds  int      `mapstructure:"CORS_MAX_AGE_SECONDS" validate:"min=0"`
+}
+
+// TLSConfig holds native TLS settings, TLS is off without a certificate
+type TLSConfig struct {
+	CertPath string `mapstructure:"TLS_CERT_PATH" validate:"required_with=KeyPath ClientCAPath"`
+	KeyPath  string `mapstructure:"TLS_KEY_PATH" validate:"required_with=CertPath"`
+	// ClientCAPath enables mTLS, client certificates must chain to it
+	ClientCAPath string `mapstructure:"TLS_CLIENT_CA_PATH"`
+	// ClientAuth is require, or request to verify only certificates that are sent
+	ClientAuth            string `mapstructure:"TLS_CLIENT_AUTH" validate:"required,oneof=require request"`
+	ReloadIntervalSeconds int    `mapstructure:"TLS_RELOAD_INTERVAL_SECONDS" validate:"required,min=1"`
+}
+
+// ShutdownConfig holds graceful shutdown settings
+type ShutdownConfig struct {
+	// TimeoutSeconds bounds how long in-flight requests may drain on SIGTERM
+	TimeoutSeconds int `mapstructure:"SHUTDOWN_TIMEOUT_SECONDS" validate:"required,min=1"`
+	// DrainDelaySeconds is how long readiness fails before the listener
+	// closes, so load balancers see it. Keep it above the probe period
+	DrainDelaySeconds int `mapstructure:"SHUTDOWN_DRAIN_DELAY_SECONDS" validate:"min=0"`
+}
+
+// RevocationConfig holds settings for the token deny-list
+type RevocationConfig struct {
+	// CacheSeconds is how long a replica trusts its cached lookups, so a
+	// revocation made on another replica takes at most this long to apply
+	CacheSeconds           int `mapstructure:"REVOCATION_CACHE_SECONDS" validate:"min=0"`
+	CleanupIntervalSeconds int `mapstructure:"REVOCATION_CLEANUP_INTERVAL_SECONDS" validate:"required,min=1"`
 }
 
 // Config holds all configuration for the application
 type Config struct {
-	Environment string         `mapstructure:"ENVIRONMENT" validate:"required"`
-	ServerPort  string         `mapstructure:"SERVER_PORT" validate:"required"`
-	Database    DatabaseConfig `mapstructure:",squash"`
-	JWT         JWTConfig      `mapstructure:",squash"`
+	Environment    string           `mapstructure:"ENVIRONMENT" validate:"required"`
+	ServerPort     string           `mapstructure:"SERVER_PORT" validate:"required"`
+	ServerSocket   string           `mapstructure:"SERVER_SOCKET"`
+	MetricsPort    string           `mapstructure:"METRICS_PORT" validate:"required"`
+	LogLevel       string           `mapstructure:"LOG_LEVEL" validate:"required,oneof=debug info warn error"`
+	AutoMigrate    bool             `mapstructure:"AUTO_MIGRATE"`
+	AllowedOrigins []string         `mapstructure:"ALLOWED_ORIGINS" validate:"dive,url"`
+	TrustedProxies []string         `mapstructure:"TRUSTED_PROXIES" validate:"dive,cidr|ip"`
+	Database       DatabaseConfig   `mapstructure:",squash"`
+	JWT            JWTConfig        `mapstructure:",squash"`
+	Emergency      EmergencyConfig  `mapstructure:",squash"`
+	Audit          AuditConfig      `mapstructure:",squash"`
+	Webhook        WebhookConfig    `mapstructure:",squash"`
+	Tracing        TracingConfig    `mapstructure:",squash"`
+	RateLimit      RateLimitConfig  `mapstructure:",squash"`
+	APIToken       APITokenConfig   `mapstructure:",squash"`
+	Shutdown       ShutdownConfig   `mapstructure:",squash"`
+	CORS           CORSConfig       `mapstructure:",squash"`
+	TLS            TLSConfig        `mapstructure:",squash"`
+	Revocation     RevocationConfig `mapstructure:",squash"`
 }
 
 // LoadConfig loads the configurations from the .env file
@@ -41,9 +153,55 @@ func LoadConfig() (config *Config, err error) {
 	viper.AutomaticEnv()
 
 	// Defaults
+	viper.SetDefault("METRICS_PORT", "9090")
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("AUTO_MIGRATE", true)
+	viper.SetDefault("SHUTDOWN_TIMEOUT_SECONDS", 30)
+	viper.SetDefault("SHUTDOWN_DRAIN_DELAY_SECONDS", 5)
+	viper.SetDefault("ALLOWED_ORIGINS", "http://localhost:5173,http://localhost:5174")
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("SERVER_SOCKET", "")
+	viper.SetDefault("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE")
+	viper.SetDefault("CORS_ALLOWED_HEADERS", "Authorization,Content-Type,X-Request-ID")
+	viper.SetDefault("CORS_EXPOSED_HEADERS", "X-Request-ID,Retry-After,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset")
+	viper.SetDefault("CORS_MAX_AGE_SECONDS", 43200)
+	viper.SetDefault("TLS_CERT_PATH", "")
+	viper.SetDefault("TLS_KEY_PATH", "")
+	viper.SetDefault("TLS_CLIENT_CA_PATH", "")
+	viper.SetDefault("TLS_CLIENT_AUTH", "require")
+	viper.SetDefault("TLS_RELOAD_INTERVAL_SECONDS", 30)
 	viper.SetDefault("DB_SSLMODE", "disable")
 	viper.SetDefault("JWT_ISSUER", "auth-service")
 	viper.SetDefault("JWT_EXPIRATION_HOURS", 24)
+	viper.SetDefault("JWT_AUDIENCE", "")
+	viper.SetDefault("JWT_LEEWAY_SECONDS", 5)
+	viper.SetDefault("JWT_STATIC_ALGORITHMS", "RS512")
+	viper.SetDefault("JWKS_URL", "")
+	viper.SetDefault("JWKS_REFRESH_INTERVAL_SECONDS", 300)
+	viper.SetDefault("JWKS_MIN_REFRESH_INTERVAL_SECONDS", 30)
+	viper.SetDefault("REVOCATION_CACHE_SECONDS", 30)
+	viper.SetDefault("REVOCATION_CLEANUP_INTERVAL_SECONDS", 3600)
+	viper.SetDefault("EMERGENCY_DEFAULT_WAIT_HOURS", 48)
+	viper.SetDefault("EMERGENCY_RELEASE_INTERVAL_SECONDS", 60)
+	viper.SetDefault("AUDIT_CHECKPOINT_INTERVAL_SECONDS", 300)
+	viper.SetDefault("WEBHOOK_DELIVERY_INTERVAL_SECONDS", 5)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_TIMEOUT_SECONDS", 10)
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_OTLP_ENDPOINT", "localhost:4318")
+	viper.SetDefault("TRACING_OTLP_INSECURE", false)
+	viper.SetDefault("TRACING_FILE_PATH", "traces.jsonl")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("API_TOKEN_MAX_LIFETIME_DAYS", 365)
+	viper.SetDefault("RATE_LIMIT_BACKEND", "memory")
+	viper.SetDefault("RATE_LIMIT_READ_RATE", 20)
+	viper.SetDefault("RATE_LIMIT_READ_BURST", 60)
+	viper.SetDefault("RATE_LIMIT_WRITE_RATE", 5)
+	viper.SetDefault("RATE_LIMIT_WRITE_BURST", 20)
+	viper.SetDefault("RATE_LIMIT_SHARE_RATE", 1)
+	viper.SetDefault("RATE_LIMIT_SHARE_BURST", 10)
+	viper.SetDefault("RATE_LIMIT_IP_RATE", 50)
+	viper.SetDefault("RATE_LIMIT_IP_BURST", 200)
 
 	// Read Config
 	if err := viper.ReadInConfig(); err != nil {
@@ -0,0 +1,96 @@
+-- Queries for the vaultctl admin tool
+
+-- name: GetVaultStats :one
+SELECT
+    (SELECT COUNT(*) FROM folders WHERE deleted_at IS NULL)::bigint AS folders,
+    (SELECT COUNT(*) FROM items WHERE deleted_at IS NULL)::bigint AS items,
+    (SELECT COUNT(*) FROM folders WHERE deleted_at IS NOT NULL)::bigint AS trashed_folders,
+    (SELECT COUNT(*) FROM items WHERE deleted_at IS NOT NULL)::bigint AS trashed_items,
+    (SELECT COUNT(*) FROM keys)::bigint AS grants,
+    (SELECT COUNT(DISTINCT user_id) FROM keys)::bigint AS users,
+    (SELECT COUNT(*) FROM organizations)::bigint AS organizations,
+    (SELECT COUNT(*) FROM groups)::bigint AS groups,
+    (SELECT COUNT(*) FROM service_accounts)::bigint AS service_accounts,
+    (SELECT COUNT(*) FROM api_tokens WHERE revoked_at IS NULL AND expires_at > NOW())::bigint AS active_api_tokens,
+    (SELECT COUNT(*) FROM projects)::bigint AS projects,
+    (SELECT COUNT(*) FROM audit_events)::bigint AS audit_events,
+    (SELECT COUNT(*) FROM webhook_deliveries WHERE status = 'PENDING')::bigint AS pending_webhook_deliveries,
+    (SELECT COUNT(*) FROM webhook_deliveries WHERE status = 'DEAD')::bigint AS dead_webhook_deliveries;
+
+-- name: PurgeDeletedItems :execrows
+DELETE FROM items
+WHERE deleted_at < $1;
+
+-- name: PurgeDeletedFolders :execrows
+-- Folders still holding items are kept until those are purged too
+DELETE FROM folders f
+WHERE f.deleted_at < $1
+  AND NOT EXISTS (SELECT 1 FROM items i WHERE i.folder_id = f.id);
+
+-- name: DeleteUserGrants :many
+-- Owner keys stay, the user keeps what they own
+DELETE FROM keys
+WHERE user_id = $1
+  AND access_level <> 'OWNER'
+RETURNING folder_id, item_id;
+
+-- name: RemoveUserFromAllGroups :execrows
+DELETE FROM group_members
+WHERE user_id = $1;
+
+-- name: GetUserItemsExport :many
+SELECT
+    i.id,
+    i.folder_id,
+    i.org_id,
+    i.type,
+    i.nonce AS data_nonce,
+    i.enc_data,
+    i.overview_nonce,
+    i.enc_overview,
+    i.created_at,
+    i.updated_at,
+    k.enc_key AS wrapped_key,
+    k.nonce AS key_nonce,
+    k.access_level
+FROM items i
+JOIN keys k ON i.id = k.item_id
+WHERE k.user_id = $1
+  AND i.deleted_at IS NULL
+ORDER BY i.created_at ASC;
+
+-- name: FsckItemsInDeletedFolders :many
+SELECT i.id
+FROM items i
+JOIN folders f ON f.id = i.folder_id
+WHERE i.deleted_at IS NULL
+  AND f.deleted_at IS NOT NULL;
+
+-- name: FsckFoldersWithoutOwnerKey :many
+SELECT f.id
+FROM folders f
+WHERE f.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys k
+    WHERE k.folder_id = f.id AND k.access_level = 'OWNER'
+  );
+
+-- name: FsckItemsWithoutOwnerKey :many
+SELECT i.id
+FROM items i
+WHERE i.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys k
+    WHERE k.item_id = i.id AND k.access_level = 'OWNER'
+  );
+
+-- name: FsckExpiredGrants :many
+SELECT id
+FROM keys
+WHERE expires_at < NOW();
+
+-- name: FsckEnvironmentsWithDeletedFolder :many
+SELECT e.id
+FROM environments e
+JOIN folders f ON f.id = e.folder_id
+WHERE f.deleted_at IS NOT NULL;
@@ -0,0 +1,97 @@
+-- name: LockAuditChain :exec
+-- Serializes writers so every event links to the one committed before it
+SELECT pg_advisory_xact_lock(hashtext('audit_events'));
+
+-- name: GetAuditChainHead :one
+SELECT seq::bigint AS seq, hash
+FROM audit_events
+WHERE seq IS NOT NULL
+ORDER BY seq DESC
+LIMIT 1;
+
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (
+    id,
+    actor_id,
+    action,
+    resource_type,
+    resource_id,
+    owner_id,
+    org_id,
+    target_user_id,
+    ip,
+    user_agent,
+    created_at,
+    seq,
+    prev_hash,
+    hash
+)
+VALUES (
+    sqlc.arg(id),
+    sqlc.arg(actor_id),
+    sqlc.arg(action),
+    sqlc.narg(resource_type),
+    sqlc.narg(resource_id),
+    sqlc.narg(owner_id),
+    sqlc.narg(org_id),
+    sqlc.narg(target_user_id),
+    sqlc.narg(ip),
+    sqlc.narg(user_agent),
+    sqlc.arg(created_at),
+    sqlc.arg(seq)::bigint,
+    sqlc.narg(prev_hash),
+    sqlc.arg(hash)
+);
+
+-- name: ListChainedAuditEvents :many
+SELECT
+    id,
+    actor_id,
+    action,
+    resource_type,
+    resource_id,
+    owner_id,
+    org_id,
+    target_user_id,
+    ip,
+    user_agent,
+    created_at,
+    seq::bigint AS seq,
+    prev_hash,
+    hash
+FROM audit_events
+WHERE seq > sqlc.arg(after_seq)::bigint
+ORDER BY seq ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: CountUnchainedAuditEvents :one
+-- Events written before the first chained one
+SELECT COUNT(*) FROM audit_events
+WHERE seq IS NULL
+  AND created_at < COALESCE(
+    (SELECT c.created_at FROM audit_events c WHERE c.seq IS NOT NULL ORDER BY c.seq ASC LIMIT 1),
+    'infinity'
+  );
+
+-- name: ListLateUnchainedAuditEvents :many
+-- Unchained events written at or after the first chained one
+SELECT id FROM audit_events
+WHERE seq IS NULL
+  AND created_at >= (SELECT c.created_at FROM audit_events c WHERE c.seq IS NOT NULL ORDER BY c.seq ASC LIMIT 1)
+ORDER BY created_at ASC;
+
+-- name: GetLatestAuditCheckpoint :one
+SELECT seq, hash, signature, created_at
+FROM audit_checkpoints
+ORDER BY seq DESC
+LIMIT 1;
+
+-- name: CreateAuditCheckpoint :exec
+INSERT INTO audit_checkpoints (seq, hash, signature)
+VALUES ($1, $2, $3)
+ON CONFLICT (seq) DO NOTHING;
+
+-- name: ListAuditCheckpoints :many
+SELECT seq, hash, signature, created_at
+FROM audit_checkpoints
+ORDER BY seq ASC;
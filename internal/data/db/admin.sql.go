@@ -0,0 +1,359 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: admin.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteUserGrants = `-- name: DeleteUserGrants :many
+DELETE FROM keys
+WHERE user_id = $1
+  AND access_level <> 'OWNER'
+RETURNING folder_id, item_id
+`
+
+type DeleteUserGrantsRow struct {
+	FolderID *uuid.UUID
+	ItemID   *uuid.UUID
+}
+
+// Owner keys stay, the user keeps what they own
+func (q *Queries) DeleteUserGrants(ctx context.Context, userID uuid.UUID) ([]DeleteUserGrantsRow, error) {
+	rows, err := q.db.Query(ctx, deleteUserGrants, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeleteUserGrantsRow
+	for rows.Next() {
+		var i DeleteUserGrantsRow
+		if err := rows.Scan(&i.FolderID, &i.ItemID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fsckEnvironmentsWithDeletedFolder = `-- name: FsckEnvironmentsWithDeletedFolder :many
+SELECT e.id
+FROM environments e
+JOIN folders f ON f.id = e.folder_id
+WHERE f.deleted_at IS NOT NULL
+`
+
+func (q *Queries) FsckEnvironmentsWithDeletedFolder(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, fsckEnvironmentsWithDeletedFolder)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fsckExpiredGrants = `-- name: FsckExpiredGrants :many
+SELECT id
+FROM keys
+WHERE expires_at < NOW()
+`
+
+func (q *Queries) FsckExpiredGrants(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, fsckExpiredGrants)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fsckFoldersWithoutOwnerKey = `-- name: FsckFoldersWithoutOwnerKey :many
+SELECT f.id
+FROM folders f
+WHERE f.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys k
+    WHERE k.folder_id = f.id AND k.access_level = 'OWNER'
+  )
+`
+
+func (q *Queries) FsckFoldersWithoutOwnerKey(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, fsckFoldersWithoutOwnerKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fsckItemsInDeletedFolders = `-- name: FsckItemsInDeletedFolders :many
+SELECT i.id
+FROM items i
+JOIN folders f ON f.id = i.folder_id
+WHERE i.deleted_at IS NULL
+  AND f.deleted_at IS NOT NULL
+`
+
+func (q *Queries) FsckItemsInDeletedFolders(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, fsckItemsInDeletedFolders)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fsckItemsWithoutOwnerKey = `-- name: FsckItemsWithoutOwnerKey :many
+SELECT i.id
+FROM items i
+WHERE i.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys k
+    WHERE k.item_id = i.id AND k.access_level = 'OWNER'
+  )
+`
+
+func (q *Queries) FsckItemsWithoutOwnerKey(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, fsckItemsWithoutOwnerKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserItemsExport = `-- name: GetUserItemsExport :many
+SELECT
+    i.id,
+    i.folder_id,
+    i.org_id,
+    i.type,
+    i.nonce AS data_nonce,
+    i.enc_data,
+    i.overview_nonce,
+    i.enc_overview,
+    i.created_at,
+    i.updated_at,
+    k.enc_key AS wrapped_key,
+    k.nonce AS key_nonce,
+    k.access_level
+FROM items i
+JOIN keys k ON i.id = k.item_id
+WHERE k.user_id = $1
+  AND i.deleted_at IS NULL
+ORDER BY i.created_at ASC
+`
+
+type GetUserItemsExportRow struct {
+	ID            uuid.UUID
+	FolderID      *uuid.UUID
+	OrgID         *uuid.UUID
+	Type          string
+	DataNonce     []byte
+	EncData       []byte
+	OverviewNonce []byte
+	EncOverview   []byte
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	WrappedKey    []byte
+	KeyNonce      []byte
+	AccessLevel   string
+}
+
+func (q *Queries) GetUserItemsExport(ctx context.Context, userID uuid.UUID) ([]GetUserItemsExportRow, error) {
+	rows, err := q.db.Query(ctx, getUserItemsExport, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserItemsExportRow
+	for rows.Next() {
+		var i GetUserItemsExportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FolderID,
+			&i.OrgID,
+			&i.Type,
+			&i.DataNonce,
+			&i.EncData,
+			&i.OverviewNonce,
+			&i.EncOverview,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.WrappedKey,
+			&i.KeyNonce,
+			&i.AccessLevel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVaultStats = `-- name: GetVaultStats :one
+
+SELECT
+    (SELECT COUNT(*) FROM folders WHERE deleted_at IS NULL)::bigint AS folders,
+    (SELECT COUNT(*) FROM items WHERE deleted_at IS NULL)::bigint AS items,
+    (SELECT COUNT(*) FROM folders WHERE deleted_at IS NOT NULL)::bigint AS trashed_folders,
+    (SELECT COUNT(*) FROM items WHERE deleted_at IS NOT NULL)::bigint AS trashed_items,
+    (SELECT COUNT(*) FROM keys)::bigint AS grants,
+    (SELECT COUNT(DISTINCT user_id) FROM keys)::bigint AS users,
+    (SELECT COUNT(*) FROM organizations)::bigint AS organizations,
+    (SELECT COUNT(*) FROM groups)::bigint AS groups,
+    (SELECT COUNT(*) FROM service_accounts)::bigint AS service_accounts,
+    (SELECT COUNT(*) FROM api_tokens WHERE revoked_at IS NULL AND expires_at > NOW())::bigint AS active_api_tokens,
+    (SELECT COUNT(*) FROM projects)::bigint AS projects,
+    (SELECT COUNT(*) FROM audit_events)::bigint AS audit_events,
+    (SELECT COUNT(*) FROM webhook_deliveries WHERE status = 'PENDING')::bigint AS pending_webhook_deliveries,
+    (SELECT COUNT(*) FROM webhook_deliveries WHERE status = 'DEAD')::bigint AS dead_webhook_deliveries
+`
+
+type GetVaultStatsRow struct {
+	Folders                  int64
+	Items                    int64
+	TrashedFolders           int64
+	TrashedItems             int64
+	Grants                   int64
+	Users                    int64
+	Organizations            int64
+	Groups                   int64
+	ServiceAccounts          int64
+	ActiveApiTokens          int64
+	Projects                 int64
+	AuditEvents              int64
+	PendingWebhookDeliveries int64
+	DeadWebhookDeliveries    int64
+}
+
+// Queries for the vaultctl admin tool
+func (q *Queries) GetVaultStats(ctx context.Context) (GetVaultStatsRow, error) {
+	row := q.db.QueryRow(ctx, getVaultStats)
+	var i GetVaultStatsRow
+	err := row.Scan(
+		&i.Folders,
+		&i.Items,
+		&i.TrashedFolders,
+		&i.TrashedItems,
+		&i.Grants,
+		&i.Users,
+		&i.Organizations,
+		&i.Groups,
+		&i.ServiceAccounts,
+		&i.ActiveApiTokens,
+		&i.Projects,
+		&i.AuditEvents,
+		&i.PendingWebhookDeliveries,
+		&i.DeadWebhookDeliveries,
+	)
+	return i, err
+}
+
+const purgeDeletedFolders = `-- name: PurgeDeletedFolders :execrows
+DELETE FROM folders f
+WHERE f.deleted_at < $1
+  AND NOT EXISTS (SELECT 1 FROM items i WHERE i.folder_id = f.id)
+`
+
+// Folders still holding items are kept until those are purged too
+func (q *Queries) PurgeDeletedFolders(ctx context.Context, deletedAt *time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedFolders, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeDeletedItems = `-- name: PurgeDeletedItems :execrows
+DELETE FROM items
+WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedItems(ctx context.Context, deletedAt *time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedItems, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const removeUserFromAllGroups = `-- name: RemoveUserFromAllGroups :execrows
+DELETE FROM group_members
+WHERE user_id = $1
+`
+
+func (q *Queries) RemoveUserFromAllGroups(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, removeUserFromAllGroups, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
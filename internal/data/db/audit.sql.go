@@ -0,0 +1,306 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: audit.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countUnchainedAuditEvents = `-- name: CountUnchainedAuditEvents :one
+SELECT COUNT(*) FROM audit_events
+WHERE seq IS NULL
+  AND created_at < COALESCE(
+    (SELECT c.created_at FROM audit_events c WHERE c.seq IS NOT NULL ORDER BY c.seq ASC LIMIT 1),
+    'infinity'
+  )
+`
+
+// Events written before the first chained one
+func (q *Queries) CountUnchainedAuditEvents(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countUnchainedAuditEvents)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAuditCheckpoint = `-- name: CreateAuditCheckpoint :exec
+INSERT INTO audit_checkpoints (seq, hash, signature)
+VALUES ($1, $2, $3)
+ON CONFLICT (seq) DO NOTHING
+`
+
+type CreateAuditCheckpointParams struct {
+	Seq       int64
+	Hash      []byte
+	Signature []byte
+}
+
+func (q *Queries) CreateAuditCheckpoint(ctx context.Context, arg CreateAuditCheckpointParams) error {
+	_, err := q.db.Exec(ctx, createAuditCheckpoint, arg.Seq, arg.Hash, arg.Signature)
+	return err
+}
+
+const createAuditEvent = `-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (
+    id,
+    actor_id,
+    action,
+    resource_type,
+    resource_id,
+    owner_id,
+    org_id,
+    target_user_id,
+    ip,
+    user_agent,
+    created_at,
+    seq,
+    prev_hash,
+    hash
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12::bigint,
+    $13,
+    $14
+)
+`
+
+type CreateAuditEventParams struct {
+	ID           uuid.UUID
+	ActorID      uuid.UUID
+	Action       string
+	ResourceType *string
+	ResourceID   *uuid.UUID
+	OwnerID      *uuid.UUID
+	OrgID        *uuid.UUID
+	TargetUserID *uuid.UUID
+	Ip           *string
+	UserAgent    *string
+	CreatedAt    time.Time
+	Seq          int64
+	PrevHash     []byte
+	Hash         []byte
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error {
+	_, err := q.db.Exec(ctx, createAuditEvent,
+		arg.ID,
+		arg.ActorID,
+		arg.Action,
+		arg.ResourceType,
+		arg.ResourceID,
+		arg.OwnerID,
+		arg.OrgID,
+		arg.TargetUserID,
+		arg.Ip,
+		arg.UserAgent,
+		arg.CreatedAt,
+		arg.Seq,
+		arg.PrevHash,
+		arg.Hash,
+	)
+	return err
+}
+
+const getAuditChainHead = `-- name: GetAuditChainHead :one
+SELECT seq::bigint AS seq, hash
+FROM audit_events
+WHERE seq IS NOT NULL
+ORDER BY seq DESC
+LIMIT 1
+`
+
+type GetAuditChainHeadRow struct {
+	Seq  int64
+	Hash []byte
+}
+
+func (q *Queries) GetAuditChainHead(ctx context.Context) (GetAuditChainHeadRow, error) {
+	row := q.db.QueryRow(ctx, getAuditChainHead)
+	var i GetAuditChainHeadRow
+	err := row.Scan(&i.Seq, &i.Hash)
+	return i, err
+}
+
+const getLatestAuditCheckpoint = `-- name: GetLatestAuditCheckpoint :one
+SELECT seq, hash, signature, created_at
+FROM audit_checkpoints
+ORDER BY seq DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestAuditCheckpoint(ctx context.Context) (AuditCheckpoint, error) {
+	row := q.db.QueryRow(ctx, getLatestAuditCheckpoint)
+	var i AuditCheckpoint
+	err := row.Scan(
+		&i.Seq,
+		&i.Hash,
+		&i.Signature,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAuditCheckpoints = `-- name: ListAuditCheckpoints :many
+SELECT seq, hash, signature, created_at
+FROM audit_checkpoints
+ORDER BY seq ASC
+`
+
+func (q *Queries) ListAuditCheckpoints(ctx context.Context) ([]AuditCheckpoint, error) {
+	rows, err := q.db.Query(ctx, listAuditCheckpoints)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditCheckpoint
+	for rows.Next() {
+		var i AuditCheckpoint
+		if err := rows.Scan(
+			&i.Seq,
+			&i.Hash,
+			&i.Signature,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChainedAuditEvents = `-- name: ListChainedAuditEvents :many
+SELECT
+    id,
+    actor_id,
+    action,
+    resource_type,
+    resource_id,
+    owner_id,
+    org_id,
+    target_user_id,
+    ip,
+    user_agent,
+    created_at,
+    seq::bigint AS seq,
+    prev_hash,
+    hash
+FROM audit_events
+WHERE seq > $1::bigint
+ORDER BY seq ASC
+LIMIT $2
+`
+
+type ListChainedAuditEventsParams struct {
+	AfterSeq int64
+	RowLimit int32
+}
+
+type ListChainedAuditEventsRow struct {
+	ID           uuid.UUID
+	ActorID      uuid.UUID
+	Action       string
+	ResourceType *string
+	ResourceID   *uuid.UUID
+	OwnerID      *uuid.UUID
+	OrgID        *uuid.UUID
+	TargetUserID *uuid.UUID
+	Ip           *string
+	UserAgent    *string
+	CreatedAt    time.Time
+	Seq          int64
+	PrevHash     []byte
+	Hash         []byte
+}
+
+func (q *Queries) ListChainedAuditEvents(ctx context.Context, arg ListChainedAuditEventsParams) ([]ListChainedAuditEventsRow, error) {
+	rows, err := q.db.Query(ctx, listChainedAuditEvents, arg.AfterSeq, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChainedAuditEventsRow
+	for rows.Next() {
+		var i ListChainedAuditEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorID,
+			&i.Action,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.OwnerID,
+			&i.OrgID,
+			&i.TargetUserID,
+			&i.Ip,
+			&i.UserAgent,
+			&i.CreatedAt,
+			&i.Seq,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLateUnchainedAuditEvents = `-- name: ListLateUnchainedAuditEvents :many
+SELECT id FROM audit_events
+WHERE seq IS NULL
+  AND created_at >= (SELECT c.created_at FROM audit_events c WHERE c.seq IS NOT NULL ORDER BY c.seq ASC LIMIT 1)
+ORDER BY created_at ASC
+`
+
+// Unchained events written at or after the first chained one
+func (q *Queries) ListLateUnchainedAuditEvents(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listLateUnchainedAuditEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAuditChain = `-- name: LockAuditChain :exec
+SELECT pg_advisory_xact_lock(hashtext('audit_events'))
+`
+
+// Serializes writers so every event links to the one committed before it
+func (q *Queries) LockAuditChain(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockAuditChain)
+	return err
+}
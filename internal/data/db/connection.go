@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/axosec/vault/internal/config"
+	"github.com/axosec/vault/internal/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -22,6 +23,7 @@ func NewConnection(cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
 	dbConfig.MaxConnIdleTime = 30 * time.Minute
 	dbConfig.HealthCheckPeriod = time.Minute
 	dbConfig.ConnConfig.ConnectTimeout = time.Second * 5
+	dbConfig.ConnConfig.Tracer = tracing.NewQueryTracer()
 
 	connPool, err := pgxpool.NewWithConfig(context.Background(), dbConfig)
 	if err != nil {
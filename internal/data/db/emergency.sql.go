@@ -0,0 +1,413 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: emergency.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createEmergencyContact = `-- name: CreateEmergencyContact :one
+INSERT INTO emergency_contacts (grantor_id, grantee_id, wait_hours)
+VALUES ($1, $2, $3)
+RETURNING id, grantor_id, grantee_id, wait_hours, status, requested_at, released_at, created_at, updated_at
+`
+
+type CreateEmergencyContactParams struct {
+	GrantorID uuid.UUID
+	GranteeID uuid.UUID
+	WaitHours int32
+}
+
+func (q *Queries) CreateEmergencyContact(ctx context.Context, arg CreateEmergencyContactParams) (EmergencyContact, error) {
+	row := q.db.QueryRow(ctx, createEmergencyContact, arg.GrantorID, arg.GranteeID, arg.WaitHours)
+	var i EmergencyContact
+	err := row.Scan(
+		&i.ID,
+		&i.GrantorID,
+		&i.GranteeID,
+		&i.WaitHours,
+		&i.Status,
+		&i.RequestedAt,
+		&i.ReleasedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteEmergencyContact = `-- name: DeleteEmergencyContact :execrows
+DELETE FROM emergency_contacts
+WHERE id = $1 AND grantor_id = $2
+`
+
+type DeleteEmergencyContactParams struct {
+	ID        uuid.UUID
+	GrantorID uuid.UUID
+}
+
+func (q *Queries) DeleteEmergencyContact(ctx context.Context, arg DeleteEmergencyContactParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteEmergencyContact, arg.ID, arg.GrantorID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getEmergencyContactForUpdate = `-- name: GetEmergencyContactForUpdate :one
+SELECT id, grantor_id, grantee_id, wait_hours, status, requested_at, released_at, created_at, updated_at
+FROM emergency_contacts
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetEmergencyContactForUpdate(ctx context.Context, id uuid.UUID) (EmergencyContact, error) {
+	row := q.db.QueryRow(ctx, getEmergencyContactForUpdate, id)
+	var i EmergencyContact
+	err := row.Scan(
+		&i.ID,
+		&i.GrantorID,
+		&i.GranteeID,
+		&i.WaitHours,
+		&i.Status,
+		&i.RequestedAt,
+		&i.ReleasedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEmergencyContactsByGrantee = `-- name: GetEmergencyContactsByGrantee :many
+SELECT
+    c.id,
+    c.grantor_id,
+    c.grantee_id,
+    c.wait_hours,
+    c.status,
+    c.requested_at,
+    c.released_at,
+    c.created_at,
+    c.updated_at,
+    (SELECT COUNT(*) FROM emergency_keys ek WHERE ek.contact_id = c.id) AS key_count
+FROM emergency_contacts c
+WHERE c.grantee_id = $1
+ORDER BY c.created_at ASC
+`
+
+type GetEmergencyContactsByGranteeRow struct {
+	ID          uuid.UUID
+	GrantorID   uuid.UUID
+	GranteeID   uuid.UUID
+	WaitHours   int32
+	Status      string
+	RequestedAt *time.Time
+	ReleasedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	KeyCount    int64
+}
+
+func (q *Queries) GetEmergencyContactsByGrantee(ctx context.Context, granteeID uuid.UUID) ([]GetEmergencyContactsByGranteeRow, error) {
+	rows, err := q.db.Query(ctx, getEmergencyContactsByGrantee, granteeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEmergencyContactsByGranteeRow
+	for rows.Next() {
+		var i GetEmergencyContactsByGranteeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GrantorID,
+			&i.GranteeID,
+			&i.WaitHours,
+			&i.Status,
+			&i.RequestedAt,
+			&i.ReleasedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.KeyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEmergencyContactsByGrantor = `-- name: GetEmergencyContactsByGrantor :many
+SELECT
+    c.id,
+    c.grantor_id,
+    c.grantee_id,
+    c.wait_hours,
+    c.status,
+    c.requested_at,
+    c.released_at,
+    c.created_at,
+    c.updated_at,
+    (SELECT COUNT(*) FROM emergency_keys ek WHERE ek.contact_id = c.id) AS key_count
+FROM emergency_contacts c
+WHERE c.grantor_id = $1
+ORDER BY c.created_at ASC
+`
+
+type GetEmergencyContactsByGrantorRow struct {
+	ID          uuid.UUID
+	GrantorID   uuid.UUID
+	GranteeID   uuid.UUID
+	WaitHours   int32
+	Status      string
+	RequestedAt *time.Time
+	ReleasedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	KeyCount    int64
+}
+
+func (q *Queries) GetEmergencyContactsByGrantor(ctx context.Context, grantorID uuid.UUID) ([]GetEmergencyContactsByGrantorRow, error) {
+	rows, err := q.db.Query(ctx, getEmergencyContactsByGrantor, grantorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEmergencyContactsByGrantorRow
+	for rows.Next() {
+		var i GetEmergencyContactsByGrantorRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GrantorID,
+			&i.GranteeID,
+			&i.WaitHours,
+			&i.Status,
+			&i.RequestedAt,
+			&i.ReleasedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.KeyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueEmergencyRequests = `-- name: ListDueEmergencyRequests :many
+SELECT id
+FROM emergency_contacts
+WHERE status = 'REQUESTED'
+  AND requested_at + make_interval(hours => wait_hours) <= NOW()
+ORDER BY requested_at ASC
+LIMIT 100
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ListDueEmergencyRequests(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listDueEmergencyRequests)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseEmergencyFolderKeys = `-- name: ReleaseEmergencyFolderKeys :many
+INSERT INTO keys (user_id, folder_id, enc_key, nonce, access_level, granted_by)
+SELECT
+    c.grantee_id,
+    ek.folder_id,
+    ek.enc_key,
+    ek.nonce,
+    'READ',
+    c.grantor_id
+FROM emergency_keys ek
+JOIN emergency_contacts c ON c.id = ek.contact_id
+WHERE c.id = $1
+  AND ek.folder_id IS NOT NULL
+ON CONFLICT (user_id, folder_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    -- A permanent WRITE or OWNER grant is kept, anything else becomes a
+    -- permanent READ so the contact is never left with an expired grant
+    access_level = CASE
+        WHEN keys.expires_at IS NULL AND keys.access_level IN ('WRITE', 'OWNER') THEN keys.access_level
+        ELSE EXCLUDED.access_level
+    END,
+    granted_by = CASE
+        WHEN keys.expires_at IS NULL AND keys.access_level IN ('WRITE', 'OWNER') THEN keys.granted_by
+        ELSE EXCLUDED.granted_by
+    END,
+    expires_at = NULL
+RETURNING folder_id
+`
+
+// Copy the pre-wrapped folder keys into the regular access model as READ
+// grants, refreshing any grant the contact already holds
+func (q *Queries) ReleaseEmergencyFolderKeys(ctx context.Context, id uuid.UUID) ([]*uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, releaseEmergencyFolderKeys, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*uuid.UUID
+	for rows.Next() {
+		var folder_id *uuid.UUID
+		if err := rows.Scan(&folder_id); err != nil {
+			return nil, err
+		}
+		items = append(items, folder_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseEmergencyItemKeys = `-- name: ReleaseEmergencyItemKeys :many
+INSERT INTO keys (user_id, item_id, enc_key, nonce, access_level, granted_by)
+SELECT
+    c.grantee_id,
+    ek.item_id,
+    ek.enc_key,
+    ek.nonce,
+    'READ',
+    c.grantor_id
+FROM emergency_keys ek
+JOIN emergency_contacts c ON c.id = ek.contact_id
+WHERE c.id = $1
+  AND ek.item_id IS NOT NULL
+ON CONFLICT (user_id, item_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    -- A permanent WRITE or OWNER grant is kept, anything else becomes a
+    -- permanent READ so the contact is never left with an expired grant
+    access_level = CASE
+        WHEN keys.expires_at IS NULL AND keys.access_level IN ('WRITE', 'OWNER') THEN keys.access_level
+        ELSE EXCLUDED.access_level
+    END,
+    granted_by = CASE
+        WHEN keys.expires_at IS NULL AND keys.access_level IN ('WRITE', 'OWNER') THEN keys.granted_by
+        ELSE EXCLUDED.granted_by
+    END,
+    expires_at = NULL
+RETURNING item_id
+`
+
+// Same as ReleaseEmergencyFolderKeys for item keys
+func (q *Queries) ReleaseEmergencyItemKeys(ctx context.Context, id uuid.UUID) ([]*uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, releaseEmergencyItemKeys, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*uuid.UUID
+	for rows.Next() {
+		var item_id *uuid.UUID
+		if err := rows.Scan(&item_id); err != nil {
+			return nil, err
+		}
+		items = append(items, item_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setEmergencyContactStatus = `-- name: SetEmergencyContactStatus :exec
+UPDATE emergency_contacts
+SET
+    status = $1,
+    requested_at = CASE WHEN $1::text = 'REQUESTED' THEN NOW() ELSE requested_at END,
+    released_at = CASE WHEN $1::text = 'RELEASED' THEN NOW() ELSE released_at END,
+    updated_at = NOW()
+WHERE id = $2
+`
+
+type SetEmergencyContactStatusParams struct {
+	Status string
+	ID     uuid.UUID
+}
+
+func (q *Queries) SetEmergencyContactStatus(ctx context.Context, arg SetEmergencyContactStatusParams) error {
+	_, err := q.db.Exec(ctx, setEmergencyContactStatus, arg.Status, arg.ID)
+	return err
+}
+
+const upsertEmergencyFolderKey = `-- name: UpsertEmergencyFolderKey :exec
+INSERT INTO emergency_keys (contact_id, folder_id, enc_key, nonce)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (contact_id, folder_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce
+`
+
+type UpsertEmergencyFolderKeyParams struct {
+	ContactID uuid.UUID
+	FolderID  *uuid.UUID
+	EncKey    []byte
+	Nonce     []byte
+}
+
+func (q *Queries) UpsertEmergencyFolderKey(ctx context.Context, arg UpsertEmergencyFolderKeyParams) error {
+	_, err := q.db.Exec(ctx, upsertEmergencyFolderKey,
+		arg.ContactID,
+		arg.FolderID,
+		arg.EncKey,
+		arg.Nonce,
+	)
+	return err
+}
+
+const upsertEmergencyItemKey = `-- name: UpsertEmergencyItemKey :exec
+INSERT INTO emergency_keys (contact_id, item_id, enc_key, nonce)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (contact_id, item_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce
+`
+
+type UpsertEmergencyItemKeyParams struct {
+	ContactID uuid.UUID
+	ItemID    *uuid.UUID
+	EncKey    []byte
+	Nonce     []byte
+}
+
+func (q *Queries) UpsertEmergencyItemKey(ctx context.Context, arg UpsertEmergencyItemKeyParams) error {
+	_, err := q.db.Exec(ctx, upsertEmergencyItemKey,
+		arg.ContactID,
+		arg.ItemID,
+		arg.EncKey,
+		arg.Nonce,
+	)
+	return err
+}
@@ -0,0 +1,322 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: group.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const addGroupMember = `-- name: AddGroupMember :exec
+INSERT INTO group_members (group_id, user_id, enc_key, nonce, added_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (group_id, user_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    added_by = EXCLUDED.added_by
+`
+
+type AddGroupMemberParams struct {
+	GroupID uuid.UUID
+	UserID  uuid.UUID
+	EncKey  []byte
+	Nonce   []byte
+	AddedBy uuid.UUID
+}
+
+func (q *Queries) AddGroupMember(ctx context.Context, arg AddGroupMemberParams) error {
+	_, err := q.db.Exec(ctx, addGroupMember,
+		arg.GroupID,
+		arg.UserID,
+		arg.EncKey,
+		arg.Nonce,
+		arg.AddedBy,
+	)
+	return err
+}
+
+const createGroup = `-- name: CreateGroup :one
+INSERT INTO groups (org_id, name, created_by)
+VALUES ($1, $2, $3)
+RETURNING id, org_id, name, created_by, created_at
+`
+
+type CreateGroupParams struct {
+	OrgID     uuid.UUID
+	Name      string
+	CreatedBy uuid.UUID
+}
+
+func (q *Queries) CreateGroup(ctx context.Context, arg CreateGroupParams) (Group, error) {
+	row := q.db.QueryRow(ctx, createGroup, arg.OrgID, arg.Name, arg.CreatedBy)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createGroupFolderKey = `-- name: CreateGroupFolderKey :exec
+INSERT INTO group_keys (group_id, folder_id, enc_key, nonce, access_level, granted_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (group_id, folder_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    access_level = EXCLUDED.access_level,
+    granted_by = EXCLUDED.granted_by
+`
+
+type CreateGroupFolderKeyParams struct {
+	GroupID     uuid.UUID
+	FolderID    *uuid.UUID
+	EncKey      []byte
+	Nonce       []byte
+	AccessLevel string
+	GrantedBy   uuid.UUID
+}
+
+func (q *Queries) CreateGroupFolderKey(ctx context.Context, arg CreateGroupFolderKeyParams) error {
+	_, err := q.db.Exec(ctx, createGroupFolderKey,
+		arg.GroupID,
+		arg.FolderID,
+		arg.EncKey,
+		arg.Nonce,
+		arg.AccessLevel,
+		arg.GrantedBy,
+	)
+	return err
+}
+
+const createGroupItemKey = `-- name: CreateGroupItemKey :exec
+INSERT INTO group_keys (group_id, item_id, enc_key, nonce, access_level, granted_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (group_id, item_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    access_level = EXCLUDED.access_level,
+    granted_by = EXCLUDED.granted_by
+`
+
+type CreateGroupItemKeyParams struct {
+	GroupID     uuid.UUID
+	ItemID      *uuid.UUID
+	EncKey      []byte
+	Nonce       []byte
+	AccessLevel string
+	GrantedBy   uuid.UUID
+}
+
+func (q *Queries) CreateGroupItemKey(ctx context.Context, arg CreateGroupItemKeyParams) error {
+	_, err := q.db.Exec(ctx, createGroupItemKey,
+		arg.GroupID,
+		arg.ItemID,
+		arg.EncKey,
+		arg.Nonce,
+		arg.AccessLevel,
+		arg.GrantedBy,
+	)
+	return err
+}
+
+const deleteGroup = `-- name: DeleteGroup :execrows
+DELETE FROM groups
+WHERE id = $1 AND org_id = $2
+`
+
+type DeleteGroupParams struct {
+	ID    uuid.UUID
+	OrgID uuid.UUID
+}
+
+func (q *Queries) DeleteGroup(ctx context.Context, arg DeleteGroupParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteGroup, arg.ID, arg.OrgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getGroup = `-- name: GetGroup :one
+SELECT id, org_id, name, created_by, created_at
+FROM groups
+WHERE id = $1
+`
+
+func (q *Queries) GetGroup(ctx context.Context, id uuid.UUID) (Group, error) {
+	row := q.db.QueryRow(ctx, getGroup, id)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGroupMembers = `-- name: GetGroupMembers :many
+SELECT user_id, added_by, created_at
+FROM group_members
+WHERE group_id = $1
+ORDER BY created_at ASC
+`
+
+type GetGroupMembersRow struct {
+	UserID    uuid.UUID
+	AddedBy   uuid.UUID
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]GetGroupMembersRow, error) {
+	rows, err := q.db.Query(ctx, getGroupMembers, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGroupMembersRow
+	for rows.Next() {
+		var i GetGroupMembersRow
+		if err := rows.Scan(&i.UserID, &i.AddedBy, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrgGroups = `-- name: GetOrgGroups :many
+SELECT
+    g.id,
+    g.org_id,
+    g.name,
+    g.created_by,
+    g.created_at,
+    (SELECT COUNT(*) FROM group_members gm WHERE gm.group_id = g.id) AS member_count
+FROM groups g
+WHERE g.org_id = $1
+ORDER BY g.name ASC
+`
+
+type GetOrgGroupsRow struct {
+	ID          uuid.UUID
+	OrgID       uuid.UUID
+	Name        string
+	CreatedBy   uuid.UUID
+	CreatedAt   time.Time
+	MemberCount int64
+}
+
+func (q *Queries) GetOrgGroups(ctx context.Context, orgID uuid.UUID) ([]GetOrgGroupsRow, error) {
+	rows, err := q.db.Query(ctx, getOrgGroups, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrgGroupsRow
+	for rows.Next() {
+		var i GetOrgGroupsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrgID,
+			&i.Name,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.MemberCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeGroupMember = `-- name: RemoveGroupMember :execrows
+DELETE FROM group_members
+WHERE group_id = $1 AND user_id = $2
+`
+
+type RemoveGroupMemberParams struct {
+	GroupID uuid.UUID
+	UserID  uuid.UUID
+}
+
+func (q *Queries) RemoveGroupMember(ctx context.Context, arg RemoveGroupMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeGroupMember, arg.GroupID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const removeUserFromOrgGroups = `-- name: RemoveUserFromOrgGroups :exec
+DELETE FROM group_members gm
+USING groups g
+WHERE gm.group_id = g.id
+  AND g.org_id = $1
+  AND gm.user_id = $2
+`
+
+type RemoveUserFromOrgGroupsParams struct {
+	OrgID  uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) RemoveUserFromOrgGroups(ctx context.Context, arg RemoveUserFromOrgGroupsParams) error {
+	_, err := q.db.Exec(ctx, removeUserFromOrgGroups, arg.OrgID, arg.UserID)
+	return err
+}
+
+const revokeGroupFolderItemKeys = `-- name: RevokeGroupFolderItemKeys :exec
+DELETE FROM group_keys gk
+USING items i
+WHERE gk.item_id = i.id
+  AND gk.group_id = $1
+  AND i.folder_id = $2
+`
+
+type RevokeGroupFolderItemKeysParams struct {
+	GroupID  uuid.UUID
+	FolderID *uuid.UUID
+}
+
+func (q *Queries) RevokeGroupFolderItemKeys(ctx context.Context, arg RevokeGroupFolderItemKeysParams) error {
+	_, err := q.db.Exec(ctx, revokeGroupFolderItemKeys, arg.GroupID, arg.FolderID)
+	return err
+}
+
+const revokeGroupFolderKey = `-- name: RevokeGroupFolderKey :execrows
+DELETE FROM group_keys
+WHERE group_id = $1 AND folder_id = $2
+`
+
+type RevokeGroupFolderKeyParams struct {
+	GroupID  uuid.UUID
+	FolderID *uuid.UUID
+}
+
+func (q *Queries) RevokeGroupFolderKey(ctx context.Context, arg RevokeGroupFolderKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeGroupFolderKey, arg.GroupID, arg.FolderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
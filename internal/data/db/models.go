@@ -10,6 +10,76 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApiToken struct {
+	ID               uuid.UUID
+	ServiceAccountID uuid.UUID
+	Name             string
+	TokenHash        []byte
+	TokenPrefix      string
+	ReadOnly         bool
+	FolderIds        []uuid.UUID
+	ItemIds          []uuid.UUID
+	ExpiresAt        time.Time
+	RevokedAt        *time.Time
+	LastUsedAt       *time.Time
+	CreatedBy        uuid.UUID
+	CreatedAt        time.Time
+}
+
+type AuditCheckpoint struct {
+	Seq       int64
+	Hash      []byte
+	Signature []byte
+	CreatedAt time.Time
+}
+
+type AuditEvent struct {
+	ID           uuid.UUID
+	ActorID      uuid.UUID
+	Action       string
+	ResourceType *string
+	ResourceID   *uuid.UUID
+	OwnerID      *uuid.UUID
+	TargetUserID *uuid.UUID
+	CreatedAt    time.Time
+	OrgID        *uuid.UUID
+	Ip           *string
+	UserAgent    *string
+	Seq          *int64
+	PrevHash     []byte
+	Hash         []byte
+}
+
+type EmergencyContact struct {
+	ID          uuid.UUID
+	GrantorID   uuid.UUID
+	GranteeID   uuid.UUID
+	WaitHours   int32
+	Status      string
+	RequestedAt *time.Time
+	ReleasedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type EmergencyKey struct {
+	ID        uuid.UUID
+	ContactID uuid.UUID
+	FolderID  *uuid.UUID
+	ItemID    *uuid.UUID
+	EncKey    []byte
+	Nonce     []byte
+	CreatedAt time.Time
+}
+
+type Environment struct {
+	ID        uuid.UUID
+	ProjectID uuid.UUID
+	FolderID  uuid.UUID
+	Name      string
+	CreatedAt time.Time
+}
+
 type Folder struct {
 	ID          uuid.UUID
 	OwnerID     uuid.UUID
@@ -18,6 +88,36 @@ type Folder struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	DeletedAt   *time.Time
+	OrgID       *uuid.UUID
+}
+
+type Group struct {
+	ID        uuid.UUID
+	OrgID     uuid.UUID
+	Name      string
+	CreatedBy uuid.UUID
+	CreatedAt time.Time
+}
+
+type GroupKey struct {
+	ID          uuid.UUID
+	GroupID     uuid.UUID
+	FolderID    *uuid.UUID
+	EncKey      []byte
+	Nonce       []byte
+	AccessLevel string
+	GrantedBy   uuid.UUID
+	CreatedAt   time.Time
+	ItemID      *uuid.UUID
+}
+
+type GroupMember struct {
+	GroupID   uuid.UUID
+	UserID    uuid.UUID
+	EncKey    []byte
+	Nonce     []byte
+	AddedBy   uuid.UUID
+	CreatedAt time.Time
 }
 
 type Item struct {
@@ -32,6 +132,7 @@ type Item struct {
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	DeletedAt     *time.Time
+	OrgID         *uuid.UUID
 }
 
 type Key struct {
@@ -43,4 +144,117 @@ type Key struct {
 	Nonce       []byte
 	AccessLevel string
 	CreatedAt   time.Time
+	GrantedBy   *uuid.UUID
+	ExpiresAt   *time.Time
+}
+
+type OrgMember struct {
+	OrgID     uuid.UUID
+	UserID    uuid.UUID
+	Role      string
+	CreatedAt time.Time
+}
+
+type OrgRecoveryPolicy struct {
+	OrgID     uuid.UUID
+	PublicKey []byte
+	UpdatedBy uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Organization struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedBy uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Project struct {
+	ID        uuid.UUID
+	OwnerID   uuid.UUID
+	OrgID     *uuid.UUID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type RateLimitBucket struct {
+	Key       string
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+type RecoveryEnrollment struct {
+	OrgID          uuid.UUID
+	UserID         uuid.UUID
+	WrappedKeyring []byte
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type RecoveryRequest struct {
+	ID               uuid.UUID
+	OrgID            uuid.UUID
+	UserID           uuid.UUID
+	DevicePublicKey  []byte
+	RecoveredKeyring []byte
+	Status           string
+	InitiatedBy      uuid.UUID
+	ApprovedBy       *uuid.UUID
+	CreatedAt        time.Time
+	ApprovedAt       *time.Time
+	CompletedAt      *time.Time
+}
+
+type RevokedToken struct {
+	Jti       string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	RevokedBy *uuid.UUID
+	RevokedAt time.Time
+}
+
+type ServiceAccount struct {
+	ID        uuid.UUID
+	UserID    *uuid.UUID
+	OrgID     *uuid.UUID
+	Name      string
+	PublicKey []byte
+	CreatedBy uuid.UUID
+	CreatedAt time.Time
+}
+
+type SessionRevocation struct {
+	UserID        uuid.UUID
+	RevokedBefore time.Time
+	RevokedBy     *uuid.UUID
+	UpdatedAt     time.Time
+}
+
+type Webhook struct {
+	ID        uuid.UUID
+	UserID    *uuid.UUID
+	OrgID     *uuid.UUID
+	Url       string
+	Secret    string
+	Events    []string
+	CreatedBy uuid.UUID
+	CreatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	WebhookID      uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastStatusCode *int32
+	LastError      *string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
 }
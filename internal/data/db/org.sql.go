@@ -0,0 +1,289 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: org.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const addOrgMember = `-- name: AddOrgMember :execrows
+INSERT INTO org_members (org_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (org_id, user_id) DO NOTHING
+`
+
+type AddOrgMemberParams struct {
+	OrgID  uuid.UUID
+	UserID uuid.UUID
+	Role   string
+}
+
+func (q *Queries) AddOrgMember(ctx context.Context, arg AddOrgMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addOrgMember, arg.OrgID, arg.UserID, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countOrgResources = `-- name: CountOrgResources :one
+SELECT
+    (SELECT COUNT(*) FROM folders f WHERE f.org_id = $1 AND f.deleted_at IS NULL) +
+    (SELECT COUNT(*) FROM items i WHERE i.org_id = $1 AND i.deleted_at IS NULL) AS total
+`
+
+func (q *Queries) CountOrgResources(ctx context.Context, orgID *uuid.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, countOrgResources, orgID)
+	var total int32
+	err := row.Scan(&total)
+	return total, err
+}
+
+const createOrganization = `-- name: CreateOrganization :one
+INSERT INTO organizations (name, created_by)
+VALUES ($1, $2)
+RETURNING id, name, created_by, created_at, updated_at
+`
+
+type CreateOrganizationParams struct {
+	Name      string
+	CreatedBy uuid.UUID
+}
+
+func (q *Queries) CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error) {
+	row := q.db.QueryRow(ctx, createOrganization, arg.Name, arg.CreatedBy)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteOrganization = `-- name: DeleteOrganization :execrows
+DELETE FROM organizations
+WHERE id = $1
+`
+
+func (q *Queries) DeleteOrganization(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOrganization, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getOrgMemberRole = `-- name: GetOrgMemberRole :one
+SELECT role
+FROM org_members
+WHERE org_id = $1 AND user_id = $2
+`
+
+type GetOrgMemberRoleParams struct {
+	OrgID  uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetOrgMemberRole(ctx context.Context, arg GetOrgMemberRoleParams) (string, error) {
+	row := q.db.QueryRow(ctx, getOrgMemberRole, arg.OrgID, arg.UserID)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getOrgMembers = `-- name: GetOrgMembers :many
+SELECT user_id, role, created_at
+FROM org_members
+WHERE org_id = $1
+ORDER BY created_at ASC
+`
+
+type GetOrgMembersRow struct {
+	UserID    uuid.UUID
+	Role      string
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetOrgMembers(ctx context.Context, orgID uuid.UUID) ([]GetOrgMembersRow, error) {
+	rows, err := q.db.Query(ctx, getOrgMembers, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrgMembersRow
+	for rows.Next() {
+		var i GetOrgMembersRow
+		if err := rows.Scan(&i.UserID, &i.Role, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrganization = `-- name: GetOrganization :one
+SELECT id, name, created_by, created_at, updated_at
+FROM organizations
+WHERE id = $1
+`
+
+func (q *Queries) GetOrganization(ctx context.Context, id uuid.UUID) (Organization, error) {
+	row := q.db.QueryRow(ctx, getOrganization, id)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserOrganizations = `-- name: GetUserOrganizations :many
+SELECT
+    o.id,
+    o.name,
+    o.created_by,
+    o.created_at,
+    o.updated_at,
+    m.role
+FROM organizations o
+JOIN org_members m ON o.id = m.org_id
+WHERE m.user_id = $1
+ORDER BY o.name ASC
+`
+
+type GetUserOrganizationsRow struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedBy uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Role      string
+}
+
+func (q *Queries) GetUserOrganizations(ctx context.Context, userID uuid.UUID) ([]GetUserOrganizationsRow, error) {
+	rows, err := q.db.Query(ctx, getUserOrganizations, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserOrganizationsRow
+	for rows.Next() {
+		var i GetUserOrganizationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockOrgOwners = `-- name: LockOrgOwners :many
+SELECT user_id
+FROM org_members
+WHERE org_id = $1 AND role = 'OWNER'
+FOR UPDATE
+`
+
+func (q *Queries) LockOrgOwners(ctx context.Context, orgID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, lockOrgOwners, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeOrgMember = `-- name: RemoveOrgMember :execrows
+DELETE FROM org_members
+WHERE org_id = $1 AND user_id = $2
+`
+
+type RemoveOrgMemberParams struct {
+	OrgID  uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) RemoveOrgMember(ctx context.Context, arg RemoveOrgMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeOrgMember, arg.OrgID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateOrgMemberRole = `-- name: UpdateOrgMemberRole :execrows
+UPDATE org_members
+SET role = $1
+WHERE org_id = $2 AND user_id = $3
+`
+
+type UpdateOrgMemberRoleParams struct {
+	Role   string
+	OrgID  uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) UpdateOrgMemberRole(ctx context.Context, arg UpdateOrgMemberRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateOrgMemberRole, arg.Role, arg.OrgID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateOrganization = `-- name: UpdateOrganization :execrows
+UPDATE organizations
+SET
+    name = $1,
+    updated_at = NOW()
+WHERE id = $2
+`
+
+type UpdateOrganizationParams struct {
+	Name string
+	ID   uuid.UUID
+}
+
+func (q *Queries) UpdateOrganization(ctx context.Context, arg UpdateOrganizationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateOrganization, arg.Name, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,455 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: project.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countProjectEnvironments = `-- name: CountProjectEnvironments :one
+SELECT COUNT(*) FROM environments
+WHERE project_id = $1
+`
+
+func (q *Queries) CountProjectEnvironments(ctx context.Context, projectID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countProjectEnvironments, projectID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createEnvironment = `-- name: CreateEnvironment :one
+INSERT INTO environments (project_id, folder_id, name)
+VALUES ($1, $2, $3)
+ON CONFLICT (project_id, name) DO NOTHING
+RETURNING id, project_id, folder_id, name, created_at
+`
+
+type CreateEnvironmentParams struct {
+	ProjectID uuid.UUID
+	FolderID  uuid.UUID
+	Name      string
+}
+
+func (q *Queries) CreateEnvironment(ctx context.Context, arg CreateEnvironmentParams) (Environment, error) {
+	row := q.db.QueryRow(ctx, createEnvironment, arg.ProjectID, arg.FolderID, arg.Name)
+	var i Environment
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.FolderID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createProject = `-- name: CreateProject :one
+INSERT INTO projects (owner_id, org_id, name)
+VALUES ($1, $2, $3)
+RETURNING id, owner_id, org_id, name, created_at, updated_at
+`
+
+type CreateProjectParams struct {
+	OwnerID uuid.UUID
+	OrgID   *uuid.UUID
+	Name    string
+}
+
+func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error) {
+	row := q.db.QueryRow(ctx, createProject, arg.OwnerID, arg.OrgID, arg.Name)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.OrgID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteEnvironment = `-- name: DeleteEnvironment :exec
+DELETE FROM environments
+WHERE id = $1 AND project_id = $2
+`
+
+type DeleteEnvironmentParams struct {
+	ID        uuid.UUID
+	ProjectID uuid.UUID
+}
+
+func (q *Queries) DeleteEnvironment(ctx context.Context, arg DeleteEnvironmentParams) error {
+	_, err := q.db.Exec(ctx, deleteEnvironment, arg.ID, arg.ProjectID)
+	return err
+}
+
+const deleteProject = `-- name: DeleteProject :exec
+DELETE FROM projects
+WHERE id = $1
+`
+
+func (q *Queries) DeleteProject(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteProject, id)
+	return err
+}
+
+const getEnvironment = `-- name: GetEnvironment :one
+SELECT id, project_id, folder_id, name, created_at
+FROM environments
+WHERE id = $1 AND project_id = $2
+`
+
+type GetEnvironmentParams struct {
+	ID        uuid.UUID
+	ProjectID uuid.UUID
+}
+
+func (q *Queries) GetEnvironment(ctx context.Context, arg GetEnvironmentParams) (Environment, error) {
+	row := q.db.QueryRow(ctx, getEnvironment, arg.ID, arg.ProjectID)
+	var i Environment
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.FolderID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getEnvironmentByName = `-- name: GetEnvironmentByName :one
+SELECT e.id, e.project_id, e.folder_id, e.name, e.created_at
+FROM environments e
+JOIN folders f ON f.id = e.folder_id
+WHERE e.project_id = $1
+  AND e.name = $2
+  AND f.deleted_at IS NULL
+`
+
+type GetEnvironmentByNameParams struct {
+	ProjectID uuid.UUID
+	Name      string
+}
+
+func (q *Queries) GetEnvironmentByName(ctx context.Context, arg GetEnvironmentByNameParams) (Environment, error) {
+	row := q.db.QueryRow(ctx, getEnvironmentByName, arg.ProjectID, arg.Name)
+	var i Environment
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.FolderID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFolderSecrets = `-- name: GetFolderSecrets :many
+SELECT
+    i.id,
+    i.owner_id,
+    i.enc_overview,
+    i.overview_nonce,
+    i.enc_data,
+    i.nonce AS data_nonce,
+    i.updated_at,
+    k.enc_key AS wrapped_key,
+    k.nonce AS key_nonce
+FROM items i
+JOIN keys k ON i.id = k.item_id
+WHERE i.folder_id = $1
+  AND k.user_id = $2
+  AND i.type = 'SECRET'
+  AND i.deleted_at IS NULL
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+ORDER BY i.created_at ASC
+`
+
+type GetFolderSecretsParams struct {
+	FolderID *uuid.UUID
+	UserID   uuid.UUID
+}
+
+type GetFolderSecretsRow struct {
+	ID            uuid.UUID
+	OwnerID       uuid.UUID
+	EncOverview   []byte
+	OverviewNonce []byte
+	EncData       []byte
+	DataNonce     []byte
+	UpdatedAt     time.Time
+	WrappedKey    []byte
+	KeyNonce      []byte
+}
+
+func (q *Queries) GetFolderSecrets(ctx context.Context, arg GetFolderSecretsParams) ([]GetFolderSecretsRow, error) {
+	rows, err := q.db.Query(ctx, getFolderSecrets, arg.FolderID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFolderSecretsRow
+	for rows.Next() {
+		var i GetFolderSecretsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.EncOverview,
+			&i.OverviewNonce,
+			&i.EncData,
+			&i.DataNonce,
+			&i.UpdatedAt,
+			&i.WrappedKey,
+			&i.KeyNonce,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOwnedProject = `-- name: GetOwnedProject :one
+SELECT id, owner_id, org_id, name, created_at, updated_at
+FROM projects
+WHERE id = $1
+  AND (owner_id = $2 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = projects.org_id
+      AND m.user_id = $2
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+`
+
+type GetOwnedProjectParams struct {
+	ID      uuid.UUID
+	OwnerID uuid.UUID
+}
+
+// Admins of the owning organization count as owners of org projects
+func (q *Queries) GetOwnedProject(ctx context.Context, arg GetOwnedProjectParams) (Project, error) {
+	row := q.db.QueryRow(ctx, getOwnedProject, arg.ID, arg.OwnerID)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.OrgID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectEnvironments = `-- name: GetProjectEnvironments :many
+SELECT
+    e.id,
+    e.project_id,
+    e.folder_id,
+    e.name,
+    e.created_at,
+    k.access_level
+FROM environments e
+JOIN folders f ON f.id = e.folder_id
+LEFT JOIN keys k ON k.folder_id = e.folder_id
+    AND k.user_id = $2
+    AND (k.expires_at IS NULL OR k.expires_at > NOW())
+WHERE e.project_id = $1
+  AND f.deleted_at IS NULL
+ORDER BY e.name ASC
+`
+
+type GetProjectEnvironmentsParams struct {
+	ProjectID uuid.UUID
+	UserID    uuid.UUID
+}
+
+type GetProjectEnvironmentsRow struct {
+	ID          uuid.UUID
+	ProjectID   uuid.UUID
+	FolderID    uuid.UUID
+	Name        string
+	CreatedAt   time.Time
+	AccessLevel *string
+}
+
+// access_level is NULL for environments the caller holds no key to
+func (q *Queries) GetProjectEnvironments(ctx context.Context, arg GetProjectEnvironmentsParams) ([]GetProjectEnvironmentsRow, error) {
+	rows, err := q.db.Query(ctx, getProjectEnvironments, arg.ProjectID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetProjectEnvironmentsRow
+	for rows.Next() {
+		var i GetProjectEnvironmentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.FolderID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.AccessLevel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVisibleProject = `-- name: GetVisibleProject :one
+SELECT
+    p.id,
+    p.owner_id,
+    p.org_id,
+    p.name,
+    p.created_at,
+    p.updated_at,
+    ARRAY(
+        SELECT e.folder_id FROM environments e WHERE e.project_id = p.id
+    )::uuid[] AS folder_ids
+FROM projects p
+WHERE p.id = $1
+  AND (p.owner_id = $2
+   OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = p.org_id
+      AND m.user_id = $2
+  )
+   OR EXISTS (
+    SELECT 1 FROM environments e
+    JOIN folders f ON f.id = e.folder_id
+    JOIN keys k ON k.folder_id = f.id
+    WHERE e.project_id = p.id
+      AND k.user_id = $2
+      AND f.deleted_at IS NULL
+      AND (k.expires_at IS NULL OR k.expires_at > NOW())
+  ))
+`
+
+type GetVisibleProjectParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+type GetVisibleProjectRow struct {
+	ID        uuid.UUID
+	OwnerID   uuid.UUID
+	OrgID     *uuid.UUID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	FolderIds []uuid.UUID
+}
+
+func (q *Queries) GetVisibleProject(ctx context.Context, arg GetVisibleProjectParams) (GetVisibleProjectRow, error) {
+	row := q.db.QueryRow(ctx, getVisibleProject, arg.ID, arg.UserID)
+	var i GetVisibleProjectRow
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.OrgID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FolderIds,
+	)
+	return i, err
+}
+
+const getVisibleProjects = `-- name: GetVisibleProjects :many
+SELECT
+    p.id,
+    p.owner_id,
+    p.org_id,
+    p.name,
+    p.created_at,
+    p.updated_at,
+    ARRAY(
+        SELECT e.folder_id FROM environments e WHERE e.project_id = p.id
+    )::uuid[] AS folder_ids
+FROM projects p
+WHERE p.owner_id = $1
+   OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = p.org_id
+      AND m.user_id = $1
+  )
+   OR EXISTS (
+    SELECT 1 FROM environments e
+    JOIN folders f ON f.id = e.folder_id
+    JOIN keys k ON k.folder_id = f.id
+    WHERE e.project_id = p.id
+      AND k.user_id = $1
+      AND f.deleted_at IS NULL
+      AND (k.expires_at IS NULL OR k.expires_at > NOW())
+  )
+ORDER BY p.name ASC
+`
+
+type GetVisibleProjectsRow struct {
+	ID        uuid.UUID
+	OwnerID   uuid.UUID
+	OrgID     *uuid.UUID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	FolderIds []uuid.UUID
+}
+
+// Owners, members of the owning organization and anyone holding a key to one
+// of the environments see a project
+func (q *Queries) GetVisibleProjects(ctx context.Context, userID uuid.UUID) ([]GetVisibleProjectsRow, error) {
+	rows, err := q.db.Query(ctx, getVisibleProjects, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetVisibleProjectsRow
+	for rows.Next() {
+		var i GetVisibleProjectsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.OrgID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FolderIds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteEnvironmentFolder = `-- name: SoftDeleteEnvironmentFolder :exec
+UPDATE folders
+SET deleted_at = NOW()
+WHERE id = $1
+`
+
+// Ownership is checked on the project, environment folders are removed with it
+func (q *Queries) SoftDeleteEnvironmentFolder(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, softDeleteEnvironmentFolder, id)
+	return err
+}
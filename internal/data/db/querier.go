@@ -6,25 +6,194 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddGroupMember(ctx context.Context, arg AddGroupMemberParams) error
+	AddOrgMember(ctx context.Context, arg AddOrgMemberParams) (int64, error)
+	ApproveRecoveryRequest(ctx context.Context, arg ApproveRecoveryRequestParams) error
+	CancelOpenRecoveryRequests(ctx context.Context, orgID uuid.UUID) error
+	CancelRecoveryRequest(ctx context.Context, id uuid.UUID) error
+	// Leases due deliveries by pushing next_attempt_at forward, so a crashed
+	// worker's deliveries are retried once the lease runs out
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error)
+	CompleteRecoveryRequest(ctx context.Context, arg CompleteRecoveryRequestParams) error
+	CountOrgResources(ctx context.Context, orgID *uuid.UUID) (int32, error)
+	CountProjectEnvironments(ctx context.Context, projectID uuid.UUID) (int64, error)
+	// Events written before the first chained one
+	CountUnchainedAuditEvents(ctx context.Context) (int64, error)
+	CountVaultTotals(ctx context.Context) (CountVaultTotalsRow, error)
+	CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (CreateAPITokenRow, error)
+	CreateAuditCheckpoint(ctx context.Context, arg CreateAuditCheckpointParams) error
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error
+	CreateEmergencyContact(ctx context.Context, arg CreateEmergencyContactParams) (EmergencyContact, error)
+	CreateEnvironment(ctx context.Context, arg CreateEnvironmentParams) (Environment, error)
 	CreateFolder(ctx context.Context, arg CreateFolderParams) (CreateFolderRow, error)
 	CreateFolderKey(ctx context.Context, arg CreateFolderKeyParams) error
+	CreateGroup(ctx context.Context, arg CreateGroupParams) (Group, error)
+	CreateGroupFolderKey(ctx context.Context, arg CreateGroupFolderKeyParams) error
+	CreateGroupItemKey(ctx context.Context, arg CreateGroupItemKeyParams) error
 	CreateItem(ctx context.Context, arg CreateItemParams) (CreateItemRow, error)
 	CreateItemKey(ctx context.Context, arg CreateItemKeyParams) error
+	CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error)
+	CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error)
+	CreateRecoveryRequest(ctx context.Context, arg CreateRecoveryRequestParams) (RecoveryRequest, error)
+	CreateServiceAccount(ctx context.Context, arg CreateServiceAccountParams) (ServiceAccount, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	DeleteEmergencyContact(ctx context.Context, arg DeleteEmergencyContactParams) (int64, error)
+	DeleteEnvironment(ctx context.Context, arg DeleteEnvironmentParams) error
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	DeleteGroup(ctx context.Context, arg DeleteGroupParams) (int64, error)
+	DeleteIdleRateLimitBuckets(ctx context.Context, idleBefore time.Time) (int64, error)
+	// Enrollments are wrapped with the policy key, so they go away with it
+	DeleteOrgRecoveryEnrollments(ctx context.Context, orgID uuid.UUID) error
+	DeleteOrganization(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteProject(ctx context.Context, id uuid.UUID) error
+	DeleteRecoveryEnrollment(ctx context.Context, arg DeleteRecoveryEnrollmentParams) (int64, error)
+	DeleteRecoveryPolicy(ctx context.Context, orgID uuid.UUID) (int64, error)
+	DeleteServiceAccount(ctx context.Context, id uuid.UUID) error
+	// Owner keys stay, the user keeps what they own
+	DeleteUserGrants(ctx context.Context, userID uuid.UUID) ([]DeleteUserGrantsRow, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	// User webhooks fire for events on resources they own or that target them,
+	// org webhooks for every event in the organization
+	EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) error
+	FsckEnvironmentsWithDeletedFolder(ctx context.Context) ([]uuid.UUID, error)
+	FsckExpiredGrants(ctx context.Context) ([]uuid.UUID, error)
+	FsckFoldersWithoutOwnerKey(ctx context.Context) ([]uuid.UUID, error)
+	FsckItemsInDeletedFolders(ctx context.Context) ([]uuid.UUID, error)
+	FsckItemsWithoutOwnerKey(ctx context.Context) ([]uuid.UUID, error)
+	GetActiveAPIToken(ctx context.Context, tokenHash []byte) (GetActiveAPITokenRow, error)
+	GetAuditChainHead(ctx context.Context) (GetAuditChainHeadRow, error)
+	GetEmergencyContactForUpdate(ctx context.Context, id uuid.UUID) (EmergencyContact, error)
+	GetEmergencyContactsByGrantee(ctx context.Context, granteeID uuid.UUID) ([]GetEmergencyContactsByGranteeRow, error)
+	GetEmergencyContactsByGrantor(ctx context.Context, grantorID uuid.UUID) ([]GetEmergencyContactsByGrantorRow, error)
+	GetEnvironment(ctx context.Context, arg GetEnvironmentParams) (Environment, error)
+	GetEnvironmentByName(ctx context.Context, arg GetEnvironmentByNameParams) (Environment, error)
+	// Direct grants take precedence, group grants fill in items the user has no key for.
+	// is_owner follows IsItemOwner, so org admins own org items
 	GetFolderItems(ctx context.Context, arg GetFolderItemsParams) ([]GetFolderItemsRow, error)
+	GetFolderOwner(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	GetFolderSecrets(ctx context.Context, arg GetFolderSecretsParams) ([]GetFolderSecretsRow, error)
+	// Ownership follows IsFolderOwner, so org resources the user administers are not listed
+	// Direct grants take precedence, group grants fill in folders the user has no key for
+	GetFoldersSharedWithUser(ctx context.Context, userID uuid.UUID) ([]GetFoldersSharedWithUserRow, error)
+	GetGrantAccessLevel(ctx context.Context, arg GetGrantAccessLevelParams) (string, error)
+	GetGroup(ctx context.Context, id uuid.UUID) (Group, error)
+	GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]GetGroupMembersRow, error)
+	// A direct grant takes precedence. Group grants are READ or WRITE, so the
+	// descending order picks WRITE when several groups grant the item
 	GetItemData(ctx context.Context, arg GetItemDataParams) (GetItemDataRow, error)
-	GetUserFolders(ctx context.Context, userID uuid.UUID) ([]GetUserFoldersRow, error)
+	GetItemFolderID(ctx context.Context, id uuid.UUID) (*uuid.UUID, error)
+	GetItemOwner(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	// Ownership follows IsItemOwner, so org resources the user administers are not listed
+	// Direct grants take precedence, group grants fill in items the user has no key for
+	GetItemsSharedWithUser(ctx context.Context, userID uuid.UUID) ([]GetItemsSharedWithUserRow, error)
+	GetLatestAuditCheckpoint(ctx context.Context) (AuditCheckpoint, error)
+	GetOrgGroups(ctx context.Context, orgID uuid.UUID) ([]GetOrgGroupsRow, error)
+	GetOrgMemberRole(ctx context.Context, arg GetOrgMemberRoleParams) (string, error)
+	GetOrgMembers(ctx context.Context, orgID uuid.UUID) ([]GetOrgMembersRow, error)
+	GetOrgRecoveryEnrollments(ctx context.Context, orgID uuid.UUID) ([]GetOrgRecoveryEnrollmentsRow, error)
+	GetOrgRecoveryRequests(ctx context.Context, orgID uuid.UUID) ([]RecoveryRequest, error)
+	GetOrgServiceAccounts(ctx context.Context, orgID *uuid.UUID) ([]ServiceAccount, error)
+	GetOrgWebhooks(ctx context.Context, orgID *uuid.UUID) ([]Webhook, error)
+	GetOrganization(ctx context.Context, id uuid.UUID) (Organization, error)
+	// Admins of the owning organization count as owners of org projects
+	GetOwnedProject(ctx context.Context, arg GetOwnedProjectParams) (Project, error)
+	// access_level is NULL for environments the caller holds no key to
+	GetProjectEnvironments(ctx context.Context, arg GetProjectEnvironmentsParams) ([]GetProjectEnvironmentsRow, error)
+	GetRateLimitTokens(ctx context.Context, arg GetRateLimitTokensParams) (float64, error)
+	GetRecoveryEnrollment(ctx context.Context, arg GetRecoveryEnrollmentParams) (RecoveryEnrollment, error)
+	GetRecoveryPolicy(ctx context.Context, orgID uuid.UUID) (OrgRecoveryPolicy, error)
+	GetRecoveryRequest(ctx context.Context, arg GetRecoveryRequestParams) (RecoveryRequest, error)
+	GetRecoveryRequestForUpdate(ctx context.Context, arg GetRecoveryRequestForUpdateParams) (RecoveryRequest, error)
+	GetResourceEvents(ctx context.Context, resourceID *uuid.UUID) ([]GetResourceEventsRow, error)
+	// Group grants are listed once per member, with the group they come through
+	GetResourceGrants(ctx context.Context, resourceID *uuid.UUID) ([]GetResourceGrantsRow, error)
+	GetResourceOrgID(ctx context.Context, resourceID uuid.UUID) (*uuid.UUID, error)
+	GetServiceAccount(ctx context.Context, id uuid.UUID) (ServiceAccount, error)
+	GetServiceAccountTokens(ctx context.Context, serviceAccountID uuid.UUID) ([]GetServiceAccountTokensRow, error)
+	GetSessionsRevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, error)
+	// Ownership follows IsFolderOwner and IsItemOwner, so org admins see the org
+	// resources they manage
+	GetSharedByUser(ctx context.Context, ownerID uuid.UUID) ([]GetSharedByUserRow, error)
+	// Direct grants take precedence, group grants fill in folders the user has no key for.
+	// is_owner follows IsFolderOwner, so org admins own org folders
+	GetUserFolders(ctx context.Context, ownerID uuid.UUID) ([]GetUserFoldersRow, error)
+	GetUserItemsExport(ctx context.Context, userID uuid.UUID) ([]GetUserItemsExportRow, error)
+	GetUserOrganizations(ctx context.Context, userID uuid.UUID) ([]GetUserOrganizationsRow, error)
+	GetUserServiceAccounts(ctx context.Context, userID *uuid.UUID) ([]ServiceAccount, error)
+	GetUserWebhooks(ctx context.Context, userID *uuid.UUID) ([]Webhook, error)
+	// Queries for the vaultctl admin tool
+	GetVaultStats(ctx context.Context) (GetVaultStatsRow, error)
+	GetVisibleProject(ctx context.Context, arg GetVisibleProjectParams) (GetVisibleProjectRow, error)
+	// Owners, members of the owning organization and anyone holding a key to one
+	// of the environments see a project
+	GetVisibleProjects(ctx context.Context, userID uuid.UUID) ([]GetVisibleProjectsRow, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (Webhook, error)
+	GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	// Admins of the owning organization count as owners of org resources
 	IsFolderOwner(ctx context.Context, arg IsFolderOwnerParams) (int32, error)
+	// Admins of the owning organization count as owners of org resources
 	IsItemOwner(ctx context.Context, arg IsItemOwnerParams) (int32, error)
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	ListAuditCheckpoints(ctx context.Context) ([]AuditCheckpoint, error)
+	// Events on resources the user owns, plus everything in organizations they administer
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]ListAuditEventsRow, error)
+	ListChainedAuditEvents(ctx context.Context, arg ListChainedAuditEventsParams) ([]ListChainedAuditEventsRow, error)
+	ListDueEmergencyRequests(ctx context.Context) ([]uuid.UUID, error)
+	// Unchained events written at or after the first chained one
+	ListLateUnchainedAuditEvents(ctx context.Context) ([]uuid.UUID, error)
+	// Serializes writers so every event links to the one committed before it
+	LockAuditChain(ctx context.Context) error
+	LockOrgOwners(ctx context.Context, orgID uuid.UUID) ([]uuid.UUID, error)
+	LockResourceOwners(ctx context.Context, resourceID *uuid.UUID) ([]uuid.UUID, error)
+	MarkWebhookDelivered(ctx context.Context, arg MarkWebhookDeliveredParams) error
+	MarkWebhookFailed(ctx context.Context, arg MarkWebhookFailedParams) error
+	// Folders still holding items are kept until those are purged too
+	PurgeDeletedFolders(ctx context.Context, deletedAt *time.Time) (int64, error)
+	PurgeDeletedItems(ctx context.Context, deletedAt *time.Time) (int64, error)
+	// Copy the pre-wrapped folder keys into the regular access model as READ
+	// grants, refreshing any grant the contact already holds
+	ReleaseEmergencyFolderKeys(ctx context.Context, id uuid.UUID) ([]*uuid.UUID, error)
+	// Same as ReleaseEmergencyFolderKeys for item keys
+	ReleaseEmergencyItemKeys(ctx context.Context, id uuid.UUID) ([]*uuid.UUID, error)
+	RemoveGroupMember(ctx context.Context, arg RemoveGroupMemberParams) (int64, error)
+	RemoveOrgMember(ctx context.Context, arg RemoveOrgMemberParams) (int64, error)
+	RemoveUserFromAllGroups(ctx context.Context, userID uuid.UUID) (int64, error)
+	RemoveUserFromOrgGroups(ctx context.Context, arg RemoveUserFromOrgGroupsParams) error
+	ReplayWebhookDelivery(ctx context.Context, arg ReplayWebhookDeliveryParams) (int64, error)
+	RevokeAPIToken(ctx context.Context, arg RevokeAPITokenParams) (int64, error)
+	RevokeGroupFolderItemKeys(ctx context.Context, arg RevokeGroupFolderItemKeysParams) error
+	RevokeGroupFolderKey(ctx context.Context, arg RevokeGroupFolderKeyParams) (int64, error)
+	// Never moves the marker back, an older revocation must not revive tokens
+	RevokeSessions(ctx context.Context, arg RevokeSessionsParams) error
+	RevokeToken(ctx context.Context, arg RevokeTokenParams) error
 	RevokeUserAccess(ctx context.Context, arg RevokeUserAccessParams) error
+	SetEmergencyContactStatus(ctx context.Context, arg SetEmergencyContactStatusParams) error
+	// Ownership is checked on the project, environment folders are removed with it
+	SoftDeleteEnvironmentFolder(ctx context.Context, id uuid.UUID) error
 	SoftDeleteFolder(ctx context.Context, arg SoftDeleteFolderParams) (int64, error)
 	SoftDeleteItem(ctx context.Context, arg SoftDeleteItemParams) (int64, error)
+	// Refills the bucket for the time since its last update and takes one token.
+	// Returns no row when the bucket has less than one token, leaving it as is
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (float64, error)
+	// Only written once a minute to keep authentication cheap
+	TouchAPIToken(ctx context.Context, id uuid.UUID) error
+	TransferFolderOwnership(ctx context.Context, arg TransferFolderOwnershipParams) (int64, error)
+	TransferItemOwnership(ctx context.Context, arg TransferItemOwnershipParams) (int64, error)
 	UpdateFolderMetadata(ctx context.Context, arg UpdateFolderMetadataParams) (int64, error)
+	UpdateGrantAccessLevel(ctx context.Context, arg UpdateGrantAccessLevelParams) (int64, error)
 	UpdateItemBlob(ctx context.Context, arg UpdateItemBlobParams) error
+	UpdateOrgMemberRole(ctx context.Context, arg UpdateOrgMemberRoleParams) (int64, error)
+	UpdateOrganization(ctx context.Context, arg UpdateOrganizationParams) (int64, error)
+	UpsertEmergencyFolderKey(ctx context.Context, arg UpsertEmergencyFolderKeyParams) error
+	UpsertEmergencyItemKey(ctx context.Context, arg UpsertEmergencyItemKeyParams) error
+	UpsertRecoveryEnrollment(ctx context.Context, arg UpsertRecoveryEnrollmentParams) error
+	UpsertRecoveryPolicy(ctx context.Context, arg UpsertRecoveryPolicyParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -12,9 +12,29 @@ import (
 	"github.com/google/uuid"
 )
 
+const countVaultTotals = `-- name: CountVaultTotals :one
+SELECT
+    (SELECT COUNT(*) FROM folders)::bigint AS folders,
+    (SELECT COUNT(*) FROM items)::bigint AS items,
+    (SELECT COUNT(*) FROM organizations)::bigint AS organizations
+`
+
+type CountVaultTotalsRow struct {
+	Folders       int64
+	Items         int64
+	Organizations int64
+}
+
+func (q *Queries) CountVaultTotals(ctx context.Context) (CountVaultTotalsRow, error) {
+	row := q.db.QueryRow(ctx, countVaultTotals)
+	var i CountVaultTotalsRow
+	err := row.Scan(&i.Folders, &i.Items, &i.Organizations)
+	return i, err
+}
+
 const createFolder = `-- name: CreateFolder :one
-INSERT INTO folders (owner_id, nonce, enc_metadata)
-VALUES ($1, $2, $3)
+INSERT INTO folders (owner_id, nonce, enc_metadata, org_id)
+VALUES ($1, $2, $3, $4)
 RETURNING id, created_at, updated_at
 `
 
@@ -22,6 +42,7 @@ type CreateFolderParams struct {
 	OwnerID     uuid.UUID
 	Nonce       []byte
 	EncMetadata []byte
+	OrgID       *uuid.UUID
 }
 
 type CreateFolderRow struct {
@@ -31,15 +52,27 @@ type CreateFolderRow struct {
 }
 
 func (q *Queries) CreateFolder(ctx context.Context, arg CreateFolderParams) (CreateFolderRow, error) {
-	row := q.db.QueryRow(ctx, createFolder, arg.OwnerID, arg.Nonce, arg.EncMetadata)
+	row := q.db.QueryRow(ctx, createFolder,
+		arg.OwnerID,
+		arg.Nonce,
+		arg.EncMetadata,
+		arg.OrgID,
+	)
 	var i CreateFolderRow
 	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
 	return i, err
 }
 
 const createFolderKey = `-- name: CreateFolderKey :exec
-INSERT INTO keys (user_id, folder_id, enc_key, nonce, access_level)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO keys (user_id, folder_id, enc_key, nonce, access_level, granted_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, folder_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    access_level = EXCLUDED.access_level,
+    granted_by = EXCLUDED.granted_by,
+    expires_at = EXCLUDED.expires_at
 `
 
 type CreateFolderKeyParams struct {
@@ -48,6 +81,8 @@ type CreateFolderKeyParams struct {
 	EncKey      []byte
 	Nonce       []byte
 	AccessLevel string
+	GrantedBy   *uuid.UUID
+	ExpiresAt   *time.Time
 }
 
 func (q *Queries) CreateFolderKey(ctx context.Context, arg CreateFolderKeyParams) error {
@@ -57,13 +92,15 @@ func (q *Queries) CreateFolderKey(ctx context.Context, arg CreateFolderKeyParams
 		arg.EncKey,
 		arg.Nonce,
 		arg.AccessLevel,
+		arg.GrantedBy,
+		arg.ExpiresAt,
 	)
 	return err
 }
 
 const createItem = `-- name: CreateItem :one
-INSERT INTO items (owner_id, folder_id, type, nonce, enc_data, overview_nonce, enc_overview)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO items (owner_id, folder_id, type, nonce, enc_data, overview_nonce, enc_overview, org_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING id, created_at, updated_at
 `
 
@@ -75,6 +112,7 @@ type CreateItemParams struct {
 	EncData       []byte
 	OverviewNonce []byte
 	EncOverview   []byte
+	OrgID         *uuid.UUID
 }
 
 type CreateItemRow struct {
@@ -92,6 +130,7 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (CreateI
 		arg.EncData,
 		arg.OverviewNonce,
 		arg.EncOverview,
+		arg.OrgID,
 	)
 	var i CreateItemRow
 	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
@@ -99,8 +138,15 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (CreateI
 }
 
 const createItemKey = `-- name: CreateItemKey :exec
-INSERT INTO keys (user_id, item_id, enc_key, nonce, access_level)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO keys (user_id, item_id, enc_key, nonce, access_level, granted_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, item_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    access_level = EXCLUDED.access_level,
+    granted_by = EXCLUDED.granted_by,
+    expires_at = EXCLUDED.expires_at
 `
 
 type CreateItemKeyParams struct {
@@ -109,6 +155,8 @@ type CreateItemKeyParams struct {
 	EncKey      []byte
 	Nonce       []byte
 	AccessLevel string
+	GrantedBy   *uuid.UUID
+	ExpiresAt   *time.Time
 }
 
 func (q *Queries) CreateItemKey(ctx context.Context, arg CreateItemKeyParams) error {
@@ -118,6 +166,8 @@ func (q *Queries) CreateItemKey(ctx context.Context, arg CreateItemKeyParams) er
 		arg.EncKey,
 		arg.Nonce,
 		arg.AccessLevel,
+		arg.GrantedBy,
+		arg.ExpiresAt,
 	)
 	return err
 }
@@ -125,18 +175,66 @@ func (q *Queries) CreateItemKey(ctx context.Context, arg CreateItemKeyParams) er
 const getFolderItems = `-- name: GetFolderItems :many
 SELECT
     i.id,
+    i.owner_id,
+    i.org_id,
     i.type,
     i.overview_nonce,
     i.enc_overview,
+    i.created_at,
     i.updated_at,
+    (i.owner_id = k.user_id OR EXISTS (
+        SELECT 1 FROM org_members m
+        WHERE m.org_id = i.org_id
+          AND m.user_id = k.user_id
+          AND m.role IN ('OWNER', 'ADMIN')
+    ))::boolean AS is_owner,
     k.enc_key AS wrapped_key,
-    k.nonce AS key_nonce
+    k.nonce AS key_nonce,
+    k.access_level,
+    NULL::uuid AS group_id,
+    NULL::bytea AS group_key,
+    NULL::bytea AS group_key_nonce
 FROM items i
 JOIN keys k ON i.id = k.item_id
 WHERE i.folder_id = $1
   AND k.user_id = $2
   AND i.deleted_at IS NULL
-ORDER BY i.created_at DESC
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+UNION ALL
+SELECT
+    i.id,
+    i.owner_id,
+    i.org_id,
+    i.type,
+    i.overview_nonce,
+    i.enc_overview,
+    i.created_at,
+    i.updated_at,
+    (i.owner_id = gm.user_id OR EXISTS (
+        SELECT 1 FROM org_members m
+        WHERE m.org_id = i.org_id
+          AND m.user_id = gm.user_id
+          AND m.role IN ('OWNER', 'ADMIN')
+    ))::boolean AS is_owner,
+    gk.enc_key AS wrapped_key,
+    gk.nonce AS key_nonce,
+    gk.access_level,
+    gk.group_id,
+    gm.enc_key AS group_key,
+    gm.nonce AS group_key_nonce
+FROM items i
+JOIN group_keys gk ON i.id = gk.item_id
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE i.folder_id = $1
+  AND gm.user_id = $2
+  AND i.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys dk
+    WHERE dk.item_id = i.id
+      AND dk.user_id = $2
+      AND (dk.expires_at IS NULL OR dk.expires_at > NOW())
+  )
+ORDER BY created_at DESC
 `
 
 type GetFolderItemsParams struct {
@@ -146,14 +244,24 @@ type GetFolderItemsParams struct {
 
 type GetFolderItemsRow struct {
 	ID            uuid.UUID
+	OwnerID       uuid.UUID
+	OrgID         *uuid.UUID
 	Type          string
 	OverviewNonce []byte
 	EncOverview   []byte
+	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	IsOwner       bool
 	WrappedKey    []byte
 	KeyNonce      []byte
+	AccessLevel   string
+	GroupID       *uuid.UUID
+	GroupKey      []byte
+	GroupKeyNonce []byte
 }
 
+// Direct grants take precedence, group grants fill in items the user has no key for.
+// is_owner follows IsItemOwner, so org admins own org items
 func (q *Queries) GetFolderItems(ctx context.Context, arg GetFolderItemsParams) ([]GetFolderItemsRow, error) {
 	rows, err := q.db.Query(ctx, getFolderItems, arg.FolderID, arg.UserID)
 	if err != nil {
@@ -165,12 +273,141 @@ func (q *Queries) GetFolderItems(ctx context.Context, arg GetFolderItemsParams)
 		var i GetFolderItemsRow
 		if err := rows.Scan(
 			&i.ID,
+			&i.OwnerID,
+			&i.OrgID,
 			&i.Type,
 			&i.OverviewNonce,
 			&i.EncOverview,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.IsOwner,
+			&i.WrappedKey,
+			&i.KeyNonce,
+			&i.AccessLevel,
+			&i.GroupID,
+			&i.GroupKey,
+			&i.GroupKeyNonce,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFolderOwner = `-- name: GetFolderOwner :one
+SELECT owner_id FROM folders
+WHERE id = $1
+`
+
+func (q *Queries) GetFolderOwner(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, getFolderOwner, id)
+	var owner_id uuid.UUID
+	err := row.Scan(&owner_id)
+	return owner_id, err
+}
+
+const getFoldersSharedWithUser = `-- name: GetFoldersSharedWithUser :many
+SELECT
+    f.id,
+    f.owner_id,
+    f.nonce,
+    f.enc_metadata,
+    f.updated_at,
+    k.enc_key AS wrapped_key,
+    k.nonce AS key_nonce,
+    k.access_level,
+    k.created_at AS shared_at,
+    NULL::uuid AS group_id,
+    NULL::bytea AS group_key,
+    NULL::bytea AS group_key_nonce
+FROM folders f
+JOIN keys k ON f.id = k.folder_id
+WHERE k.user_id = $1
+  AND NOT (f.owner_id = $1 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = f.org_id
+      AND m.user_id = $1
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND f.deleted_at IS NULL
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+UNION ALL
+SELECT
+    f.id,
+    f.owner_id,
+    f.nonce,
+    f.enc_metadata,
+    f.updated_at,
+    gk.enc_key AS wrapped_key,
+    gk.nonce AS key_nonce,
+    gk.access_level,
+    gk.created_at AS shared_at,
+    gk.group_id,
+    gm.enc_key AS group_key,
+    gm.nonce AS group_key_nonce
+FROM folders f
+JOIN group_keys gk ON f.id = gk.folder_id
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE gm.user_id = $1
+  AND NOT (f.owner_id = $1 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = f.org_id
+      AND m.user_id = $1
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND f.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys dk
+    WHERE dk.folder_id = f.id
+      AND dk.user_id = $1
+      AND (dk.expires_at IS NULL OR dk.expires_at > NOW())
+  )
+ORDER BY shared_at DESC
+`
+
+type GetFoldersSharedWithUserRow struct {
+	ID            uuid.UUID
+	OwnerID       uuid.UUID
+	Nonce         []byte
+	EncMetadata   []byte
+	UpdatedAt     time.Time
+	WrappedKey    []byte
+	KeyNonce      []byte
+	AccessLevel   string
+	SharedAt      time.Time
+	GroupID       *uuid.UUID
+	GroupKey      []byte
+	GroupKeyNonce []byte
+}
+
+// Ownership follows IsFolderOwner, so org resources the user administers are not listed
+// Direct grants take precedence, group grants fill in folders the user has no key for
+func (q *Queries) GetFoldersSharedWithUser(ctx context.Context, userID uuid.UUID) ([]GetFoldersSharedWithUserRow, error) {
+	rows, err := q.db.Query(ctx, getFoldersSharedWithUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFoldersSharedWithUserRow
+	for rows.Next() {
+		var i GetFoldersSharedWithUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.Nonce,
+			&i.EncMetadata,
 			&i.UpdatedAt,
 			&i.WrappedKey,
 			&i.KeyNonce,
+			&i.AccessLevel,
+			&i.SharedAt,
+			&i.GroupID,
+			&i.GroupKey,
+			&i.GroupKeyNonce,
 		); err != nil {
 			return nil, err
 		}
@@ -182,6 +419,26 @@ func (q *Queries) GetFolderItems(ctx context.Context, arg GetFolderItemsParams)
 	return items, nil
 }
 
+const getGrantAccessLevel = `-- name: GetGrantAccessLevel :one
+SELECT access_level
+FROM keys
+WHERE user_id = $1
+  AND (folder_id = $2 OR item_id = $2)
+FOR UPDATE
+`
+
+type GetGrantAccessLevelParams struct {
+	UserID     uuid.UUID
+	ResourceID *uuid.UUID
+}
+
+func (q *Queries) GetGrantAccessLevel(ctx context.Context, arg GetGrantAccessLevelParams) (string, error) {
+	row := q.db.QueryRow(ctx, getGrantAccessLevel, arg.UserID, arg.ResourceID)
+	var access_level string
+	err := row.Scan(&access_level)
+	return access_level, err
+}
+
 const getItemData = `-- name: GetItemData :one
 SELECT
     i.id,
@@ -195,12 +452,47 @@ SELECT
     i.updated_at,
     k.enc_key AS wrapped_key,
     k.nonce AS key_nonce,
-    k.access_level
+    k.access_level,
+    NULL::uuid AS group_id,
+    NULL::bytea AS group_key,
+    NULL::bytea AS group_key_nonce
 FROM items i
 JOIN keys k ON i.id = k.item_id
 WHERE i.id = $1
   AND k.user_id = $2
   AND i.deleted_at IS NULL
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+UNION ALL
+SELECT
+    i.id,
+    i.folder_id,
+    i.type,
+    i.nonce AS item_nonce,
+    i.enc_data,
+    i.enc_overview,
+    i.overview_nonce,
+    i.created_at,
+    i.updated_at,
+    gk.enc_key AS wrapped_key,
+    gk.nonce AS key_nonce,
+    gk.access_level,
+    gk.group_id,
+    gm.enc_key AS group_key,
+    gm.nonce AS group_key_nonce
+FROM items i
+JOIN group_keys gk ON i.id = gk.item_id
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE i.id = $1
+  AND gm.user_id = $2
+  AND i.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys dk
+    WHERE dk.item_id = i.id
+      AND dk.user_id = $2
+      AND (dk.expires_at IS NULL OR dk.expires_at > NOW())
+  )
+ORDER BY access_level DESC
+LIMIT 1
 `
 
 type GetItemDataParams struct {
@@ -221,8 +513,13 @@ type GetItemDataRow struct {
 	WrappedKey    []byte
 	KeyNonce      []byte
 	AccessLevel   string
+	GroupID       *uuid.UUID
+	GroupKey      []byte
+	GroupKeyNonce []byte
 }
 
+// A direct grant takes precedence. Group grants are READ or WRITE, so the
+// descending order picks WRITE when several groups grant the item
 func (q *Queries) GetItemData(ctx context.Context, arg GetItemDataParams) (GetItemDataRow, error) {
 	row := q.db.QueryRow(ctx, getItemData, arg.ID, arg.UserID)
 	var i GetItemDataRow
@@ -239,38 +536,446 @@ func (q *Queries) GetItemData(ctx context.Context, arg GetItemDataParams) (GetIt
 		&i.WrappedKey,
 		&i.KeyNonce,
 		&i.AccessLevel,
+		&i.GroupID,
+		&i.GroupKey,
+		&i.GroupKeyNonce,
 	)
 	return i, err
 }
 
+const getItemFolderID = `-- name: GetItemFolderID :one
+SELECT folder_id
+FROM items
+WHERE id = $1
+`
+
+func (q *Queries) GetItemFolderID(ctx context.Context, id uuid.UUID) (*uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, getItemFolderID, id)
+	var folder_id *uuid.UUID
+	err := row.Scan(&folder_id)
+	return folder_id, err
+}
+
+const getItemOwner = `-- name: GetItemOwner :one
+SELECT owner_id FROM items
+WHERE id = $1
+`
+
+func (q *Queries) GetItemOwner(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, getItemOwner, id)
+	var owner_id uuid.UUID
+	err := row.Scan(&owner_id)
+	return owner_id, err
+}
+
+const getItemsSharedWithUser = `-- name: GetItemsSharedWithUser :many
+SELECT
+    i.id,
+    i.owner_id,
+    i.folder_id,
+    i.type,
+    i.overview_nonce,
+    i.enc_overview,
+    i.updated_at,
+    k.enc_key AS wrapped_key,
+    k.nonce AS key_nonce,
+    k.access_level,
+    k.created_at AS shared_at,
+    NULL::uuid AS group_id,
+    NULL::bytea AS group_key,
+    NULL::bytea AS group_key_nonce
+FROM items i
+JOIN keys k ON i.id = k.item_id
+WHERE k.user_id = $1
+  AND NOT (i.owner_id = $1 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = i.org_id
+      AND m.user_id = $1
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND i.deleted_at IS NULL
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+UNION ALL
+SELECT
+    i.id,
+    i.owner_id,
+    i.folder_id,
+    i.type,
+    i.overview_nonce,
+    i.enc_overview,
+    i.updated_at,
+    gk.enc_key AS wrapped_key,
+    gk.nonce AS key_nonce,
+    gk.access_level,
+    gk.created_at AS shared_at,
+    gk.group_id,
+    gm.enc_key AS group_key,
+    gm.nonce AS group_key_nonce
+FROM items i
+JOIN group_keys gk ON i.id = gk.item_id
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE gm.user_id = $1
+  AND NOT (i.owner_id = $1 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = i.org_id
+      AND m.user_id = $1
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND i.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys dk
+    WHERE dk.item_id = i.id
+      AND dk.user_id = $1
+      AND (dk.expires_at IS NULL OR dk.expires_at > NOW())
+  )
+ORDER BY shared_at DESC
+`
+
+type GetItemsSharedWithUserRow struct {
+	ID            uuid.UUID
+	OwnerID       uuid.UUID
+	FolderID      *uuid.UUID
+	Type          string
+	OverviewNonce []byte
+	EncOverview   []byte
+	UpdatedAt     time.Time
+	WrappedKey    []byte
+	KeyNonce      []byte
+	AccessLevel   string
+	SharedAt      time.Time
+	GroupID       *uuid.UUID
+	GroupKey      []byte
+	GroupKeyNonce []byte
+}
+
+// Ownership follows IsItemOwner, so org resources the user administers are not listed
+// Direct grants take precedence, group grants fill in items the user has no key for
+func (q *Queries) GetItemsSharedWithUser(ctx context.Context, userID uuid.UUID) ([]GetItemsSharedWithUserRow, error) {
+	rows, err := q.db.Query(ctx, getItemsSharedWithUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetItemsSharedWithUserRow
+	for rows.Next() {
+		var i GetItemsSharedWithUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.FolderID,
+			&i.Type,
+			&i.OverviewNonce,
+			&i.EncOverview,
+			&i.UpdatedAt,
+			&i.WrappedKey,
+			&i.KeyNonce,
+			&i.AccessLevel,
+			&i.SharedAt,
+			&i.GroupID,
+			&i.GroupKey,
+			&i.GroupKeyNonce,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getResourceEvents = `-- name: GetResourceEvents :many
+SELECT
+    id,
+    actor_id,
+    action,
+    resource_type,
+    resource_id,
+    target_user_id,
+    created_at
+FROM audit_events
+WHERE resource_id = $1
+ORDER BY created_at DESC
+LIMIT 200
+`
+
+type GetResourceEventsRow struct {
+	ID           uuid.UUID
+	ActorID      uuid.UUID
+	Action       string
+	ResourceType *string
+	ResourceID   *uuid.UUID
+	TargetUserID *uuid.UUID
+	CreatedAt    time.Time
+}
+
+func (q *Queries) GetResourceEvents(ctx context.Context, resourceID *uuid.UUID) ([]GetResourceEventsRow, error) {
+	rows, err := q.db.Query(ctx, getResourceEvents, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetResourceEventsRow
+	for rows.Next() {
+		var i GetResourceEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorID,
+			&i.Action,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.TargetUserID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getResourceGrants = `-- name: GetResourceGrants :many
+SELECT
+    k.user_id,
+    k.access_level,
+    k.created_at,
+    k.granted_by,
+    k.expires_at,
+    NULL::uuid AS group_id
+FROM keys k
+WHERE k.folder_id = $1 OR k.item_id = $1
+UNION ALL
+SELECT
+    gm.user_id,
+    gk.access_level,
+    gk.created_at,
+    gk.granted_by,
+    NULL::timestamptz AS expires_at,
+    gk.group_id
+FROM group_keys gk
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE gk.folder_id = $1 OR gk.item_id = $1
+ORDER BY created_at ASC
+`
+
+type GetResourceGrantsRow struct {
+	UserID      uuid.UUID
+	AccessLevel string
+	CreatedAt   time.Time
+	GrantedBy   *uuid.UUID
+	ExpiresAt   *time.Time
+	GroupID     *uuid.UUID
+}
+
+// Group grants are listed once per member, with the group they come through
+func (q *Queries) GetResourceGrants(ctx context.Context, resourceID *uuid.UUID) ([]GetResourceGrantsRow, error) {
+	rows, err := q.db.Query(ctx, getResourceGrants, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetResourceGrantsRow
+	for rows.Next() {
+		var i GetResourceGrantsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.AccessLevel,
+			&i.CreatedAt,
+			&i.GrantedBy,
+			&i.ExpiresAt,
+			&i.GroupID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getResourceOrgID = `-- name: GetResourceOrgID :one
+SELECT org_id FROM folders WHERE folders.id = $1
+UNION ALL
+SELECT org_id FROM items WHERE items.id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetResourceOrgID(ctx context.Context, resourceID uuid.UUID) (*uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, getResourceOrgID, resourceID)
+	var org_id *uuid.UUID
+	err := row.Scan(&org_id)
+	return org_id, err
+}
+
+const getSharedByUser = `-- name: GetSharedByUser :many
+SELECT
+    'FOLDER'::text AS resource_type,
+    f.id AS resource_id,
+    k.user_id,
+    k.access_level,
+    k.created_at,
+    k.granted_by,
+    k.expires_at
+FROM keys k
+JOIN folders f ON f.id = k.folder_id
+WHERE (f.owner_id = $1 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = f.org_id
+      AND m.user_id = $1
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND k.user_id <> $1
+  AND f.deleted_at IS NULL
+UNION ALL
+SELECT
+    'ITEM'::text AS resource_type,
+    i.id AS resource_id,
+    k.user_id,
+    k.access_level,
+    k.created_at,
+    k.granted_by,
+    k.expires_at
+FROM keys k
+JOIN items i ON i.id = k.item_id
+WHERE (i.owner_id = $1 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = i.org_id
+      AND m.user_id = $1
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND k.user_id <> $1
+  AND i.deleted_at IS NULL
+ORDER BY created_at DESC
+`
+
+type GetSharedByUserRow struct {
+	ResourceType string
+	ResourceID   uuid.UUID
+	UserID       uuid.UUID
+	AccessLevel  string
+	CreatedAt    time.Time
+	GrantedBy    *uuid.UUID
+	ExpiresAt    *time.Time
+}
+
+// Ownership follows IsFolderOwner and IsItemOwner, so org admins see the org
+// resources they manage
+func (q *Queries) GetSharedByUser(ctx context.Context, ownerID uuid.UUID) ([]GetSharedByUserRow, error) {
+	rows, err := q.db.Query(ctx, getSharedByUser, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSharedByUserRow
+	for rows.Next() {
+		var i GetSharedByUserRow
+		if err := rows.Scan(
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.UserID,
+			&i.AccessLevel,
+			&i.CreatedAt,
+			&i.GrantedBy,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserFolders = `-- name: GetUserFolders :many
 SELECT
     f.id,
+    f.owner_id,
+    f.org_id,
     f.nonce,
     f.enc_metadata,
+    f.created_at,
     f.updated_at,
+    (f.owner_id = $1 OR EXISTS (
+        SELECT 1 FROM org_members m
+        WHERE m.org_id = f.org_id
+          AND m.user_id = $1
+          AND m.role IN ('OWNER', 'ADMIN')
+    ))::boolean AS is_owner,
     k.enc_key AS wrapped_key,
     k.nonce AS key_nonce,
-    k.access_level
+    k.access_level,
+    NULL::uuid AS group_id,
+    NULL::bytea AS group_key,
+    NULL::bytea AS group_key_nonce
 FROM folders f
 JOIN keys k ON f.id = k.folder_id
 WHERE k.user_id = $1
   AND f.deleted_at IS NULL
-ORDER BY f.created_at ASC
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+UNION ALL
+SELECT
+    f.id,
+    f.owner_id,
+    f.org_id,
+    f.nonce,
+    f.enc_metadata,
+    f.created_at,
+    f.updated_at,
+    (f.owner_id = $1 OR EXISTS (
+        SELECT 1 FROM org_members m
+        WHERE m.org_id = f.org_id
+          AND m.user_id = $1
+          AND m.role IN ('OWNER', 'ADMIN')
+    ))::boolean AS is_owner,
+    gk.enc_key AS wrapped_key,
+    gk.nonce AS key_nonce,
+    gk.access_level,
+    gk.group_id,
+    gm.enc_key AS group_key,
+    gm.nonce AS group_key_nonce
+FROM folders f
+JOIN group_keys gk ON f.id = gk.folder_id
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE gm.user_id = $1
+  AND f.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys dk
+    WHERE dk.folder_id = f.id
+      AND dk.user_id = $1
+      AND (dk.expires_at IS NULL OR dk.expires_at > NOW())
+  )
+ORDER BY created_at ASC
 `
 
 type GetUserFoldersRow struct {
-	ID          uuid.UUID
-	Nonce       []byte
-	EncMetadata []byte
-	UpdatedAt   time.Time
-	WrappedKey  []byte
-	KeyNonce    []byte
-	AccessLevel string
+	ID            uuid.UUID
+	OwnerID       uuid.UUID
+	OrgID         *uuid.UUID
+	Nonce         []byte
+	EncMetadata   []byte
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	IsOwner       bool
+	WrappedKey    []byte
+	KeyNonce      []byte
+	AccessLevel   string
+	GroupID       *uuid.UUID
+	GroupKey      []byte
+	GroupKeyNonce []byte
 }
 
-func (q *Queries) GetUserFolders(ctx context.Context, userID uuid.UUID) ([]GetUserFoldersRow, error) {
-	rows, err := q.db.Query(ctx, getUserFolders, userID)
+// Direct grants take precedence, group grants fill in folders the user has no key for.
+// is_owner follows IsFolderOwner, so org admins own org folders
+func (q *Queries) GetUserFolders(ctx context.Context, ownerID uuid.UUID) ([]GetUserFoldersRow, error) {
+	rows, err := q.db.Query(ctx, getUserFolders, ownerID)
 	if err != nil {
 		return nil, err
 	}
@@ -280,12 +985,19 @@ func (q *Queries) GetUserFolders(ctx context.Context, userID uuid.UUID) ([]GetUs
 		var i GetUserFoldersRow
 		if err := rows.Scan(
 			&i.ID,
+			&i.OwnerID,
+			&i.OrgID,
 			&i.Nonce,
 			&i.EncMetadata,
+			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsOwner,
 			&i.WrappedKey,
 			&i.KeyNonce,
 			&i.AccessLevel,
+			&i.GroupID,
+			&i.GroupKey,
+			&i.GroupKeyNonce,
 		); err != nil {
 			return nil, err
 		}
@@ -299,7 +1011,13 @@ func (q *Queries) GetUserFolders(ctx context.Context, userID uuid.UUID) ([]GetUs
 
 const isFolderOwner = `-- name: IsFolderOwner :one
 SELECT 1 FROM folders
-WHERE id = $1 AND owner_id = $2
+WHERE id = $1
+  AND (owner_id = $2 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = folders.org_id
+      AND m.user_id = $2
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
 `
 
 type IsFolderOwnerParams struct {
@@ -307,6 +1025,7 @@ type IsFolderOwnerParams struct {
 	OwnerID uuid.UUID
 }
 
+// Admins of the owning organization count as owners of org resources
 func (q *Queries) IsFolderOwner(ctx context.Context, arg IsFolderOwnerParams) (int32, error) {
 	row := q.db.QueryRow(ctx, isFolderOwner, arg.ID, arg.OwnerID)
 	var column_1 int32
@@ -316,7 +1035,13 @@ func (q *Queries) IsFolderOwner(ctx context.Context, arg IsFolderOwnerParams) (i
 
 const isItemOwner = `-- name: IsItemOwner :one
 SELECT 1 FROM items
-WHERE id = $1 AND owner_id = $2
+WHERE id = $1
+  AND (owner_id = $2 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = items.org_id
+      AND m.user_id = $2
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
 `
 
 type IsItemOwnerParams struct {
@@ -324,6 +1049,7 @@ type IsItemOwnerParams struct {
 	OwnerID uuid.UUID
 }
 
+// Admins of the owning organization count as owners of org resources
 func (q *Queries) IsItemOwner(ctx context.Context, arg IsItemOwnerParams) (int32, error) {
 	row := q.db.QueryRow(ctx, isItemOwner, arg.ID, arg.OwnerID)
 	var column_1 int32
@@ -331,6 +1057,132 @@ func (q *Queries) IsItemOwner(ctx context.Context, arg IsItemOwnerParams) (int32
 	return column_1, err
 }
 
+const listAuditEvents = `-- name: ListAuditEvents :many
+SELECT
+    e.id,
+    e.actor_id,
+    e.action,
+    e.resource_type,
+    e.resource_id,
+    e.owner_id,
+    e.org_id,
+    e.target_user_id,
+    e.ip,
+    e.user_agent,
+    e.created_at
+FROM audit_events e
+WHERE (
+        e.owner_id = $1::uuid
+        OR e.org_id IN (
+            SELECT m.org_id FROM org_members m
+            WHERE m.user_id = $1 AND m.role IN ('OWNER', 'ADMIN')
+        )
+    )
+    AND ($2::timestamptz IS NULL OR e.created_at >= $2)
+    AND ($3::timestamptz IS NULL OR e.created_at < $3)
+    AND ($4::uuid IS NULL OR e.actor_id = $4)
+    AND ($5::uuid IS NULL OR e.resource_id = $5)
+    AND ($6::text IS NULL OR e.resource_type = $6)
+    AND ($7::text IS NULL OR e.action = $7)
+ORDER BY e.created_at DESC
+LIMIT $8
+`
+
+type ListAuditEventsParams struct {
+	UserID       uuid.UUID
+	Since        *time.Time
+	Until        *time.Time
+	ActorID      *uuid.UUID
+	ResourceID   *uuid.UUID
+	ResourceType *string
+	Action       *string
+	RowLimit     int32
+}
+
+type ListAuditEventsRow struct {
+	ID           uuid.UUID
+	ActorID      uuid.UUID
+	Action       string
+	ResourceType *string
+	ResourceID   *uuid.UUID
+	OwnerID      *uuid.UUID
+	OrgID        *uuid.UUID
+	TargetUserID *uuid.UUID
+	Ip           *string
+	UserAgent    *string
+	CreatedAt    time.Time
+}
+
+// Events on resources the user owns, plus everything in organizations they administer
+func (q *Queries) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]ListAuditEventsRow, error) {
+	rows, err := q.db.Query(ctx, listAuditEvents,
+		arg.UserID,
+		arg.Since,
+		arg.Until,
+		arg.ActorID,
+		arg.ResourceID,
+		arg.ResourceType,
+		arg.Action,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAuditEventsRow
+	for rows.Next() {
+		var i ListAuditEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorID,
+			&i.Action,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.OwnerID,
+			&i.OrgID,
+			&i.TargetUserID,
+			&i.Ip,
+			&i.UserAgent,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockResourceOwners = `-- name: LockResourceOwners :many
+SELECT user_id
+FROM keys
+WHERE (folder_id = $1 OR item_id = $1)
+  AND access_level = 'OWNER'
+FOR UPDATE
+`
+
+func (q *Queries) LockResourceOwners(ctx context.Context, resourceID *uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, lockResourceOwners, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const revokeUserAccess = `-- name: RevokeUserAccess :exec
 DELETE FROM keys
 WHERE user_id = $1
@@ -350,7 +1202,13 @@ func (q *Queries) RevokeUserAccess(ctx context.Context, arg RevokeUserAccessPara
 const softDeleteFolder = `-- name: SoftDeleteFolder :execrows
 UPDATE folders
 SET deleted_at = NOW()
-WHERE id = $1 AND owner_id = $2
+WHERE id = $1
+  AND (owner_id = $2 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = folders.org_id
+      AND m.user_id = $2
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
 `
 
 type SoftDeleteFolderParams struct {
@@ -369,7 +1227,13 @@ func (q *Queries) SoftDeleteFolder(ctx context.Context, arg SoftDeleteFolderPara
 const softDeleteItem = `-- name: SoftDeleteItem :execrows
 UPDATE items
 SET deleted_at = NOW()
-WHERE id = $1 AND owner_id = $2
+WHERE id = $1
+  AND (owner_id = $2 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = items.org_id
+      AND m.user_id = $2
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
 `
 
 type SoftDeleteItemParams struct {
@@ -385,13 +1249,75 @@ func (q *Queries) SoftDeleteItem(ctx context.Context, arg SoftDeleteItemParams)
 	return result.RowsAffected(), nil
 }
 
+const transferFolderOwnership = `-- name: TransferFolderOwnership :execrows
+UPDATE folders
+SET
+    owner_id = $1,
+    updated_at = NOW()
+WHERE id = $2
+  AND (owner_id = $3 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = folders.org_id
+      AND m.user_id = $3
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+`
+
+type TransferFolderOwnershipParams struct {
+	NewOwnerID uuid.UUID
+	ID         uuid.UUID
+	OwnerID    uuid.UUID
+}
+
+func (q *Queries) TransferFolderOwnership(ctx context.Context, arg TransferFolderOwnershipParams) (int64, error) {
+	result, err := q.db.Exec(ctx, transferFolderOwnership, arg.NewOwnerID, arg.ID, arg.OwnerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const transferItemOwnership = `-- name: TransferItemOwnership :execrows
+UPDATE items
+SET
+    owner_id = $1,
+    updated_at = NOW()
+WHERE id = $2
+  AND (owner_id = $3 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = items.org_id
+      AND m.user_id = $3
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+`
+
+type TransferItemOwnershipParams struct {
+	NewOwnerID uuid.UUID
+	ID         uuid.UUID
+	OwnerID    uuid.UUID
+}
+
+func (q *Queries) TransferItemOwnership(ctx context.Context, arg TransferItemOwnershipParams) (int64, error) {
+	result, err := q.db.Exec(ctx, transferItemOwnership, arg.NewOwnerID, arg.ID, arg.OwnerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateFolderMetadata = `-- name: UpdateFolderMetadata :execrows
 UPDATE folders
 SET
     enc_metadata = $1,
     nonce = $2,
     updated_at = NOW()
-WHERE id = $3 AND owner_id = $4
+WHERE id = $3
+  AND (owner_id = $4 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = folders.org_id
+      AND m.user_id = $4
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
 `
 
 type UpdateFolderMetadataParams struct {
@@ -414,6 +1340,27 @@ func (q *Queries) UpdateFolderMetadata(ctx context.Context, arg UpdateFolderMeta
 	return result.RowsAffected(), nil
 }
 
+const updateGrantAccessLevel = `-- name: UpdateGrantAccessLevel :execrows
+UPDATE keys
+SET access_level = $1
+WHERE user_id = $2
+  AND (folder_id = $3 OR item_id = $3)
+`
+
+type UpdateGrantAccessLevelParams struct {
+	AccessLevel string
+	UserID      uuid.UUID
+	ResourceID  *uuid.UUID
+}
+
+func (q *Queries) UpdateGrantAccessLevel(ctx context.Context, arg UpdateGrantAccessLevelParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateGrantAccessLevel, arg.AccessLevel, arg.UserID, arg.ResourceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateItemBlob = `-- name: UpdateItemBlob :exec
 UPDATE items
 SET
@@ -0,0 +1,69 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: ratelimit.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const deleteIdleRateLimitBuckets = `-- name: DeleteIdleRateLimitBuckets :execrows
+DELETE FROM rate_limit_buckets
+WHERE updated_at < $1
+`
+
+func (q *Queries) DeleteIdleRateLimitBuckets(ctx context.Context, idleBefore time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteIdleRateLimitBuckets, idleBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getRateLimitTokens = `-- name: GetRateLimitTokens :one
+SELECT LEAST($1::float8, tokens + EXTRACT(EPOCH FROM NOW() - updated_at)::float8 * $2::float8)::float8 AS tokens
+FROM rate_limit_buckets
+WHERE key = $3
+`
+
+type GetRateLimitTokensParams struct {
+	Burst float64
+	Rate  float64
+	Key   string
+}
+
+func (q *Queries) GetRateLimitTokens(ctx context.Context, arg GetRateLimitTokensParams) (float64, error) {
+	row := q.db.QueryRow(ctx, getRateLimitTokens, arg.Burst, arg.Rate, arg.Key)
+	var tokens float64
+	err := row.Scan(&tokens)
+	return tokens, err
+}
+
+const takeRateLimitToken = `-- name: TakeRateLimitToken :one
+INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at)
+VALUES ($1, $2::float8 - 1, NOW())
+ON CONFLICT (key) DO UPDATE
+SET
+    tokens = LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at)::float8 * $3::float8) - 1,
+    updated_at = NOW()
+WHERE LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at)::float8 * $3::float8) >= 1
+RETURNING tokens
+`
+
+type TakeRateLimitTokenParams struct {
+	Key   string
+	Burst float64
+	Rate  float64
+}
+
+// Refills the bucket for the time since its last update and takes one token.
+// Returns no row when the bucket has less than one token, leaving it as is
+func (q *Queries) TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (float64, error) {
+	row := q.db.QueryRow(ctx, takeRateLimitToken, arg.Key, arg.Burst, arg.Rate)
+	var tokens float64
+	err := row.Scan(&tokens)
+	return tokens, err
+}
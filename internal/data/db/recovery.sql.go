@@ -0,0 +1,370 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: recovery.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const approveRecoveryRequest = `-- name: ApproveRecoveryRequest :exec
+UPDATE recovery_requests
+SET
+    status = 'APPROVED',
+    approved_by = $2,
+    approved_at = NOW()
+WHERE id = $1
+`
+
+type ApproveRecoveryRequestParams struct {
+	ID         uuid.UUID
+	ApprovedBy *uuid.UUID
+}
+
+func (q *Queries) ApproveRecoveryRequest(ctx context.Context, arg ApproveRecoveryRequestParams) error {
+	_, err := q.db.Exec(ctx, approveRecoveryRequest, arg.ID, arg.ApprovedBy)
+	return err
+}
+
+const cancelOpenRecoveryRequests = `-- name: CancelOpenRecoveryRequests :exec
+UPDATE recovery_requests
+SET status = 'CANCELLED'
+WHERE org_id = $1 AND status IN ('PENDING', 'APPROVED')
+`
+
+func (q *Queries) CancelOpenRecoveryRequests(ctx context.Context, orgID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, cancelOpenRecoveryRequests, orgID)
+	return err
+}
+
+const cancelRecoveryRequest = `-- name: CancelRecoveryRequest :exec
+UPDATE recovery_requests
+SET status = 'CANCELLED'
+WHERE id = $1
+`
+
+func (q *Queries) CancelRecoveryRequest(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, cancelRecoveryRequest, id)
+	return err
+}
+
+const completeRecoveryRequest = `-- name: CompleteRecoveryRequest :exec
+UPDATE recovery_requests
+SET
+    status = 'COMPLETED',
+    recovered_keyring = $2,
+    completed_at = NOW()
+WHERE id = $1
+`
+
+type CompleteRecoveryRequestParams struct {
+	ID               uuid.UUID
+	RecoveredKeyring []byte
+}
+
+func (q *Queries) CompleteRecoveryRequest(ctx context.Context, arg CompleteRecoveryRequestParams) error {
+	_, err := q.db.Exec(ctx, completeRecoveryRequest, arg.ID, arg.RecoveredKeyring)
+	return err
+}
+
+const createRecoveryRequest = `-- name: CreateRecoveryRequest :one
+INSERT INTO recovery_requests (org_id, user_id, device_public_key, initiated_by)
+VALUES ($1, $2, $3, $4)
+RETURNING id, org_id, user_id, device_public_key, recovered_keyring, status, initiated_by, approved_by, created_at, approved_at, completed_at
+`
+
+type CreateRecoveryRequestParams struct {
+	OrgID           uuid.UUID
+	UserID          uuid.UUID
+	DevicePublicKey []byte
+	InitiatedBy     uuid.UUID
+}
+
+func (q *Queries) CreateRecoveryRequest(ctx context.Context, arg CreateRecoveryRequestParams) (RecoveryRequest, error) {
+	row := q.db.QueryRow(ctx, createRecoveryRequest,
+		arg.OrgID,
+		arg.UserID,
+		arg.DevicePublicKey,
+		arg.InitiatedBy,
+	)
+	var i RecoveryRequest
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.UserID,
+		&i.DevicePublicKey,
+		&i.RecoveredKeyring,
+		&i.Status,
+		&i.InitiatedBy,
+		&i.ApprovedBy,
+		&i.CreatedAt,
+		&i.ApprovedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const deleteOrgRecoveryEnrollments = `-- name: DeleteOrgRecoveryEnrollments :exec
+DELETE FROM recovery_enrollments
+WHERE org_id = $1
+`
+
+// Enrollments are wrapped with the policy key, so they go away with it
+func (q *Queries) DeleteOrgRecoveryEnrollments(ctx context.Context, orgID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteOrgRecoveryEnrollments, orgID)
+	return err
+}
+
+const deleteRecoveryEnrollment = `-- name: DeleteRecoveryEnrollment :execrows
+DELETE FROM recovery_enrollments
+WHERE org_id = $1 AND user_id = $2
+`
+
+type DeleteRecoveryEnrollmentParams struct {
+	OrgID  uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteRecoveryEnrollment(ctx context.Context, arg DeleteRecoveryEnrollmentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRecoveryEnrollment, arg.OrgID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRecoveryPolicy = `-- name: DeleteRecoveryPolicy :execrows
+DELETE FROM org_recovery_policies
+WHERE org_id = $1
+`
+
+func (q *Queries) DeleteRecoveryPolicy(ctx context.Context, orgID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRecoveryPolicy, orgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getOrgRecoveryEnrollments = `-- name: GetOrgRecoveryEnrollments :many
+SELECT user_id, created_at, updated_at
+FROM recovery_enrollments
+WHERE org_id = $1
+ORDER BY created_at ASC
+`
+
+type GetOrgRecoveryEnrollmentsRow struct {
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (q *Queries) GetOrgRecoveryEnrollments(ctx context.Context, orgID uuid.UUID) ([]GetOrgRecoveryEnrollmentsRow, error) {
+	rows, err := q.db.Query(ctx, getOrgRecoveryEnrollments, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrgRecoveryEnrollmentsRow
+	for rows.Next() {
+		var i GetOrgRecoveryEnrollmentsRow
+		if err := rows.Scan(&i.UserID, &i.CreatedAt, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrgRecoveryRequests = `-- name: GetOrgRecoveryRequests :many
+SELECT id, org_id, user_id, device_public_key, recovered_keyring, status, initiated_by, approved_by, created_at, approved_at, completed_at
+FROM recovery_requests
+WHERE org_id = $1
+ORDER BY created_at DESC
+LIMIT 200
+`
+
+func (q *Queries) GetOrgRecoveryRequests(ctx context.Context, orgID uuid.UUID) ([]RecoveryRequest, error) {
+	rows, err := q.db.Query(ctx, getOrgRecoveryRequests, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RecoveryRequest
+	for rows.Next() {
+		var i RecoveryRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrgID,
+			&i.UserID,
+			&i.DevicePublicKey,
+			&i.RecoveredKeyring,
+			&i.Status,
+			&i.InitiatedBy,
+			&i.ApprovedBy,
+			&i.CreatedAt,
+			&i.ApprovedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecoveryEnrollment = `-- name: GetRecoveryEnrollment :one
+SELECT org_id, user_id, wrapped_keyring, created_at, updated_at
+FROM recovery_enrollments
+WHERE org_id = $1 AND user_id = $2
+`
+
+type GetRecoveryEnrollmentParams struct {
+	OrgID  uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetRecoveryEnrollment(ctx context.Context, arg GetRecoveryEnrollmentParams) (RecoveryEnrollment, error) {
+	row := q.db.QueryRow(ctx, getRecoveryEnrollment, arg.OrgID, arg.UserID)
+	var i RecoveryEnrollment
+	err := row.Scan(
+		&i.OrgID,
+		&i.UserID,
+		&i.WrappedKeyring,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRecoveryPolicy = `-- name: GetRecoveryPolicy :one
+SELECT org_id, public_key, updated_by, created_at, updated_at
+FROM org_recovery_policies
+WHERE org_id = $1
+`
+
+func (q *Queries) GetRecoveryPolicy(ctx context.Context, orgID uuid.UUID) (OrgRecoveryPolicy, error) {
+	row := q.db.QueryRow(ctx, getRecoveryPolicy, orgID)
+	var i OrgRecoveryPolicy
+	err := row.Scan(
+		&i.OrgID,
+		&i.PublicKey,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRecoveryRequest = `-- name: GetRecoveryRequest :one
+SELECT id, org_id, user_id, device_public_key, recovered_keyring, status, initiated_by, approved_by, created_at, approved_at, completed_at
+FROM recovery_requests
+WHERE id = $1 AND org_id = $2
+`
+
+type GetRecoveryRequestParams struct {
+	ID    uuid.UUID
+	OrgID uuid.UUID
+}
+
+func (q *Queries) GetRecoveryRequest(ctx context.Context, arg GetRecoveryRequestParams) (RecoveryRequest, error) {
+	row := q.db.QueryRow(ctx, getRecoveryRequest, arg.ID, arg.OrgID)
+	var i RecoveryRequest
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.UserID,
+		&i.DevicePublicKey,
+		&i.RecoveredKeyring,
+		&i.Status,
+		&i.InitiatedBy,
+		&i.ApprovedBy,
+		&i.CreatedAt,
+		&i.ApprovedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getRecoveryRequestForUpdate = `-- name: GetRecoveryRequestForUpdate :one
+SELECT id, org_id, user_id, device_public_key, recovered_keyring, status, initiated_by, approved_by, created_at, approved_at, completed_at
+FROM recovery_requests
+WHERE id = $1 AND org_id = $2
+FOR UPDATE
+`
+
+type GetRecoveryRequestForUpdateParams struct {
+	ID    uuid.UUID
+	OrgID uuid.UUID
+}
+
+func (q *Queries) GetRecoveryRequestForUpdate(ctx context.Context, arg GetRecoveryRequestForUpdateParams) (RecoveryRequest, error) {
+	row := q.db.QueryRow(ctx, getRecoveryRequestForUpdate, arg.ID, arg.OrgID)
+	var i RecoveryRequest
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.UserID,
+		&i.DevicePublicKey,
+		&i.RecoveredKeyring,
+		&i.Status,
+		&i.InitiatedBy,
+		&i.ApprovedBy,
+		&i.CreatedAt,
+		&i.ApprovedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const upsertRecoveryEnrollment = `-- name: UpsertRecoveryEnrollment :exec
+INSERT INTO recovery_enrollments (org_id, user_id, wrapped_keyring)
+VALUES ($1, $2, $3)
+ON CONFLICT (org_id, user_id) DO UPDATE
+SET
+    wrapped_keyring = EXCLUDED.wrapped_keyring,
+    updated_at = NOW()
+`
+
+type UpsertRecoveryEnrollmentParams struct {
+	OrgID          uuid.UUID
+	UserID         uuid.UUID
+	WrappedKeyring []byte
+}
+
+func (q *Queries) UpsertRecoveryEnrollment(ctx context.Context, arg UpsertRecoveryEnrollmentParams) error {
+	_, err := q.db.Exec(ctx, upsertRecoveryEnrollment, arg.OrgID, arg.UserID, arg.WrappedKeyring)
+	return err
+}
+
+const upsertRecoveryPolicy = `-- name: UpsertRecoveryPolicy :exec
+INSERT INTO org_recovery_policies (org_id, public_key, updated_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (org_id) DO UPDATE
+SET
+    public_key = EXCLUDED.public_key,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = NOW()
+`
+
+type UpsertRecoveryPolicyParams struct {
+	OrgID     uuid.UUID
+	PublicKey []byte
+	UpdatedBy uuid.UUID
+}
+
+func (q *Queries) UpsertRecoveryPolicy(ctx context.Context, arg UpsertRecoveryPolicyParams) error {
+	_, err := q.db.Exec(ctx, upsertRecoveryPolicy, arg.OrgID, arg.PublicKey, arg.UpdatedBy)
+	return err
+}
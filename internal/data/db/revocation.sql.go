@@ -0,0 +1,97 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: revocation.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteExpiredRevokedTokens = `-- name: DeleteExpiredRevokedTokens :execrows
+DELETE FROM revoked_tokens
+WHERE expires_at < NOW()
+`
+
+func (q *Queries) DeleteExpiredRevokedTokens(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredRevokedTokens)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getSessionsRevokedBefore = `-- name: GetSessionsRevokedBefore :one
+SELECT revoked_before
+FROM session_revocations
+WHERE user_id = $1
+`
+
+func (q *Queries) GetSessionsRevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	row := q.db.QueryRow(ctx, getSessionsRevokedBefore, userID)
+	var revoked_before time.Time
+	err := row.Scan(&revoked_before)
+	return revoked_before, err
+}
+
+const isTokenRevoked = `-- name: IsTokenRevoked :one
+SELECT EXISTS (
+    SELECT 1 FROM revoked_tokens WHERE jti = $1
+) AS revoked
+`
+
+func (q *Queries) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	row := q.db.QueryRow(ctx, isTokenRevoked, jti)
+	var revoked bool
+	err := row.Scan(&revoked)
+	return revoked, err
+}
+
+const revokeSessions = `-- name: RevokeSessions :exec
+INSERT INTO session_revocations (user_id, revoked_before, revoked_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET
+    revoked_before = GREATEST(session_revocations.revoked_before, EXCLUDED.revoked_before),
+    revoked_by = EXCLUDED.revoked_by,
+    updated_at = NOW()
+`
+
+type RevokeSessionsParams struct {
+	UserID        uuid.UUID
+	RevokedBefore time.Time
+	RevokedBy     *uuid.UUID
+}
+
+// Never moves the marker back, an older revocation must not revive tokens
+func (q *Queries) RevokeSessions(ctx context.Context, arg RevokeSessionsParams) error {
+	_, err := q.db.Exec(ctx, revokeSessions, arg.UserID, arg.RevokedBefore, arg.RevokedBy)
+	return err
+}
+
+const revokeToken = `-- name: RevokeToken :exec
+INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (jti) DO NOTHING
+`
+
+type RevokeTokenParams struct {
+	Jti       string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	RevokedBy *uuid.UUID
+}
+
+func (q *Queries) RevokeToken(ctx context.Context, arg RevokeTokenParams) error {
+	_, err := q.db.Exec(ctx, revokeToken,
+		arg.Jti,
+		arg.UserID,
+		arg.ExpiresAt,
+		arg.RevokedBy,
+	)
+	return err
+}
@@ -0,0 +1,330 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: serviceaccount.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createAPIToken = `-- name: CreateAPIToken :one
+INSERT INTO api_tokens (service_account_id, name, token_hash, token_prefix, read_only, folder_ids, item_ids, expires_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, service_account_id, name, token_prefix, read_only, folder_ids, item_ids, expires_at, revoked_at, last_used_at, created_by, created_at
+`
+
+type CreateAPITokenParams struct {
+	ServiceAccountID uuid.UUID
+	Name             string
+	TokenHash        []byte
+	TokenPrefix      string
+	ReadOnly         bool
+	FolderIds        []uuid.UUID
+	ItemIds          []uuid.UUID
+	ExpiresAt        time.Time
+	CreatedBy        uuid.UUID
+}
+
+type CreateAPITokenRow struct {
+	ID               uuid.UUID
+	ServiceAccountID uuid.UUID
+	Name             string
+	TokenPrefix      string
+	ReadOnly         bool
+	FolderIds        []uuid.UUID
+	ItemIds          []uuid.UUID
+	ExpiresAt        time.Time
+	RevokedAt        *time.Time
+	LastUsedAt       *time.Time
+	CreatedBy        uuid.UUID
+	CreatedAt        time.Time
+}
+
+func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (CreateAPITokenRow, error) {
+	row := q.db.QueryRow(ctx, createAPIToken,
+		arg.ServiceAccountID,
+		arg.Name,
+		arg.TokenHash,
+		arg.TokenPrefix,
+		arg.ReadOnly,
+		arg.FolderIds,
+		arg.ItemIds,
+		arg.ExpiresAt,
+		arg.CreatedBy,
+	)
+	var i CreateAPITokenRow
+	err := row.Scan(
+		&i.ID,
+		&i.ServiceAccountID,
+		&i.Name,
+		&i.TokenPrefix,
+		&i.ReadOnly,
+		&i.FolderIds,
+		&i.ItemIds,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.LastUsedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createServiceAccount = `-- name: CreateServiceAccount :one
+INSERT INTO service_accounts (user_id, org_id, name, public_key, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, org_id, name, public_key, created_by, created_at
+`
+
+type CreateServiceAccountParams struct {
+	UserID    *uuid.UUID
+	OrgID     *uuid.UUID
+	Name      string
+	PublicKey []byte
+	CreatedBy uuid.UUID
+}
+
+func (q *Queries) CreateServiceAccount(ctx context.Context, arg CreateServiceAccountParams) (ServiceAccount, error) {
+	row := q.db.QueryRow(ctx, createServiceAccount,
+		arg.UserID,
+		arg.OrgID,
+		arg.Name,
+		arg.PublicKey,
+		arg.CreatedBy,
+	)
+	var i ServiceAccount
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.OrgID,
+		&i.Name,
+		&i.PublicKey,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteServiceAccount = `-- name: DeleteServiceAccount :exec
+DELETE FROM service_accounts
+WHERE id = $1
+`
+
+func (q *Queries) DeleteServiceAccount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteServiceAccount, id)
+	return err
+}
+
+const getActiveAPIToken = `-- name: GetActiveAPIToken :one
+SELECT id, service_account_id, read_only, folder_ids, item_ids, expires_at
+FROM api_tokens
+WHERE token_hash = $1
+  AND revoked_at IS NULL
+  AND expires_at > NOW()
+`
+
+type GetActiveAPITokenRow struct {
+	ID               uuid.UUID
+	ServiceAccountID uuid.UUID
+	ReadOnly         bool
+	FolderIds        []uuid.UUID
+	ItemIds          []uuid.UUID
+	ExpiresAt        time.Time
+}
+
+func (q *Queries) GetActiveAPIToken(ctx context.Context, tokenHash []byte) (GetActiveAPITokenRow, error) {
+	row := q.db.QueryRow(ctx, getActiveAPIToken, tokenHash)
+	var i GetActiveAPITokenRow
+	err := row.Scan(
+		&i.ID,
+		&i.ServiceAccountID,
+		&i.ReadOnly,
+		&i.FolderIds,
+		&i.ItemIds,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getOrgServiceAccounts = `-- name: GetOrgServiceAccounts :many
+SELECT id, user_id, org_id, name, public_key, created_by, created_at
+FROM service_accounts
+WHERE org_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetOrgServiceAccounts(ctx context.Context, orgID *uuid.UUID) ([]ServiceAccount, error) {
+	rows, err := q.db.Query(ctx, getOrgServiceAccounts, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ServiceAccount
+	for rows.Next() {
+		var i ServiceAccount
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrgID,
+			&i.Name,
+			&i.PublicKey,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getServiceAccount = `-- name: GetServiceAccount :one
+SELECT id, user_id, org_id, name, public_key, created_by, created_at
+FROM service_accounts
+WHERE id = $1
+`
+
+func (q *Queries) GetServiceAccount(ctx context.Context, id uuid.UUID) (ServiceAccount, error) {
+	row := q.db.QueryRow(ctx, getServiceAccount, id)
+	var i ServiceAccount
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.OrgID,
+		&i.Name,
+		&i.PublicKey,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getServiceAccountTokens = `-- name: GetServiceAccountTokens :many
+SELECT id, service_account_id, name, token_prefix, read_only, folder_ids, item_ids, expires_at, revoked_at, last_used_at, created_by, created_at
+FROM api_tokens
+WHERE service_account_id = $1
+ORDER BY created_at DESC
+`
+
+type GetServiceAccountTokensRow struct {
+	ID               uuid.UUID
+	ServiceAccountID uuid.UUID
+	Name             string
+	TokenPrefix      string
+	ReadOnly         bool
+	FolderIds        []uuid.UUID
+	ItemIds          []uuid.UUID
+	ExpiresAt        time.Time
+	RevokedAt        *time.Time
+	LastUsedAt       *time.Time
+	CreatedBy        uuid.UUID
+	CreatedAt        time.Time
+}
+
+func (q *Queries) GetServiceAccountTokens(ctx context.Context, serviceAccountID uuid.UUID) ([]GetServiceAccountTokensRow, error) {
+	rows, err := q.db.Query(ctx, getServiceAccountTokens, serviceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetServiceAccountTokensRow
+	for rows.Next() {
+		var i GetServiceAccountTokensRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ServiceAccountID,
+			&i.Name,
+			&i.TokenPrefix,
+			&i.ReadOnly,
+			&i.FolderIds,
+			&i.ItemIds,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+			&i.LastUsedAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserServiceAccounts = `-- name: GetUserServiceAccounts :many
+SELECT id, user_id, org_id, name, public_key, created_by, created_at
+FROM service_accounts
+WHERE user_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetUserServiceAccounts(ctx context.Context, userID *uuid.UUID) ([]ServiceAccount, error) {
+	rows, err := q.db.Query(ctx, getUserServiceAccounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ServiceAccount
+	for rows.Next() {
+		var i ServiceAccount
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrgID,
+			&i.Name,
+			&i.PublicKey,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAPIToken = `-- name: RevokeAPIToken :execrows
+UPDATE api_tokens
+SET revoked_at = NOW()
+WHERE id = $1 AND service_account_id = $2 AND revoked_at IS NULL
+`
+
+type RevokeAPITokenParams struct {
+	ID               uuid.UUID
+	ServiceAccountID uuid.UUID
+}
+
+func (q *Queries) RevokeAPIToken(ctx context.Context, arg RevokeAPITokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeAPIToken, arg.ID, arg.ServiceAccountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const touchAPIToken = `-- name: TouchAPIToken :exec
+UPDATE api_tokens
+SET last_used_at = NOW()
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+`
+
+// Only written once a minute to keep authentication cheap
+func (q *Queries) TouchAPIToken(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, touchAPIToken, id)
+	return err
+}
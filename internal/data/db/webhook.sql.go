@@ -0,0 +1,371 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: webhook.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const claimWebhookDeliveries = `-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET
+    attempts = d.attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => $1::int)
+FROM webhooks w
+WHERE w.id = d.webhook_id
+  AND d.id IN (
+        SELECT due.id FROM webhook_deliveries due
+        WHERE due.status = 'PENDING' AND due.next_attempt_at <= NOW()
+        ORDER BY due.next_attempt_at ASC
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING d.id, d.event_type, d.payload, d.attempts, w.url, w.secret
+`
+
+type ClaimWebhookDeliveriesParams struct {
+	LeaseSeconds int32
+	BatchSize    int32
+}
+
+type ClaimWebhookDeliveriesRow struct {
+	ID        uuid.UUID
+	EventType string
+	Payload   []byte
+	Attempts  int32
+	Url       string
+	Secret    string
+}
+
+// Leases due deliveries by pushing next_attempt_at forward, so a crashed
+// worker's deliveries are retried once the lease runs out
+func (q *Queries) ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, claimWebhookDeliveries, arg.LeaseSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimWebhookDeliveriesRow
+	for rows.Next() {
+		var i ClaimWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks (user_id, org_id, url, secret, events, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, org_id, url, secret, events, created_by, created_at
+`
+
+type CreateWebhookParams struct {
+	UserID    *uuid.UUID
+	OrgID     *uuid.UUID
+	Url       string
+	Secret    string
+	Events    []string
+	CreatedBy uuid.UUID
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, createWebhook,
+		arg.UserID,
+		arg.OrgID,
+		arg.Url,
+		arg.Secret,
+		arg.Events,
+		arg.CreatedBy,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.OrgID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE id = $1
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteWebhook, id)
+	return err
+}
+
+const enqueueWebhookDeliveries = `-- name: EnqueueWebhookDeliveries :exec
+INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+SELECT w.id, $1, $2, $3
+FROM webhooks w
+WHERE (cardinality(w.events) = 0 OR $2::text = ANY(w.events))
+  AND (
+        w.user_id = $4::uuid
+        OR w.user_id = $5::uuid
+        OR w.org_id = $6::uuid
+    )
+`
+
+type EnqueueWebhookDeliveriesParams struct {
+	EventID      uuid.UUID
+	EventType    string
+	Payload      []byte
+	OwnerID      *uuid.UUID
+	TargetUserID *uuid.UUID
+	OrgID        *uuid.UUID
+}
+
+// User webhooks fire for events on resources they own or that target them,
+// org webhooks for every event in the organization
+func (q *Queries) EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) error {
+	_, err := q.db.Exec(ctx, enqueueWebhookDeliveries,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+		arg.OwnerID,
+		arg.TargetUserID,
+		arg.OrgID,
+	)
+	return err
+}
+
+const getOrgWebhooks = `-- name: GetOrgWebhooks :many
+SELECT id, user_id, org_id, url, secret, events, created_by, created_at
+FROM webhooks
+WHERE org_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetOrgWebhooks(ctx context.Context, orgID *uuid.UUID) ([]Webhook, error) {
+	rows, err := q.db.Query(ctx, getOrgWebhooks, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrgID,
+			&i.Url,
+			&i.Secret,
+			&i.Events,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserWebhooks = `-- name: GetUserWebhooks :many
+SELECT id, user_id, org_id, url, secret, events, created_by, created_at
+FROM webhooks
+WHERE user_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetUserWebhooks(ctx context.Context, userID *uuid.UUID) ([]Webhook, error) {
+	rows, err := q.db.Query(ctx, getUserWebhooks, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrgID,
+			&i.Url,
+			&i.Secret,
+			&i.Events,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhook = `-- name: GetWebhook :one
+SELECT id, user_id, org_id, url, secret, events, created_by, created_at
+FROM webhooks
+WHERE id = $1
+`
+
+func (q *Queries) GetWebhook(ctx context.Context, id uuid.UUID) (Webhook, error) {
+	row := q.db.QueryRow(ctx, getWebhook, id)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.OrgID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWebhookDeliveries = `-- name: GetWebhookDeliveries :many
+SELECT id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE webhook_id = $1
+  AND ($2::text IS NULL OR status = $2)
+ORDER BY created_at DESC
+LIMIT 200
+`
+
+type GetWebhookDeliveriesParams struct {
+	WebhookID uuid.UUID
+	Status    *string
+}
+
+func (q *Queries) GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, getWebhookDeliveries, arg.WebhookID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastStatusCode,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markWebhookDelivered = `-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries
+SET
+    status = 'DELIVERED',
+    last_status_code = $2,
+    last_error = NULL,
+    delivered_at = NOW()
+WHERE id = $1
+`
+
+type MarkWebhookDeliveredParams struct {
+	ID             uuid.UUID
+	LastStatusCode *int32
+}
+
+func (q *Queries) MarkWebhookDelivered(ctx context.Context, arg MarkWebhookDeliveredParams) error {
+	_, err := q.db.Exec(ctx, markWebhookDelivered, arg.ID, arg.LastStatusCode)
+	return err
+}
+
+const markWebhookFailed = `-- name: MarkWebhookFailed :exec
+UPDATE webhook_deliveries
+SET
+    status = $1,
+    next_attempt_at = $2,
+    last_status_code = $3,
+    last_error = $4
+WHERE id = $5
+`
+
+type MarkWebhookFailedParams struct {
+	Status         string
+	NextAttemptAt  time.Time
+	LastStatusCode *int32
+	LastError      *string
+	ID             uuid.UUID
+}
+
+func (q *Queries) MarkWebhookFailed(ctx context.Context, arg MarkWebhookFailedParams) error {
+	_, err := q.db.Exec(ctx, markWebhookFailed,
+		arg.Status,
+		arg.NextAttemptAt,
+		arg.LastStatusCode,
+		arg.LastError,
+		arg.ID,
+	)
+	return err
+}
+
+const replayWebhookDelivery = `-- name: ReplayWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET
+    status = 'PENDING',
+    attempts = 0,
+    next_attempt_at = NOW(),
+    last_error = NULL
+WHERE id = $1 AND webhook_id = $2
+  -- A pending delivery may be in flight, resetting it would send it twice
+  AND status <> 'PENDING'
+`
+
+type ReplayWebhookDeliveryParams struct {
+	ID        uuid.UUID
+	WebhookID uuid.UUID
+}
+
+func (q *Queries) ReplayWebhookDelivery(ctx context.Context, arg ReplayWebhookDeliveryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, replayWebhookDelivery, arg.ID, arg.WebhookID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
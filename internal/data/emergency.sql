@@ -0,0 +1,143 @@
+-- name: CreateEmergencyContact :one
+INSERT INTO emergency_contacts (grantor_id, grantee_id, wait_hours)
+VALUES ($1, $2, $3)
+RETURNING id, grantor_id, grantee_id, wait_hours, status, requested_at, released_at, created_at, updated_at;
+
+-- name: GetEmergencyContactForUpdate :one
+SELECT id, grantor_id, grantee_id, wait_hours, status, requested_at, released_at, created_at, updated_at
+FROM emergency_contacts
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetEmergencyContactsByGrantor :many
+SELECT
+    c.id,
+    c.grantor_id,
+    c.grantee_id,
+    c.wait_hours,
+    c.status,
+    c.requested_at,
+    c.released_at,
+    c.created_at,
+    c.updated_at,
+    (SELECT COUNT(*) FROM emergency_keys ek WHERE ek.contact_id = c.id) AS key_count
+FROM emergency_contacts c
+WHERE c.grantor_id = $1
+ORDER BY c.created_at ASC;
+
+-- name: GetEmergencyContactsByGrantee :many
+SELECT
+    c.id,
+    c.grantor_id,
+    c.grantee_id,
+    c.wait_hours,
+    c.status,
+    c.requested_at,
+    c.released_at,
+    c.created_at,
+    c.updated_at,
+    (SELECT COUNT(*) FROM emergency_keys ek WHERE ek.contact_id = c.id) AS key_count
+FROM emergency_contacts c
+WHERE c.grantee_id = $1
+ORDER BY c.created_at ASC;
+
+-- name: DeleteEmergencyContact :execrows
+DELETE FROM emergency_contacts
+WHERE id = $1 AND grantor_id = $2;
+
+-- name: UpsertEmergencyFolderKey :exec
+INSERT INTO emergency_keys (contact_id, folder_id, enc_key, nonce)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (contact_id, folder_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce;
+
+-- name: UpsertEmergencyItemKey :exec
+INSERT INTO emergency_keys (contact_id, item_id, enc_key, nonce)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (contact_id, item_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce;
+
+-- name: SetEmergencyContactStatus :exec
+UPDATE emergency_contacts
+SET
+    status = sqlc.arg(status),
+    requested_at = CASE WHEN sqlc.arg(status)::text = 'REQUESTED' THEN NOW() ELSE requested_at END,
+    released_at = CASE WHEN sqlc.arg(status)::text = 'RELEASED' THEN NOW() ELSE released_at END,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id);
+
+-- name: ReleaseEmergencyFolderKeys :many
+-- Copy the pre-wrapped folder keys into the regular access model as READ
+-- grants, refreshing any grant the contact already holds
+INSERT INTO keys (user_id, folder_id, enc_key, nonce, access_level, granted_by)
+SELECT
+    c.grantee_id,
+    ek.folder_id,
+    ek.enc_key,
+    ek.nonce,
+    'READ',
+    c.grantor_id
+FROM emergency_keys ek
+JOIN emergency_contacts c ON c.id = ek.contact_id
+WHERE c.id = $1
+  AND ek.folder_id IS NOT NULL
+ON CONFLICT (user_id, folder_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    -- A permanent WRITE or OWNER grant is kept, anything else becomes a
+    -- permanent READ so the contact is never left with an expired grant
+    access_level = CASE
+        WHEN keys.expires_at IS NULL AND keys.access_level IN ('WRITE', 'OWNER') THEN keys.access_level
+        ELSE EXCLUDED.access_level
+    END,
+    granted_by = CASE
+        WHEN keys.expires_at IS NULL AND keys.access_level IN ('WRITE', 'OWNER') THEN keys.granted_by
+        ELSE EXCLUDED.granted_by
+    END,
+    expires_at = NULL
+RETURNING folder_id;
+
+-- name: ReleaseEmergencyItemKeys :many
+-- Same as ReleaseEmergencyFolderKeys for item keys
+INSERT INTO keys (user_id, item_id, enc_key, nonce, access_level, granted_by)
+SELECT
+    c.grantee_id,
+    ek.item_id,
+    ek.enc_key,
+    ek.nonce,
+    'READ',
+    c.grantor_id
+FROM emergency_keys ek
+JOIN emergency_contacts c ON c.id = ek.contact_id
+WHERE c.id = $1
+  AND ek.item_id IS NOT NULL
+ON CONFLICT (user_id, item_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    -- A permanent WRITE or OWNER grant is kept, anything else becomes a
+    -- permanent READ so the contact is never left with an expired grant
+    access_level = CASE
+        WHEN keys.expires_at IS NULL AND keys.access_level IN ('WRITE', 'OWNER') THEN keys.access_level
+        ELSE EXCLUDED.access_level
+    END,
+    granted_by = CASE
+        WHEN keys.expires_at IS NULL AND keys.access_level IN ('WRITE', 'OWNER') THEN keys.granted_by
+        ELSE EXCLUDED.granted_by
+    END,
+    expires_at = NULL
+RETURNING item_id;
+
+-- name: ListDueEmergencyRequests :many
+SELECT id
+FROM emergency_contacts
+WHERE status = 'REQUESTED'
+  AND requested_at + make_interval(hours => wait_hours) <= NOW()
+ORDER BY requested_at ASC
+LIMIT 100
+FOR UPDATE SKIP LOCKED;
@@ -0,0 +1,82 @@
+-- name: CreateGroup :one
+INSERT INTO groups (org_id, name, created_by)
+VALUES ($1, $2, $3)
+RETURNING id, org_id, name, created_by, created_at;
+
+-- name: GetGroup :one
+SELECT id, org_id, name, created_by, created_at
+FROM groups
+WHERE id = $1;
+
+-- name: GetOrgGroups :many
+SELECT
+    g.id,
+    g.org_id,
+    g.name,
+    g.created_by,
+    g.created_at,
+    (SELECT COUNT(*) FROM group_members gm WHERE gm.group_id = g.id) AS member_count
+FROM groups g
+WHERE g.org_id = $1
+ORDER BY g.name ASC;
+
+-- name: DeleteGroup :execrows
+DELETE FROM groups
+WHERE id = $1 AND org_id = $2;
+
+-- name: AddGroupMember :exec
+INSERT INTO group_members (group_id, user_id, enc_key, nonce, added_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (group_id, user_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    added_by = EXCLUDED.added_by;
+
+-- name: GetGroupMembers :many
+SELECT user_id, added_by, created_at
+FROM group_members
+WHERE group_id = $1
+ORDER BY created_at ASC;
+
+-- name: RemoveGroupMember :execrows
+DELETE FROM group_members
+WHERE group_id = $1 AND user_id = $2;
+
+-- name: RemoveUserFromOrgGroups :exec
+DELETE FROM group_members gm
+USING groups g
+WHERE gm.group_id = g.id
+  AND g.org_id = $1
+  AND gm.user_id = $2;
+
+-- name: CreateGroupFolderKey :exec
+INSERT INTO group_keys (group_id, folder_id, enc_key, nonce, access_level, granted_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (group_id, folder_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    access_level = EXCLUDED.access_level,
+    granted_by = EXCLUDED.granted_by;
+
+-- name: RevokeGroupFolderKey :execrows
+DELETE FROM group_keys
+WHERE group_id = $1 AND folder_id = $2;
+
+-- name: CreateGroupItemKey :exec
+INSERT INTO group_keys (group_id, item_id, enc_key, nonce, access_level, granted_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (group_id, item_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    access_level = EXCLUDED.access_level,
+    granted_by = EXCLUDED.granted_by;
+
+-- name: RevokeGroupFolderItemKeys :exec
+DELETE FROM group_keys gk
+USING items i
+WHERE gk.item_id = i.id
+  AND gk.group_id = $1
+  AND i.folder_id = $2;
@@ -0,0 +1,69 @@
+-- name: CreateOrganization :one
+INSERT INTO organizations (name, created_by)
+VALUES ($1, $2)
+RETURNING id, name, created_by, created_at, updated_at;
+
+-- name: GetOrganization :one
+SELECT id, name, created_by, created_at, updated_at
+FROM organizations
+WHERE id = $1;
+
+-- name: GetUserOrganizations :many
+SELECT
+    o.id,
+    o.name,
+    o.created_by,
+    o.created_at,
+    o.updated_at,
+    m.role
+FROM organizations o
+JOIN org_members m ON o.id = m.org_id
+WHERE m.user_id = $1
+ORDER BY o.name ASC;
+
+-- name: UpdateOrganization :execrows
+UPDATE organizations
+SET
+    name = $1,
+    updated_at = NOW()
+WHERE id = $2;
+
+-- name: DeleteOrganization :execrows
+DELETE FROM organizations
+WHERE id = $1;
+
+-- name: CountOrgResources :one
+SELECT
+    (SELECT COUNT(*) FROM folders f WHERE f.org_id = $1 AND f.deleted_at IS NULL) +
+    (SELECT COUNT(*) FROM items i WHERE i.org_id = $1 AND i.deleted_at IS NULL) AS total;
+
+-- name: AddOrgMember :execrows
+INSERT INTO org_members (org_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (org_id, user_id) DO NOTHING;
+
+-- name: GetOrgMemberRole :one
+SELECT role
+FROM org_members
+WHERE org_id = $1 AND user_id = $2;
+
+-- name: GetOrgMembers :many
+SELECT user_id, role, created_at
+FROM org_members
+WHERE org_id = $1
+ORDER BY created_at ASC;
+
+-- name: UpdateOrgMemberRole :execrows
+UPDATE org_members
+SET role = $1
+WHERE org_id = $2 AND user_id = $3;
+
+-- name: RemoveOrgMember :execrows
+DELETE FROM org_members
+WHERE org_id = $1 AND user_id = $2;
+
+-- name: LockOrgOwners :many
+SELECT user_id
+FROM org_members
+WHERE org_id = $1 AND role = 'OWNER'
+FOR UPDATE;
@@ -0,0 +1,151 @@
+-- name: CreateProject :one
+INSERT INTO projects (owner_id, org_id, name)
+VALUES ($1, $2, $3)
+RETURNING id, owner_id, org_id, name, created_at, updated_at;
+
+-- name: GetVisibleProjects :many
+-- Owners, members of the owning organization and anyone holding a key to one
+-- of the environments see a project
+SELECT
+    p.id,
+    p.owner_id,
+    p.org_id,
+    p.name,
+    p.created_at,
+    p.updated_at,
+    ARRAY(
+        SELECT e.folder_id FROM environments e WHERE e.project_id = p.id
+    )::uuid[] AS folder_ids
+FROM projects p
+WHERE p.owner_id = sqlc.arg(user_id)
+   OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = p.org_id
+      AND m.user_id = sqlc.arg(user_id)
+  )
+   OR EXISTS (
+    SELECT 1 FROM environments e
+    JOIN folders f ON f.id = e.folder_id
+    JOIN keys k ON k.folder_id = f.id
+    WHERE e.project_id = p.id
+      AND k.user_id = sqlc.arg(user_id)
+      AND f.deleted_at IS NULL
+      AND (k.expires_at IS NULL OR k.expires_at > NOW())
+  )
+ORDER BY p.name ASC;
+
+-- name: GetVisibleProject :one
+SELECT
+    p.id,
+    p.owner_id,
+    p.org_id,
+    p.name,
+    p.created_at,
+    p.updated_at,
+    ARRAY(
+        SELECT e.folder_id FROM environments e WHERE e.project_id = p.id
+    )::uuid[] AS folder_ids
+FROM projects p
+WHERE p.id = sqlc.arg(id)
+  AND (p.owner_id = sqlc.arg(user_id)
+   OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = p.org_id
+      AND m.user_id = sqlc.arg(user_id)
+  )
+   OR EXISTS (
+    SELECT 1 FROM environments e
+    JOIN folders f ON f.id = e.folder_id
+    JOIN keys k ON k.folder_id = f.id
+    WHERE e.project_id = p.id
+      AND k.user_id = sqlc.arg(user_id)
+      AND f.deleted_at IS NULL
+      AND (k.expires_at IS NULL OR k.expires_at > NOW())
+  ));
+
+-- name: GetOwnedProject :one
+-- Admins of the owning organization count as owners of org projects
+SELECT id, owner_id, org_id, name, created_at, updated_at
+FROM projects
+WHERE id = $1
+  AND (owner_id = $2 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = projects.org_id
+      AND m.user_id = $2
+      AND m.role IN ('OWNER', 'ADMIN')
+  ));
+
+-- name: DeleteProject :exec
+DELETE FROM projects
+WHERE id = $1;
+
+-- name: CountProjectEnvironments :one
+SELECT COUNT(*) FROM environments
+WHERE project_id = $1;
+
+-- name: CreateEnvironment :one
+INSERT INTO environments (project_id, folder_id, name)
+VALUES ($1, $2, $3)
+ON CONFLICT (project_id, name) DO NOTHING
+RETURNING id, project_id, folder_id, name, created_at;
+
+-- name: GetProjectEnvironments :many
+-- access_level is NULL for environments the caller holds no key to
+SELECT
+    e.id,
+    e.project_id,
+    e.folder_id,
+    e.name,
+    e.created_at,
+    k.access_level
+FROM environments e
+JOIN folders f ON f.id = e.folder_id
+LEFT JOIN keys k ON k.folder_id = e.folder_id
+    AND k.user_id = $2
+    AND (k.expires_at IS NULL OR k.expires_at > NOW())
+WHERE e.project_id = $1
+  AND f.deleted_at IS NULL
+ORDER BY e.name ASC;
+
+-- name: GetEnvironmentByName :one
+SELECT e.id, e.project_id, e.folder_id, e.name, e.created_at
+FROM environments e
+JOIN folders f ON f.id = e.folder_id
+WHERE e.project_id = $1
+  AND e.name = $2
+  AND f.deleted_at IS NULL;
+
+-- name: DeleteEnvironment :exec
+DELETE FROM environments
+WHERE id = $1 AND project_id = $2;
+
+-- name: GetEnvironment :one
+SELECT id, project_id, folder_id, name, created_at
+FROM environments
+WHERE id = $1 AND project_id = $2;
+
+-- name: GetFolderSecrets :many
+SELECT
+    i.id,
+    i.owner_id,
+    i.enc_overview,
+    i.overview_nonce,
+    i.enc_data,
+    i.nonce AS data_nonce,
+    i.updated_at,
+    k.enc_key AS wrapped_key,
+    k.nonce AS key_nonce
+FROM items i
+JOIN keys k ON i.id = k.item_id
+WHERE i.folder_id = $1
+  AND k.user_id = $2
+  AND i.type = 'SECRET'
+  AND i.deleted_at IS NULL
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+ORDER BY i.created_at ASC;
+
+-- name: SoftDeleteEnvironmentFolder :exec
+-- Ownership is checked on the project, environment folders are removed with it
+UPDATE folders
+SET deleted_at = NOW()
+WHERE id = $1;
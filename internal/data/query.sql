@@ -1,22 +1,69 @@
 -- name: CreateFolder :one
-INSERT INTO folders (owner_id, nonce, enc_metadata)
-VALUES ($1, $2, $3)
+INSERT INTO folders (owner_id, nonce, enc_metadata, org_id)
+VALUES ($1, $2, $3, $4)
 RETURNING id, created_at, updated_at;
 
 -- name: GetUserFolders :many
+-- Direct grants take precedence, group grants fill in folders the user has no key for.
+-- is_owner follows IsFolderOwner, so org admins own org folders
 SELECT
     f.id,
+    f.owner_id,
+    f.org_id,
     f.nonce,
     f.enc_metadata,
+    f.created_at,
     f.updated_at,
+    (f.owner_id = $1 OR EXISTS (
+        SELECT 1 FROM org_members m
+        WHERE m.org_id = f.org_id
+          AND m.user_id = $1
+          AND m.role IN ('OWNER', 'ADMIN')
+    ))::boolean AS is_owner,
     k.enc_key AS wrapped_key,
     k.nonce AS key_nonce,
-    k.access_level
+    k.access_level,
+    NULL::uuid AS group_id,
+    NULL::bytea AS group_key,
+    NULL::bytea AS group_key_nonce
 FROM folders f
 JOIN keys k ON f.id = k.folder_id
 WHERE k.user_id = $1
   AND f.deleted_at IS NULL
-ORDER BY f.created_at ASC;
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+UNION ALL
+SELECT
+    f.id,
+    f.owner_id,
+    f.org_id,
+    f.nonce,
+    f.enc_metadata,
+    f.created_at,
+    f.updated_at,
+    (f.owner_id = $1 OR EXISTS (
+        SELECT 1 FROM org_members m
+        WHERE m.org_id = f.org_id
+          AND m.user_id = $1
+          AND m.role IN ('OWNER', 'ADMIN')
+    ))::boolean AS is_owner,
+    gk.enc_key AS wrapped_key,
+    gk.nonce AS key_nonce,
+    gk.access_level,
+    gk.group_id,
+    gm.enc_key AS group_key,
+    gm.nonce AS group_key_nonce
+FROM folders f
+JOIN group_keys gk ON f.id = gk.folder_id
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE gm.user_id = $1
+  AND f.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys dk
+    WHERE dk.folder_id = f.id
+      AND dk.user_id = $1
+      AND (dk.expires_at IS NULL OR dk.expires_at > NOW())
+  )
+ORDER BY created_at ASC;
 
 -- name: UpdateFolderMetadata :execrows
 UPDATE folders
@@ -24,17 +71,29 @@ SET
     enc_metadata = $1,
     nonce = $2,
     updated_at = NOW()
-WHERE id = $3 AND owner_id = $4;
+WHERE id = $3
+  AND (owner_id = $4 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = folders.org_id
+      AND m.user_id = $4
+      AND m.role IN ('OWNER', 'ADMIN')
+  ));
 
 -- name: SoftDeleteFolder :execrows
 UPDATE folders
 SET deleted_at = NOW()
-WHERE id = $1 AND owner_id = $2;
+WHERE id = $1
+  AND (owner_id = $2 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = folders.org_id
+      AND m.user_id = $2
+      AND m.role IN ('OWNER', 'ADMIN')
+  ));
 
 
 -- name: CreateItem :one
-INSERT INTO items (owner_id, folder_id, type, nonce, enc_data, overview_nonce, enc_overview)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO items (owner_id, folder_id, type, nonce, enc_data, overview_nonce, enc_overview, org_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING id, created_at, updated_at;
 
 -- name: UpdateItemBlob :exec
@@ -48,22 +107,74 @@ SET
 WHERE id = $5;
 
 -- name: GetFolderItems :many
+-- Direct grants take precedence, group grants fill in items the user has no key for.
+-- is_owner follows IsItemOwner, so org admins own org items
 SELECT
     i.id,
+    i.owner_id,
+    i.org_id,
     i.type,
     i.overview_nonce,
     i.enc_overview,
+    i.created_at,
     i.updated_at,
+    (i.owner_id = k.user_id OR EXISTS (
+        SELECT 1 FROM org_members m
+        WHERE m.org_id = i.org_id
+          AND m.user_id = k.user_id
+          AND m.role IN ('OWNER', 'ADMIN')
+    ))::boolean AS is_owner,
     k.enc_key AS wrapped_key,
-    k.nonce AS key_nonce
+    k.nonce AS key_nonce,
+    k.access_level,
+    NULL::uuid AS group_id,
+    NULL::bytea AS group_key,
+    NULL::bytea AS group_key_nonce
 FROM items i
 JOIN keys k ON i.id = k.item_id
 WHERE i.folder_id = $1
   AND k.user_id = $2
   AND i.deleted_at IS NULL
-ORDER BY i.created_at DESC;
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+UNION ALL
+SELECT
+    i.id,
+    i.owner_id,
+    i.org_id,
+    i.type,
+    i.overview_nonce,
+    i.enc_overview,
+    i.created_at,
+    i.updated_at,
+    (i.owner_id = gm.user_id OR EXISTS (
+        SELECT 1 FROM org_members m
+        WHERE m.org_id = i.org_id
+          AND m.user_id = gm.user_id
+          AND m.role IN ('OWNER', 'ADMIN')
+    ))::boolean AS is_owner,
+    gk.enc_key AS wrapped_key,
+    gk.nonce AS key_nonce,
+    gk.access_level,
+    gk.group_id,
+    gm.enc_key AS group_key,
+    gm.nonce AS group_key_nonce
+FROM items i
+JOIN group_keys gk ON i.id = gk.item_id
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE i.folder_id = $1
+  AND gm.user_id = $2
+  AND i.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys dk
+    WHERE dk.item_id = i.id
+      AND dk.user_id = $2
+      AND (dk.expires_at IS NULL OR dk.expires_at > NOW())
+  )
+ORDER BY created_at DESC;
 
 -- name: GetItemData :one
+-- A direct grant takes precedence. Group grants are READ or WRITE, so the
+-- descending order picks WRITE when several groups grant the item
 SELECT
     i.id,
     i.folder_id,
@@ -76,25 +187,80 @@ SELECT
     i.updated_at,
     k.enc_key AS wrapped_key,
     k.nonce AS key_nonce,
-    k.access_level
+    k.access_level,
+    NULL::uuid AS group_id,
+    NULL::bytea AS group_key,
+    NULL::bytea AS group_key_nonce
 FROM items i
 JOIN keys k ON i.id = k.item_id
 WHERE i.id = $1
   AND k.user_id = $2
-  AND i.deleted_at IS NULL;
+  AND i.deleted_at IS NULL
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+UNION ALL
+SELECT
+    i.id,
+    i.folder_id,
+    i.type,
+    i.nonce AS item_nonce,
+    i.enc_data,
+    i.enc_overview,
+    i.overview_nonce,
+    i.created_at,
+    i.updated_at,
+    gk.enc_key AS wrapped_key,
+    gk.nonce AS key_nonce,
+    gk.access_level,
+    gk.group_id,
+    gm.enc_key AS group_key,
+    gm.nonce AS group_key_nonce
+FROM items i
+JOIN group_keys gk ON i.id = gk.item_id
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE i.id = $1
+  AND gm.user_id = $2
+  AND i.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys dk
+    WHERE dk.item_id = i.id
+      AND dk.user_id = $2
+      AND (dk.expires_at IS NULL OR dk.expires_at > NOW())
+  )
+ORDER BY access_level DESC
+LIMIT 1;
 
 -- name: SoftDeleteItem :execrows
 UPDATE items
 SET deleted_at = NOW()
-WHERE id = $1 AND owner_id = $2;
+WHERE id = $1
+  AND (owner_id = $2 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = items.org_id
+      AND m.user_id = $2
+      AND m.role IN ('OWNER', 'ADMIN')
+  ));
 
 -- name: CreateFolderKey :exec
-INSERT INTO keys (user_id, folder_id, enc_key, nonce, access_level)
-VALUES ($1, $2, $3, $4, $5);
+INSERT INTO keys (user_id, folder_id, enc_key, nonce, access_level, granted_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, folder_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    access_level = EXCLUDED.access_level,
+    granted_by = EXCLUDED.granted_by,
+    expires_at = EXCLUDED.expires_at;
 
 -- name: CreateItemKey :exec
-INSERT INTO keys (user_id, item_id, enc_key, nonce, access_level)
-VALUES ($1, $2, $3, $4, $5);
+INSERT INTO keys (user_id, item_id, enc_key, nonce, access_level, granted_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, item_id) DO UPDATE
+SET
+    enc_key = EXCLUDED.enc_key,
+    nonce = EXCLUDED.nonce,
+    access_level = EXCLUDED.access_level,
+    granted_by = EXCLUDED.granted_by,
+    expires_at = EXCLUDED.expires_at;
 
 -- name: RevokeUserAccess :exec
 DELETE FROM keys
@@ -102,9 +268,329 @@ WHERE user_id = $1
   AND (folder_id = $2 OR item_id = $2);
 
 -- name: IsFolderOwner :one
+-- Admins of the owning organization count as owners of org resources
 SELECT 1 FROM folders
-WHERE id = $1 AND owner_id = $2;
+WHERE id = $1
+  AND (owner_id = $2 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = folders.org_id
+      AND m.user_id = $2
+      AND m.role IN ('OWNER', 'ADMIN')
+  ));
 
 -- name: IsItemOwner :one
+-- Admins of the owning organization count as owners of org resources
 SELECT 1 FROM items
-WHERE id = $1 AND owner_id = $2;
+WHERE id = $1
+  AND (owner_id = $2 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = items.org_id
+      AND m.user_id = $2
+      AND m.role IN ('OWNER', 'ADMIN')
+  ));
+
+-- name: GetResourceGrants :many
+-- Group grants are listed once per member, with the group they come through
+SELECT
+    k.user_id,
+    k.access_level,
+    k.created_at,
+    k.granted_by,
+    k.expires_at,
+    NULL::uuid AS group_id
+FROM keys k
+WHERE k.folder_id = sqlc.arg(resource_id) OR k.item_id = sqlc.arg(resource_id)
+UNION ALL
+SELECT
+    gm.user_id,
+    gk.access_level,
+    gk.created_at,
+    gk.granted_by,
+    NULL::timestamptz AS expires_at,
+    gk.group_id
+FROM group_keys gk
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE gk.folder_id = sqlc.arg(resource_id) OR gk.item_id = sqlc.arg(resource_id)
+ORDER BY created_at ASC;
+
+-- name: GetSharedByUser :many
+-- Ownership follows IsFolderOwner and IsItemOwner, so org admins see the org
+-- resources they manage
+SELECT
+    'FOLDER'::text AS resource_type,
+    f.id AS resource_id,
+    k.user_id,
+    k.access_level,
+    k.created_at,
+    k.granted_by,
+    k.expires_at
+FROM keys k
+JOIN folders f ON f.id = k.folder_id
+WHERE (f.owner_id = sqlc.arg(owner_id) OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = f.org_id
+      AND m.user_id = sqlc.arg(owner_id)
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND k.user_id <> sqlc.arg(owner_id)
+  AND f.deleted_at IS NULL
+UNION ALL
+SELECT
+    'ITEM'::text AS resource_type,
+    i.id AS resource_id,
+    k.user_id,
+    k.access_level,
+    k.created_at,
+    k.granted_by,
+    k.expires_at
+FROM keys k
+JOIN items i ON i.id = k.item_id
+WHERE (i.owner_id = sqlc.arg(owner_id) OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = i.org_id
+      AND m.user_id = sqlc.arg(owner_id)
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND k.user_id <> sqlc.arg(owner_id)
+  AND i.deleted_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: LockResourceOwners :many
+SELECT user_id
+FROM keys
+WHERE (folder_id = sqlc.arg(resource_id) OR item_id = sqlc.arg(resource_id))
+  AND access_level = 'OWNER'
+FOR UPDATE;
+
+-- name: UpdateGrantAccessLevel :execrows
+UPDATE keys
+SET access_level = sqlc.arg(access_level)
+WHERE user_id = sqlc.arg(user_id)
+  AND (folder_id = sqlc.arg(resource_id) OR item_id = sqlc.arg(resource_id));
+
+-- name: TransferFolderOwnership :execrows
+UPDATE folders
+SET
+    owner_id = sqlc.arg(new_owner_id),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND (owner_id = sqlc.arg(owner_id) OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = folders.org_id
+      AND m.user_id = sqlc.arg(owner_id)
+      AND m.role IN ('OWNER', 'ADMIN')
+  ));
+
+-- name: TransferItemOwnership :execrows
+UPDATE items
+SET
+    owner_id = sqlc.arg(new_owner_id),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND (owner_id = sqlc.arg(owner_id) OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = items.org_id
+      AND m.user_id = sqlc.arg(owner_id)
+      AND m.role IN ('OWNER', 'ADMIN')
+  ));
+
+-- name: GetGrantAccessLevel :one
+SELECT access_level
+FROM keys
+WHERE user_id = sqlc.arg(user_id)
+  AND (folder_id = sqlc.arg(resource_id) OR item_id = sqlc.arg(resource_id))
+FOR UPDATE;
+
+-- name: GetFolderOwner :one
+SELECT owner_id FROM folders
+WHERE id = $1;
+
+-- name: GetItemOwner :one
+SELECT owner_id FROM items
+WHERE id = $1;
+
+-- name: GetResourceOrgID :one
+SELECT org_id FROM folders WHERE folders.id = sqlc.arg(resource_id)
+UNION ALL
+SELECT org_id FROM items WHERE items.id = sqlc.arg(resource_id)
+LIMIT 1;
+
+-- name: GetResourceEvents :many
+SELECT
+    id,
+    actor_id,
+    action,
+    resource_type,
+    resource_id,
+    target_user_id,
+    created_at
+FROM audit_events
+WHERE resource_id = $1
+ORDER BY created_at DESC
+LIMIT 200;
+
+-- name: GetFoldersSharedWithUser :many
+-- Ownership follows IsFolderOwner, so org resources the user administers are not listed
+-- Direct grants take precedence, group grants fill in folders the user has no key for
+SELECT
+    f.id,
+    f.owner_id,
+    f.nonce,
+    f.enc_metadata,
+    f.updated_at,
+    k.enc_key AS wrapped_key,
+    k.nonce AS key_nonce,
+    k.access_level,
+    k.created_at AS shared_at,
+    NULL::uuid AS group_id,
+    NULL::bytea AS group_key,
+    NULL::bytea AS group_key_nonce
+FROM folders f
+JOIN keys k ON f.id = k.folder_id
+WHERE k.user_id = $1
+  AND NOT (f.owner_id = $1 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = f.org_id
+      AND m.user_id = $1
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND f.deleted_at IS NULL
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+UNION ALL
+SELECT
+    f.id,
+    f.owner_id,
+    f.nonce,
+    f.enc_metadata,
+    f.updated_at,
+    gk.enc_key AS wrapped_key,
+    gk.nonce AS key_nonce,
+    gk.access_level,
+    gk.created_at AS shared_at,
+    gk.group_id,
+    gm.enc_key AS group_key,
+    gm.nonce AS group_key_nonce
+FROM folders f
+JOIN group_keys gk ON f.id = gk.folder_id
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE gm.user_id = $1
+  AND NOT (f.owner_id = $1 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = f.org_id
+      AND m.user_id = $1
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND f.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys dk
+    WHERE dk.folder_id = f.id
+      AND dk.user_id = $1
+      AND (dk.expires_at IS NULL OR dk.expires_at > NOW())
+  )
+ORDER BY shared_at DESC;
+
+-- name: GetItemsSharedWithUser :many
+-- Ownership follows IsItemOwner, so org resources the user administers are not listed
+-- Direct grants take precedence, group grants fill in items the user has no key for
+SELECT
+    i.id,
+    i.owner_id,
+    i.folder_id,
+    i.type,
+    i.overview_nonce,
+    i.enc_overview,
+    i.updated_at,
+    k.enc_key AS wrapped_key,
+    k.nonce AS key_nonce,
+    k.access_level,
+    k.created_at AS shared_at,
+    NULL::uuid AS group_id,
+    NULL::bytea AS group_key,
+    NULL::bytea AS group_key_nonce
+FROM items i
+JOIN keys k ON i.id = k.item_id
+WHERE k.user_id = $1
+  AND NOT (i.owner_id = $1 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = i.org_id
+      AND m.user_id = $1
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND i.deleted_at IS NULL
+  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+UNION ALL
+SELECT
+    i.id,
+    i.owner_id,
+    i.folder_id,
+    i.type,
+    i.overview_nonce,
+    i.enc_overview,
+    i.updated_at,
+    gk.enc_key AS wrapped_key,
+    gk.nonce AS key_nonce,
+    gk.access_level,
+    gk.created_at AS shared_at,
+    gk.group_id,
+    gm.enc_key AS group_key,
+    gm.nonce AS group_key_nonce
+FROM items i
+JOIN group_keys gk ON i.id = gk.item_id
+JOIN group_members gm ON gk.group_id = gm.group_id
+WHERE gm.user_id = $1
+  AND NOT (i.owner_id = $1 OR EXISTS (
+    SELECT 1 FROM org_members m
+    WHERE m.org_id = i.org_id
+      AND m.user_id = $1
+      AND m.role IN ('OWNER', 'ADMIN')
+  ))
+  AND i.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM keys dk
+    WHERE dk.item_id = i.id
+      AND dk.user_id = $1
+      AND (dk.expires_at IS NULL OR dk.expires_at > NOW())
+  )
+ORDER BY shared_at DESC;
+
+-- name: ListAuditEvents :many
+-- Events on resources the user owns, plus everything in organizations they administer
+SELECT
+    e.id,
+    e.actor_id,
+    e.action,
+    e.resource_type,
+    e.resource_id,
+    e.owner_id,
+    e.org_id,
+    e.target_user_id,
+    e.ip,
+    e.user_agent,
+    e.created_at
+FROM audit_events e
+WHERE (
+        e.owner_id = sqlc.arg(user_id)::uuid
+        OR e.org_id IN (
+            SELECT m.org_id FROM org_members m
+            WHERE m.user_id = sqlc.arg(user_id) AND m.role IN ('OWNER', 'ADMIN')
+        )
+    )
+    AND (sqlc.narg(since)::timestamptz IS NULL OR e.created_at >= sqlc.narg(since))
+    AND (sqlc.narg(until)::timestamptz IS NULL OR e.created_at < sqlc.narg(until))
+    AND (sqlc.narg(actor_id)::uuid IS NULL OR e.actor_id = sqlc.narg(actor_id))
+    AND (sqlc.narg(resource_id)::uuid IS NULL OR e.resource_id = sqlc.narg(resource_id))
+    AND (sqlc.narg(resource_type)::text IS NULL OR e.resource_type = sqlc.narg(resource_type))
+    AND (sqlc.narg(action)::text IS NULL OR e.action = sqlc.narg(action))
+ORDER BY e.created_at DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: CountVaultTotals :one
+SELECT
+    (SELECT COUNT(*) FROM folders)::bigint AS folders,
+    (SELECT COUNT(*) FROM items)::bigint AS items,
+    (SELECT COUNT(*) FROM organizations)::bigint AS organizations;
+
+-- name: GetItemFolderID :one
+SELECT folder_id
+FROM items
+WHERE id = $1;
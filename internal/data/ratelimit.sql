@@ -0,0 +1,20 @@
+-- name: TakeRateLimitToken :one
+-- Refills the bucket for the time since its last update and takes one token.
+-- Returns no row when the bucket has less than one token, leaving it as is
+INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at)
+VALUES (sqlc.arg(key), sqlc.arg(burst)::float8 - 1, NOW())
+ON CONFLICT (key) DO UPDATE
+SET
+    tokens = LEAST(sqlc.arg(burst)::float8, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at)::float8 * sqlc.arg(rate)::float8) - 1,
+    updated_at = NOW()
+WHERE LEAST(sqlc.arg(burst)::float8, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at)::float8 * sqlc.arg(rate)::float8) >= 1
+RETURNING tokens;
+
+-- name: GetRateLimitTokens :one
+SELECT LEAST(sqlc.arg(burst)::float8, tokens + EXTRACT(EPOCH FROM NOW() - updated_at)::float8 * sqlc.arg(rate)::float8)::float8 AS tokens
+FROM rate_limit_buckets
+WHERE key = sqlc.arg(key);
+
+-- name: DeleteIdleRateLimitBuckets :execrows
+DELETE FROM rate_limit_buckets
+WHERE updated_at < sqlc.arg(idle_before);
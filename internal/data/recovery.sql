@@ -0,0 +1,94 @@
+-- name: UpsertRecoveryPolicy :exec
+INSERT INTO org_recovery_policies (org_id, public_key, updated_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (org_id) DO UPDATE
+SET
+    public_key = EXCLUDED.public_key,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = NOW();
+
+-- name: GetRecoveryPolicy :one
+SELECT org_id, public_key, updated_by, created_at, updated_at
+FROM org_recovery_policies
+WHERE org_id = $1;
+
+-- name: DeleteRecoveryPolicy :execrows
+DELETE FROM org_recovery_policies
+WHERE org_id = $1;
+
+-- name: DeleteOrgRecoveryEnrollments :exec
+-- Enrollments are wrapped with the policy key, so they go away with it
+DELETE FROM recovery_enrollments
+WHERE org_id = $1;
+
+-- name: UpsertRecoveryEnrollment :exec
+INSERT INTO recovery_enrollments (org_id, user_id, wrapped_keyring)
+VALUES ($1, $2, $3)
+ON CONFLICT (org_id, user_id) DO UPDATE
+SET
+    wrapped_keyring = EXCLUDED.wrapped_keyring,
+    updated_at = NOW();
+
+-- name: GetRecoveryEnrollment :one
+SELECT org_id, user_id, wrapped_keyring, created_at, updated_at
+FROM recovery_enrollments
+WHERE org_id = $1 AND user_id = $2;
+
+-- name: GetOrgRecoveryEnrollments :many
+SELECT user_id, created_at, updated_at
+FROM recovery_enrollments
+WHERE org_id = $1
+ORDER BY created_at ASC;
+
+-- name: DeleteRecoveryEnrollment :execrows
+DELETE FROM recovery_enrollments
+WHERE org_id = $1 AND user_id = $2;
+
+-- name: CreateRecoveryRequest :one
+INSERT INTO recovery_requests (org_id, user_id, device_public_key, initiated_by)
+VALUES ($1, $2, $3, $4)
+RETURNING id, org_id, user_id, device_public_key, recovered_keyring, status, initiated_by, approved_by, created_at, approved_at, completed_at;
+
+-- name: GetRecoveryRequest :one
+SELECT id, org_id, user_id, device_public_key, recovered_keyring, status, initiated_by, approved_by, created_at, approved_at, completed_at
+FROM recovery_requests
+WHERE id = $1 AND org_id = $2;
+
+-- name: GetRecoveryRequestForUpdate :one
+SELECT id, org_id, user_id, device_public_key, recovered_keyring, status, initiated_by, approved_by, created_at, approved_at, completed_at
+FROM recovery_requests
+WHERE id = $1 AND org_id = $2
+FOR UPDATE;
+
+-- name: GetOrgRecoveryRequests :many
+SELECT id, org_id, user_id, device_public_key, recovered_keyring, status, initiated_by, approved_by, created_at, approved_at, completed_at
+FROM recovery_requests
+WHERE org_id = $1
+ORDER BY created_at DESC
+LIMIT 200;
+
+-- name: ApproveRecoveryRequest :exec
+UPDATE recovery_requests
+SET
+    status = 'APPROVED',
+    approved_by = $2,
+    approved_at = NOW()
+WHERE id = $1;
+
+-- name: CompleteRecoveryRequest :exec
+UPDATE recovery_requests
+SET
+    status = 'COMPLETED',
+    recovered_keyring = $2,
+    completed_at = NOW()
+WHERE id = $1;
+
+-- name: CancelRecoveryRequest :exec
+UPDATE recovery_requests
+SET status = 'CANCELLED'
+WHERE id = $1;
+
+-- name: CancelOpenRecoveryRequests :exec
+UPDATE recovery_requests
+SET status = 'CANCELLED'
+WHERE org_id = $1 AND status IN ('PENDING', 'APPROVED');
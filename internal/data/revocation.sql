@@ -0,0 +1,28 @@
+-- name: RevokeToken :exec
+INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: IsTokenRevoked :one
+SELECT EXISTS (
+    SELECT 1 FROM revoked_tokens WHERE jti = $1
+) AS revoked;
+
+-- name: RevokeSessions :exec
+-- Never moves the marker back, an older revocation must not revive tokens
+INSERT INTO session_revocations (user_id, revoked_before, revoked_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET
+    revoked_before = GREATEST(session_revocations.revoked_before, EXCLUDED.revoked_before),
+    revoked_by = EXCLUDED.revoked_by,
+    updated_at = NOW();
+
+-- name: GetSessionsRevokedBefore :one
+SELECT revoked_before
+FROM session_revocations
+WHERE user_id = $1;
+
+-- name: DeleteExpiredRevokedTokens :execrows
+DELETE FROM revoked_tokens
+WHERE expires_at < NOW();
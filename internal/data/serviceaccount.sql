@@ -0,0 +1,55 @@
+-- name: CreateServiceAccount :one
+INSERT INTO service_accounts (user_id, org_id, name, public_key, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, org_id, name, public_key, created_by, created_at;
+
+-- name: GetServiceAccount :one
+SELECT id, user_id, org_id, name, public_key, created_by, created_at
+FROM service_accounts
+WHERE id = $1;
+
+-- name: GetUserServiceAccounts :many
+SELECT id, user_id, org_id, name, public_key, created_by, created_at
+FROM service_accounts
+WHERE user_id = $1
+ORDER BY created_at ASC;
+
+-- name: GetOrgServiceAccounts :many
+SELECT id, user_id, org_id, name, public_key, created_by, created_at
+FROM service_accounts
+WHERE org_id = $1
+ORDER BY created_at ASC;
+
+-- name: DeleteServiceAccount :exec
+DELETE FROM service_accounts
+WHERE id = $1;
+
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (service_account_id, name, token_hash, token_prefix, read_only, folder_ids, item_ids, expires_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, service_account_id, name, token_prefix, read_only, folder_ids, item_ids, expires_at, revoked_at, last_used_at, created_by, created_at;
+
+-- name: GetServiceAccountTokens :many
+SELECT id, service_account_id, name, token_prefix, read_only, folder_ids, item_ids, expires_at, revoked_at, last_used_at, created_by, created_at
+FROM api_tokens
+WHERE service_account_id = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeAPIToken :execrows
+UPDATE api_tokens
+SET revoked_at = NOW()
+WHERE id = $1 AND service_account_id = $2 AND revoked_at IS NULL;
+
+-- name: GetActiveAPIToken :one
+SELECT id, service_account_id, read_only, folder_ids, item_ids, expires_at
+FROM api_tokens
+WHERE token_hash = $1
+  AND revoked_at IS NULL
+  AND expires_at > NOW();
+
+-- name: TouchAPIToken :exec
+-- Only written once a minute to keep authentication cheap
+UPDATE api_tokens
+SET last_used_at = NOW()
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
@@ -0,0 +1,93 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (user_id, org_id, url, secret, events, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, org_id, url, secret, events, created_by, created_at;
+
+-- name: GetWebhook :one
+SELECT id, user_id, org_id, url, secret, events, created_by, created_at
+FROM webhooks
+WHERE id = $1;
+
+-- name: GetUserWebhooks :many
+SELECT id, user_id, org_id, url, secret, events, created_by, created_at
+FROM webhooks
+WHERE user_id = $1
+ORDER BY created_at ASC;
+
+-- name: GetOrgWebhooks :many
+SELECT id, user_id, org_id, url, secret, events, created_by, created_at
+FROM webhooks
+WHERE org_id = $1
+ORDER BY created_at ASC;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE id = $1;
+
+-- name: EnqueueWebhookDeliveries :exec
+-- User webhooks fire for events on resources they own or that target them,
+-- org webhooks for every event in the organization
+INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+SELECT w.id, sqlc.arg(event_id), sqlc.arg(event_type), sqlc.arg(payload)
+FROM webhooks w
+WHERE (cardinality(w.events) = 0 OR sqlc.arg(event_type)::text = ANY(w.events))
+  AND (
+        w.user_id = sqlc.narg(owner_id)::uuid
+        OR w.user_id = sqlc.narg(target_user_id)::uuid
+        OR w.org_id = sqlc.narg(org_id)::uuid
+    );
+
+-- name: ClaimWebhookDeliveries :many
+-- Leases due deliveries by pushing next_attempt_at forward, so a crashed
+-- worker's deliveries are retried once the lease runs out
+UPDATE webhook_deliveries d
+SET
+    attempts = d.attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::int)
+FROM webhooks w
+WHERE w.id = d.webhook_id
+  AND d.id IN (
+        SELECT due.id FROM webhook_deliveries due
+        WHERE due.status = 'PENDING' AND due.next_attempt_at <= NOW()
+        ORDER BY due.next_attempt_at ASC
+        LIMIT sqlc.arg(batch_size)
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING d.id, d.event_type, d.payload, d.attempts, w.url, w.secret;
+
+-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries
+SET
+    status = 'DELIVERED',
+    last_status_code = $2,
+    last_error = NULL,
+    delivered_at = NOW()
+WHERE id = $1;
+
+-- name: MarkWebhookFailed :exec
+UPDATE webhook_deliveries
+SET
+    status = sqlc.arg(status),
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    last_status_code = sqlc.narg(last_status_code),
+    last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id);
+
+-- name: GetWebhookDeliveries :many
+SELECT id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE webhook_id = sqlc.arg(webhook_id)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+ORDER BY created_at DESC
+LIMIT 200;
+
+-- name: ReplayWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET
+    status = 'PENDING',
+    attempts = 0,
+    next_attempt_at = NOW(),
+    last_error = NULL
+WHERE id = $1 AND webhook_id = $2
+  -- A pending delivery may be in flight, resetting it would send it twice
+  AND status <> 'PENDING';
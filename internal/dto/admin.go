@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type VaultStats struct {
+	Folders        int64 `json:"folders"`
+	Items          int64 `json:"items"`
+	TrashedFolders int64 `json:"trashed_folders"`
+	TrashedItems   int64 `json:"trashed_items"`
+	Grants         int64 `json:"grants"`
+	// Users counts distinct key holders, service accounts included
+	Users                    int64 `json:"users"`
+	Organizations            int64 `json:"organizations"`
+	Groups                   int64 `json:"groups"`
+	ServiceAccounts          int64 `json:"service_accounts"`
+	ActiveAPITokens          int64 `json:"active_api_tokens"`
+	Projects                 int64 `json:"projects"`
+	AuditEvents              int64 `json:"audit_events"`
+	PendingWebhookDeliveries int64 `json:"pending_webhook_deliveries"`
+	DeadWebhookDeliveries    int64 `json:"dead_webhook_deliveries"`
+}
+
+type PurgeResult struct {
+	Before  time.Time `json:"before"`
+	Items   int64     `json:"items"`
+	Folders int64     `json:"folders"`
+}
+
+type RevokeAllResult struct {
+	UserID uuid.UUID `json:"user_id"`
+	Grants int       `json:"grants"`
+	Groups int64     `json:"groups"`
+}
+
+type ExportedItem struct {
+	ID            uuid.UUID  `json:"id"`
+	FolderID      *uuid.UUID `json:"folder_id"`
+	OrgID         *uuid.UUID `json:"org_id"`
+	Type          string     `json:"type"`
+	EncData       []byte     `json:"enc_data"`
+	DataNonce     []byte     `json:"data_nonce"`
+	EncOverview   []byte     `json:"enc_overview"`
+	OverviewNonce []byte     `json:"overview_nonce"`
+	WrappedKey    []byte     `json:"wrapped_key"`
+	KeyNonce      []byte     `json:"key_nonce"`
+	AccessLevel   string     `json:"access_level"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// UserExport holds everything a user can decrypt, still encrypted. Only the
+// user's own keys can open it.
+type UserExport struct {
+	UserID     uuid.UUID       `json:"user_id"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Folders    []FolderSummary `json:"folders"`
+	Items      []ExportedItem  `json:"items"`
+}
+
+type FsckCheck struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	IDs         []uuid.UUID `json:"ids"`
+}
+
+type FsckReport struct {
+	Checks []FsckCheck `json:"checks"`
+	// Problems is the number of ids across all checks
+	Problems int `json:"problems"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Emergency access moves ACTIVE -> REQUESTED -> RELEASED, or REQUESTED ->
+// REJECTED if the grantor objects during the waiting period. A rejected
+// contact may request again.
+const (
+	EmergencyActive    = "ACTIVE"
+	EmergencyRequested = "REQUESTED"
+	EmergencyReleased  = "RELEASED"
+	EmergencyRejected  = "REJECTED"
+)
+
+type CreateEmergencyContactReq struct {
+	GranteeID uuid.UUID `json:"grantee_id" binding:"required"`
+	// WaitHours defaults to the server's configured waiting period
+	WaitHours *int `json:"wait_hours" binding:"omitempty,min=1,max=2160"`
+}
+
+type EmergencyKey struct {
+	ResourceType ResourceType `json:"resource_type" binding:"required,oneof=FOLDER ITEM"`
+	ResourceID   uuid.UUID    `json:"resource_id" binding:"required"`
+
+	// The resource key wrapped for the emergency contact
+	EncKey   []byte `json:"enc_key" binding:"required"`
+	KeyNonce []byte `json:"key_nonce" binding:"required"`
+}
+
+type SetEmergencyKeysReq struct {
+	Keys []EmergencyKey `json:"keys" binding:"required,min=1,dive"`
+}
+
+type EmergencyContact struct {
+	ID          uuid.UUID  `json:"id"`
+	GrantorID   uuid.UUID  `json:"grantor_id"`
+	GranteeID   uuid.UUID  `json:"grantee_id"`
+	WaitHours   int32      `json:"wait_hours"`
+	Status      string     `json:"status"`
+	RequestedAt *time.Time `json:"requested_at"`
+	// ReleasesAt is when a pending request is released automatically
+	ReleasesAt *time.Time `json:"releases_at"`
+	ReleasedAt *time.Time `json:"released_at"`
+	KeyCount   int64      `json:"key_count"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type EmergencyContacts struct {
+	AsGrantor []EmergencyContact `json:"as_grantor"`
+	AsGrantee []EmergencyContact `json:"as_grantee"`
+}
@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventCreated              = "CREATED"
+	EventRead                 = "READ"
+	EventUpdated              = "UPDATED"
+	EventDeleted              = "DELETED"
+	EventShared               = "SHARED"
+	EventRevoked              = "REVOKED"
+	EventGrantUpdated         = "GRANT_UPDATED"
+	EventOwnershipTransferred = "OWNERSHIP_TRANSFERRED"
+	EventGroupShared          = "GROUP_SHARED"
+	EventGroupRevoked         = "GROUP_REVOKED"
+	EventShareLeft            = "SHARE_LEFT"
+
+	EventRecoveryPolicySet      = "RECOVERY_POLICY_SET"
+	EventRecoveryPolicyDisabled = "RECOVERY_POLICY_DISABLED"
+	EventRecoveryEnrolled       = "RECOVERY_ENROLLED"
+	EventRecoveryUnenrolled     = "RECOVERY_UNENROLLED"
+	EventRecoveryInitiated      = "RECOVERY_INITIATED"
+	EventRecoveryApproved       = "RECOVERY_APPROVED"
+	EventRecoveryKeyringRead    = "RECOVERY_KEYRING_READ"
+	EventRecoveryCompleted      = "RECOVERY_COMPLETED"
+	EventRecoveryCancelled      = "RECOVERY_CANCELLED"
+
+	EventSessionRevoked  = "SESSION_REVOKED"
+	EventSessionsRevoked = "SESSIONS_REVOKED"
+
+	EventEmergencyRequested = "EMERGENCY_REQUESTED"
+	EventEmergencyApproved  = "EMERGENCY_APPROVED"
+	EventEmergencyRejected  = "EMERGENCY_REJECTED"
+	EventEmergencyReleased  = "EMERGENCY_RELEASED"
+)
+
+type Event struct {
+	ID           uuid.UUID     `json:"id"`
+	ActorID      uuid.UUID     `json:"actor_id"`
+	Action       string        `json:"action"`
+	ResourceType *ResourceType `json:"resource_type"`
+	ResourceID   *uuid.UUID    `json:"resource_id"`
+	TargetUserID *uuid.UUID    `json:"target_user_id"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// AuditEvent is an Event with the request details, returned by the audit log.
+type AuditEvent struct {
+	Event
+	OwnerID   *uuid.UUID `json:"owner_id"`
+	OrgID     *uuid.UUID `json:"org_id"`
+	IP        *string    `json:"ip"`
+	UserAgent *string    `json:"user_agent"`
+}
+
+type AuditQuery struct {
+	Since        *time.Time `form:"since"`
+	Until        *time.Time `form:"until"`
+	ActorID      string     `form:"actor_id" binding:"omitempty,uuid"`
+	ResourceID   string     `form:"resource_id" binding:"omitempty,uuid"`
+	ResourceType string     `form:"resource_type" binding:"omitempty,oneof=FOLDER ITEM ORGANIZATION"`
+	Action       string     `form:"action" binding:"omitempty,max=50"`
+	// Limit defaults to 100
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+type AuditChainBreak struct {
+	Seq int64 `json:"seq"`
+	// EventID is set instead of Seq for events that are not chained
+	EventID *uuid.UUID `json:"event_id,omitempty"`
+	Reason  string     `json:"reason"`
+}
+
+// AuditVerifyReport is the result of walking the audit hash chain.
+type AuditVerifyReport struct {
+	Checked int64 `json:"checked"`
+	// Unchained counts events written before chaining was introduced
+	Unchained   int64             `json:"unchained"`
+	Checkpoints int               `json:"checkpoints"`
+	Breaks      []AuditChainBreak `json:"breaks"`
+}
@@ -5,6 +5,9 @@ import (
 )
 
 type CreateFolderReq struct {
+	// OrgID optionally places the folder under an organization the caller belongs to
+	OrgID *uuid.UUID `json:"org_id"`
+
 	EncMetadata []byte `json:"enc_metadata" binding:"required"`
 	NameNonce   []byte `json:"nonce" binding:"required"`
 
@@ -22,10 +25,19 @@ type UpdateFolderReq struct {
 }
 
 type FolderSummary struct {
-	ID          uuid.UUID `json:"id"`
-	EncMetadata []byte    `json:"enc_metadata"`
-	Nonce       []byte    `json:"nonce"`
+	ID          uuid.UUID  `json:"id"`
+	OrgID       *uuid.UUID `json:"org_id"`
+	EncMetadata []byte     `json:"enc_metadata"`
+	Nonce       []byte     `json:"nonce"`
+
+	WrappedKey  []byte `json:"wrapped_key"`
+	KeyNonce    []byte `json:"key_nonce"`
+	AccessLevel string `json:"access_level"`
+	IsOwner     bool   `json:"is_owner"`
 
-	WrappedKey []byte `json:"wrapped_key"`
-	KeyNonce   []byte `json:"key_nonce"`
+	// Set when access comes through a group. WrappedKey is then wrapped with
+	// the group key, which is itself wrapped for the user in GroupKey.
+	GroupID       *uuid.UUID `json:"group_id,omitempty"`
+	GroupKey      []byte     `json:"group_key,omitempty"`
+	GroupKeyNonce []byte     `json:"group_key_nonce,omitempty"`
 }
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Grant struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	AccessLevel string     `json:"access_level"`
+	CreatedAt   time.Time  `json:"created_at"`
+	GrantedBy   *uuid.UUID `json:"granted_by"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+
+	// Set when the grant comes through a group the user is a member of
+	GroupID *uuid.UUID `json:"group_id,omitempty"`
+}
+
+type SharedGrant struct {
+	ResourceID   uuid.UUID    `json:"resource_id"`
+	ResourceType ResourceType `json:"resource_type"`
+	Grant
+}
+
+type UpdateGrantReq struct {
+	AccessLevel string `json:"access_level" binding:"required,oneof=READ WRITE OWNER"`
+}
+
+type TransferOwnershipReq struct {
+	TargetUserID uuid.UUID `json:"target_user_id" binding:"required"`
+	// DemoteTo optionally lowers the former owner's grant. Empty keeps it at OWNER.
+	DemoteTo string `json:"demote_to" binding:"omitempty,oneof=READ WRITE"`
+}
+
+type SharedFolder struct {
+	FolderSummary
+	OwnerID  uuid.UUID `json:"owner_id"`
+	SharedAt time.Time `json:"shared_at"`
+}
+
+type SharedItem struct {
+	ItemSummary
+	OwnerID  uuid.UUID  `json:"owner_id"`
+	FolderID *uuid.UUID `json:"folder_id"`
+	SharedAt time.Time  `json:"shared_at"`
+}
+
+type SharedWithMe struct {
+	Folders []SharedFolder `json:"folders"`
+	Items   []SharedItem   `json:"items"`
+}
@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateGroupReq struct {
+	Name string `json:"name" binding:"required,max=255"`
+
+	// The new group key wrapped for the creator, who becomes its first member
+	EncKey   []byte `json:"enc_key" binding:"required"`
+	KeyNonce []byte `json:"key_nonce" binding:"required"`
+}
+
+type GroupResponse struct {
+	ID          uuid.UUID `json:"id"`
+	OrgID       uuid.UUID `json:"org_id"`
+	Name        string    `json:"name"`
+	CreatedBy   uuid.UUID `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	MemberCount int64     `json:"member_count"`
+}
+
+type GroupMember struct {
+	UserID    uuid.UUID `json:"user_id"`
+	AddedBy   uuid.UUID `json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AddGroupMemberReq struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+
+	// The group key wrapped for the new member
+	EncKey   []byte `json:"enc_key" binding:"required"`
+	KeyNonce []byte `json:"key_nonce" binding:"required"`
+}
+
+type GroupShareReq struct {
+	GroupID  uuid.UUID `json:"group_id" binding:"required"`
+	FolderID uuid.UUID `json:"folder_id" binding:"required"`
+
+	// The folder key wrapped with the group key
+	EncKey      []byte `json:"enc_key" binding:"required"`
+	KeyNonce    []byte `json:"key_nonce" binding:"required"`
+	AccessLevel string `json:"access_level" binding:"required,oneof=READ WRITE"`
+
+	// Keys of items in the folder wrapped with the group key. Items are keyed
+	// one by one, so members can only open the items listed here
+	Items []GroupItemKey `json:"items" binding:"omitempty,dive"`
+}
+
+type GroupItemKey struct {
+	ItemID   uuid.UUID `json:"item_id" binding:"required"`
+	EncKey   []byte    `json:"enc_key" binding:"required"`
+	KeyNonce []byte    `json:"key_nonce" binding:"required"`
+}
+
+type GroupRevokeReq struct {
+	GroupID  uuid.UUID `json:"group_id" binding:"required"`
+	FolderID uuid.UUID `json:"folder_id" binding:"required"`
+}
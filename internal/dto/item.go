@@ -7,8 +7,9 @@ import (
 )
 
 type CreateItemReq struct {
-	FolderID uuid.UUID `json:"folder_id" binding:"required"`
-	Type     string    `json:"type" binding:"required"`
+	FolderID uuid.UUID  `json:"folder_id" binding:"required"`
+	OrgID    *uuid.UUID `json:"org_id"`
+	Type     string     `json:"type" binding:"required"`
 
 	EncData       []byte `json:"enc_data" binding:"required"`
 	EncOverview   []byte `json:"enc_overview"`
@@ -31,13 +32,21 @@ type ItemResponse struct {
 }
 
 type ItemSummary struct {
-	ID            uuid.UUID `json:"id"`
-	Type          string    `json:"type"`
-	EncOverview   []byte    `json:"enc_overview"`
-	OverviewNonce []byte    `json:"overview_nonce"`
-	WrappedKey    []byte    `json:"wrapped_key"`
-	KeyNonce      []byte    `json:"key_nonce"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            uuid.UUID  `json:"id"`
+	OrgID         *uuid.UUID `json:"org_id"`
+	Type          string     `json:"type"`
+	EncOverview   []byte     `json:"enc_overview"`
+	OverviewNonce []byte     `json:"overview_nonce"`
+	WrappedKey    []byte     `json:"wrapped_key"`
+	KeyNonce      []byte     `json:"key_nonce"`
+	AccessLevel   string     `json:"access_level"`
+	IsOwner       bool       `json:"is_owner"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Set when access comes through a group, as in FolderSummary
+	GroupID       *uuid.UUID `json:"group_id,omitempty"`
+	GroupKey      []byte     `json:"group_key,omitempty"`
+	GroupKeyNonce []byte     `json:"group_key_nonce,omitempty"`
 }
 
 type ItemDetail struct {
@@ -49,4 +58,9 @@ type ItemDetail struct {
 	WrappedKey []byte     `json:"wrapped_key"`
 	KeyNonce   []byte     `json:"key_nonce"`
 	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Set when access comes through a group, as in FolderSummary
+	GroupID       *uuid.UUID `json:"group_id,omitempty"`
+	GroupKey      []byte     `json:"group_key,omitempty"`
+	GroupKeyNonce []byte     `json:"group_key_nonce,omitempty"`
 }
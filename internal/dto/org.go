@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OrgRoleOwner  = "OWNER"
+	OrgRoleAdmin  = "ADMIN"
+	OrgRoleMember = "MEMBER"
+)
+
+type CreateOrgReq struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type UpdateOrgReq struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type OrgResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy uuid.UUID `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Role is the caller's role in the organization
+	Role string `json:"role"`
+}
+
+type OrgMember struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AddOrgMemberReq struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Role   string    `json:"role" binding:"required,oneof=OWNER ADMIN MEMBER"`
+}
+
+type UpdateOrgMemberReq struct {
+	Role string `json:"role" binding:"required,oneof=OWNER ADMIN MEMBER"`
+}
@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ItemTypeSecret marks items holding one key/value secret of an environment.
+// The key name is in the encrypted overview and the value in the encrypted data.
+const ItemTypeSecret = "SECRET"
+
+type CreateProjectReq struct {
+	Name string `json:"name" binding:"required,max=255"`
+	// OrgID optionally places the project under an organization the caller belongs to
+	OrgID *uuid.UUID `json:"org_id"`
+}
+
+type Project struct {
+	ID        uuid.UUID  `json:"id"`
+	OrgID     *uuid.UUID `json:"org_id"`
+	Name      string     `json:"name"`
+	IsOwner   bool       `json:"is_owner"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// CreateEnvironmentReq creates the environment together with the folder that
+// stores its secrets, encrypted the same way as CreateFolderReq.
+type CreateEnvironmentReq struct {
+	Name string `json:"name" binding:"required,max=64"`
+
+	EncMetadata []byte `json:"enc_metadata" binding:"required"`
+	NameNonce   []byte `json:"nonce" binding:"required"`
+
+	EncKey   []byte `json:"enc_key" binding:"required"`
+	KeyNonce []byte `json:"key_nonce" binding:"required"`
+}
+
+type Environment struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	// FolderID is where secrets are created as items of type SECRET and
+	// what environments are shared and scoped by
+	FolderID uuid.UUID `json:"folder_id"`
+	Name     string    `json:"name"`
+	// AccessLevel is empty when the caller holds no key to the environment
+	AccessLevel string    `json:"access_level,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Secret struct {
+	ID            uuid.UUID `json:"id"`
+	EncOverview   []byte    `json:"enc_overview"`
+	OverviewNonce []byte    `json:"overview_nonce"`
+	EncData       []byte    `json:"enc_data"`
+	DataNonce     []byte    `json:"data_nonce"`
+	WrappedKey    []byte    `json:"wrapped_key"`
+	KeyNonce      []byte    `json:"key_nonce"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type EnvironmentSecrets struct {
+	Environment Environment `json:"environment"`
+	Secrets     []Secret    `json:"secrets"`
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A recovery request moves PENDING -> APPROVED -> COMPLETED. The approving
+// admin must differ from the one who started it. Open requests may be
+// CANCELLED.
+const (
+	RecoveryPending   = "PENDING"
+	RecoveryApproved  = "APPROVED"
+	RecoveryCompleted = "COMPLETED"
+	RecoveryCancelled = "CANCELLED"
+)
+
+type SetRecoveryPolicyReq struct {
+	// PublicKey is the org recovery public key, the private half never reaches the server
+	PublicKey []byte `json:"public_key" binding:"required"`
+}
+
+type RecoveryPolicy struct {
+	OrgID     uuid.UUID `json:"org_id"`
+	PublicKey []byte    `json:"public_key"`
+	UpdatedBy uuid.UUID `json:"updated_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Enrolled reports whether the caller has uploaded a wrapped keyring
+	Enrolled bool `json:"enrolled"`
+}
+
+type RecoveryEnrollReq struct {
+	// The member's keyring wrapped with the org recovery public key
+	WrappedKeyring []byte `json:"wrapped_keyring" binding:"required"`
+}
+
+type RecoveryEnrollment struct {
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateRecoveryReq struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	// Public key of the member's re-enrolled device
+	DevicePublicKey []byte `json:"device_public_key" binding:"required"`
+}
+
+type CompleteRecoveryReq struct {
+	// The keyring re-wrapped with the device public key
+	RecoveredKeyring []byte `json:"recovered_keyring" binding:"required"`
+}
+
+type RecoveryRequest struct {
+	ID              uuid.UUID  `json:"id"`
+	OrgID           uuid.UUID  `json:"org_id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	DevicePublicKey []byte     `json:"device_public_key"`
+	Status          string     `json:"status"`
+	InitiatedBy     uuid.UUID  `json:"initiated_by"`
+	ApprovedBy      *uuid.UUID `json:"approved_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ApprovedAt      *time.Time `json:"approved_at"`
+	CompletedAt     *time.Time `json:"completed_at"`
+	// RecoveredKeyring is only returned to the recovering member
+	RecoveredKeyring []byte `json:"recovered_keyring,omitempty"`
+}
+
+// RecoveryKeyring is what an admin needs to re-wrap a member's keyring.
+type RecoveryKeyring struct {
+	WrappedKeyring  []byte `json:"wrapped_keyring"`
+	DevicePublicKey []byte `json:"device_public_key"`
+}
@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APITokenPrefix marks service account tokens so they are told apart from
+// JWTs in the Authorization header.
+const APITokenPrefix = "vat_"
+
+type CreateServiceAccountReq struct {
+	Name string `json:"name" binding:"required,max=255"`
+	// PublicKey is what owners wrap resource keys with when sharing to the account
+	PublicKey []byte `json:"public_key" binding:"required"`
+	// OrgID makes this an organization account, requires ADMIN or OWNER
+	OrgID *uuid.UUID `json:"org_id"`
+}
+
+type ServiceAccount struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    *uuid.UUID `json:"user_id"`
+	OrgID     *uuid.UUID `json:"org_id"`
+	Name      string     `json:"name"`
+	PublicKey []byte     `json:"public_key"`
+	CreatedBy uuid.UUID  `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ServiceAccountQuery struct {
+	OrgID string `form:"org_id" binding:"omitempty,uuid"`
+}
+
+// TokenScope limits what an API token may do. Empty FolderIDs and ItemIDs
+// allow every resource shared with the service account; otherwise only the
+// listed folders, their items and the listed items are reachable.
+type TokenScope struct {
+	ReadOnly  bool        `json:"read_only"`
+	FolderIDs []uuid.UUID `json:"folder_ids"`
+	ItemIDs   []uuid.UUID `json:"item_ids"`
+}
+
+// Restricted reports whether the scope lists specific resources.
+func (s TokenScope) Restricted() bool {
+	return len(s.FolderIDs) > 0 || len(s.ItemIDs) > 0
+}
+
+// AllowsFolder reports whether the folder is in scope.
+func (s TokenScope) AllowsFolder(folderID uuid.UUID) bool {
+	return !s.Restricted() || slices.Contains(s.FolderIDs, folderID)
+}
+
+// AllowsItem reports whether the item, stored in folderID if set, is in scope.
+func (s TokenScope) AllowsItem(itemID uuid.UUID, folderID *uuid.UUID) bool {
+	if !s.Restricted() || slices.Contains(s.ItemIDs, itemID) {
+		return true
+	}
+	return folderID != nil && slices.Contains(s.FolderIDs, *folderID)
+}
+
+type CreateAPITokenReq struct {
+	Name      string      `json:"name" binding:"required,max=255"`
+	ReadOnly  *bool       `json:"read_only"`
+	FolderIDs []uuid.UUID `json:"folder_ids" binding:"max=100"`
+	ItemIDs   []uuid.UUID `json:"item_ids" binding:"max=100"`
+	ExpiresAt time.Time   `json:"expires_at" binding:"required"`
+}
+
+type APIToken struct {
+	ID               uuid.UUID  `json:"id"`
+	ServiceAccountID uuid.UUID  `json:"service_account_id"`
+	Name             string     `json:"name"`
+	TokenPrefix      string     `json:"token_prefix"`
+	Scope            TokenScope `json:"scope"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	CreatedBy        uuid.UUID  `json:"created_by"`
+	CreatedAt        time.Time  `json:"created_at"`
+	// Token is only returned when the token is issued
+	Token string `json:"token,omitempty"`
+}
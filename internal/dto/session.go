@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+// SessionsRevoked is returned when every session of a user is revoked.
+// Tokens issued before RevokedBefore are rejected.
+type SessionsRevoked struct {
+	RevokedBefore time.Time `json:"revoked_before"`
+}
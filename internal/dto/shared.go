@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type ResourceType string
 
@@ -14,7 +18,8 @@ type ShareParams struct {
 	ResourceID   uuid.UUID    `json:"resource_id" binding:"required"`
 	ResourceType ResourceType `json:"resource_type" binding:"required,oneof=FOLDER ITEM"`
 
-	EncKey      []byte `json:"enc_key" binding:"required"`
-	KeyNonce    []byte `json:"key_nonce" binding:"required"`
-	AccessLevel string `json:"access_level" binding:"required,oneof=READ WRITE OWNER"`
+	EncKey      []byte     `json:"enc_key" binding:"required"`
+	KeyNonce    []byte     `json:"key_nonce" binding:"required"`
+	AccessLevel string     `json:"access_level" binding:"required,oneof=READ WRITE OWNER"`
+	ExpiresAt   *time.Time `json:"expires_at"`
 }
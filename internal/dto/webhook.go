@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A delivery stays PENDING while it is retried with exponential backoff and
+// becomes DEAD once it runs out of attempts. DEAD deliveries can be replayed.
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryDead      = "DEAD"
+)
+
+type CreateWebhookReq struct {
+	// URL must be http(s) and must not resolve to a loopback, private or
+	// link-local address
+	URL string `json:"url" binding:"required,url,max=2048"`
+	// Events lists the actions to deliver, e.g. SHARED or REVOKED. Empty means all
+	Events []string `json:"events" binding:"omitempty,dive,required,max=50"`
+	// OrgID makes this an organization webhook, requires ADMIN or OWNER
+	OrgID *uuid.UUID `json:"org_id"`
+}
+
+type WebhookResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    *uuid.UUID `json:"user_id"`
+	OrgID     *uuid.UUID `json:"org_id"`
+	URL       string     `json:"url"`
+	Events    []string   `json:"events"`
+	CreatedBy uuid.UUID  `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	// Secret is only returned when the webhook is created
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhookQuery struct {
+	OrgID string `form:"org_id" binding:"omitempty,uuid"`
+}
+
+type WebhookDeliveryQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=PENDING DELIVERED DEAD"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode *int32          `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
+
+// WebhookPayload is the JSON body posted to webhook URLs.
+type WebhookPayload struct {
+	ID           uuid.UUID  `json:"id"`
+	Event        string     `json:"event"`
+	ActorID      uuid.UUID  `json:"actor_id"`
+	ResourceType *string    `json:"resource_type"`
+	ResourceID   *uuid.UUID `json:"resource_id"`
+	OwnerID      *uuid.UUID `json:"owner_id"`
+	OrgID        *uuid.UUID `json:"org_id"`
+	TargetUserID *uuid.UUID `json:"target_user_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,164 @@
+package jwks
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// jsonWebKey is the subset of RFC 7517 needed for signature verification.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// verificationKey is a public key and the algorithms it may verify.
+type verificationKey struct {
+	key     crypto.PublicKey
+	methods []string
+}
+
+// rsaMethods are the algorithms any RSA key may verify
+var rsaMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+
+// ecMethods maps each curve to the one algorithm defined for it
+var ecMethods = map[string]string{
+	"P-256": "ES256",
+	"P-384": "ES384",
+	"P-521": "ES512",
+}
+
+// parseKeySet decodes a JWKS document into public keys by kid. Keys for
+// encryption and of unsupported types are skipped so an auth service can
+// publish them alongside signing keys. A key allows the algorithms of its
+// type, narrowed to its alg when one is published.
+func parseKeySet(data []byte) (map[string]verificationKey, error) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid jwks document: %w", err)
+	}
+
+	keys := make(map[string]verificationKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key crypto.PublicKey
+		var methods []string
+		var err error
+
+		switch jwk.Kty {
+		case "RSA":
+			key, err = jwk.rsaKey()
+			methods = rsaMethods
+		case "EC":
+			key, err = jwk.ecKey()
+			methods = []string{ecMethods[jwk.Crv]}
+		default:
+			continue
+		}
+
+		if err == nil && jwk.Alg != "" {
+			if !slices.Contains(methods, jwk.Alg) {
+				err = fmt.Errorf("alg %q does not match the key", jwk.Alg)
+			}
+			methods = []string{jwk.Alg}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", jwk.Kid, err)
+		}
+
+		if _, dup := keys[jwk.Kid]; dup {
+			return nil, fmt.Errorf("duplicate key id %q", jwk.Kid)
+		}
+		keys[jwk.Kid] = verificationKey{key: key, methods: methods}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks holds no usable signing keys")
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) rsaKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("unsupported exponent")
+	}
+
+	key := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}
+
+	if key.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("rsa keys must be at least 2048 bits")
+	}
+
+	return key, nil
+}
+
+func (k jsonWebKey) ecKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x coordinate: %w", err)
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y coordinate: %w", err)
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+	if len(x) != size || len(y) != size {
+		return nil, fmt.Errorf("coordinates do not match curve %s", k.Crv)
+	}
+
+	// Uncompressed point encoding, validated to be on the curve
+	point := append([]byte{4}, append(x, y...)...)
+	return ecdsa.ParseUncompressedPublicKey(curve, point)
+}
@@ -0,0 +1,282 @@
+// Package jwks verifies user JWTs against the auth service's signing keys,
+// either a single static public key or a JWKS document fetched from a URL
+// or read from a file. Several keys may be valid at once, so the auth
+// service can rotate without restarting vault replicas.
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axosec/core/crypto/token"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// maxDocumentSize bounds the JWKS response, real sets are a few KB
+const maxDocumentSize = 1 << 20
+
+var (
+	ErrUnknownKey      = errors.New("token signed with an unknown key")
+	ErrNoKeys          = errors.New("no signing keys loaded")
+	ErrMethodNotForKey = errors.New("signing method not allowed for this key")
+)
+
+// validMethods are the asymmetric algorithms a JWKS key may allow. HMAC is
+// excluded so a public key can never be used as a shared secret.
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// defaultStaticMethods is what the static key verified before JWKS support
+var defaultStaticMethods = []string{"RS512"}
+
+type Options struct {
+	// Audience is required in the aud claim when set
+	Audience string
+	// Leeway is the clock skew allowed on exp, nbf and iat
+	Leeway time.Duration
+	// RefreshInterval is how long a fetched key set is used before it is
+	// fetched again
+	RefreshInterval time.Duration
+	// MinRefreshInterval limits refetches triggered by unknown key ids, so
+	// forged kids cannot hammer the auth service
+	MinRefreshInterval time.Duration
+	// StaticMethods are the algorithms the static key verifies, RS512 when
+	// empty. JWKS keys allow what their type and alg permit.
+	StaticMethods []string
+}
+
+// Verifier validates JWTs. It is safe for concurrent use. After New, key
+// sets are only fetched by Run, so validating a token never waits on the
+// auth service.
+type Verifier struct {
+	source string
+	opts   Options
+	client *http.Client
+	parser *jwt.Parser
+
+	mu   sync.RWMutex
+	keys map[string]verificationKey
+
+	// wake asks Run for an early refresh when an unknown kid shows up
+	wake chan struct{}
+	// lastAttempt is only touched by New and Run
+	lastAttempt time.Time
+}
+
+// NewStatic returns a Verifier for a single key, e.g. loaded with
+// token.LoadKeysFromFiles. Tokens are accepted regardless of their kid.
+func NewStatic(key crypto.PublicKey, opts Options) *Verifier {
+	methods := opts.StaticMethods
+	if len(methods) == 0 {
+		methods = defaultStaticMethods
+	}
+
+	return &Verifier{
+		opts:   opts,
+		parser: newParser(opts, methods),
+		keys:   map[string]verificationKey{"": {key: key, methods: methods}},
+	}
+}
+
+// New returns a Verifier for the JWKS at source, an http(s) URL or a file
+// path. The first fetch happens here; when it fails the error is returned
+// along with a usable Verifier that Run keeps retrying. Run must be started
+// for the keys to be refreshed.
+func New(ctx context.Context, source string, opts Options) (*Verifier, error) {
+	v := &Verifier{
+		source: source,
+		opts:   opts,
+		client: &http.Client{Timeout: 10 * time.Second},
+		parser: newParser(opts, validMethods),
+		wake:   make(chan struct{}, 1),
+	}
+
+	return v, v.refresh(ctx)
+}
+
+func newParser(opts Options, methods []string) *jwt.Parser {
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(opts.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	return jwt.NewParser(parserOpts...)
+}
+
+// Validate checks the signature, exp, nbf, iat and aud of a token and
+// returns its claims.
+func (v *Verifier) Validate(tokenString string) (*token.Claims, error) {
+	claims := &token.Claims{}
+
+	_, err := v.parser.ParseWithClaims(tokenString, claims, v.keyFunc)
+	if err != nil {
+		return nil, fmt.Errorf("token parsing failed: %w", err)
+	}
+
+	return claims, nil
+}
+
+// keyFunc selects the key by kid. An unknown kid usually means the auth
+// service rotated, so Run is asked to refetch, but the token fails at once
+// rather than waiting for it.
+func (v *Verifier) keyFunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	if key, ok, err := v.lookup(kid, t.Method.Alg()); ok {
+		return key, err
+	}
+
+	if kid == "" {
+		return nil, ErrNoKeys
+	}
+
+	if v.source != "" {
+		select {
+		case v.wake <- struct{}{}:
+		default:
+			// A refresh is already requested
+		}
+	}
+
+	return nil, fmt.Errorf("kid %q: %w", kid, ErrUnknownKey)
+}
+
+// lookup returns the key for kid if it allows alg. Tokens without a kid,
+// and every token when a static key is configured, are checked against all
+// keys allowing alg. ok reports whether any key was found.
+func (v *Verifier) lookup(kid string, alg string) (_ any, ok bool, _ error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if v.source == "" || kid == "" {
+		set := jwt.VerificationKeySet{}
+		for _, key := range v.keys {
+			if slices.Contains(key.methods, alg) {
+				set.Keys = append(set.Keys, key.key)
+			}
+		}
+		if len(set.Keys) == 0 && len(v.keys) > 0 {
+			return nil, true, fmt.Errorf("%s: %w", alg, ErrMethodNotForKey)
+		}
+		return set, len(set.Keys) > 0, nil
+	}
+
+	key, ok := v.keys[kid]
+	if ok && !slices.Contains(key.methods, alg) {
+		return nil, true, fmt.Errorf("kid %q, %s: %w", kid, alg, ErrMethodNotForKey)
+	}
+	return key.key, ok, nil
+}
+
+// Run refreshes the key set every RefreshInterval, and early when keyFunc
+// meets an unknown kid, until ctx is done. Refreshes are at least
+// MinRefreshInterval apart, so forged kids cannot hammer the auth service,
+// and a failed refresh is retried after that interval. It returns at once
+// for a static key.
+func (v *Verifier) Run(ctx context.Context) {
+	if v.source == "" {
+		return
+	}
+
+	next := v.lastAttempt.Add(v.opts.RefreshInterval)
+	if v.loaded() == 0 {
+		next = v.lastAttempt.Add(v.opts.MinRefreshInterval)
+	}
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-v.wake:
+			if earliest := v.lastAttempt.Add(v.opts.MinRefreshInterval); time.Now().Before(earliest) {
+				if earliest.Before(next) {
+					next = earliest
+					timer.Reset(time.Until(next))
+				}
+				continue
+			}
+		}
+
+		if err := v.refresh(ctx); err != nil {
+			next = v.lastAttempt.Add(v.opts.MinRefreshInterval)
+		} else {
+			next = v.lastAttempt.Add(v.opts.RefreshInterval)
+		}
+		timer.Reset(time.Until(next))
+	}
+}
+
+func (v *Verifier) loaded() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return len(v.keys)
+}
+
+// refresh fetches and swaps in the key set. A failed refresh keeps the
+// previous keys.
+func (v *Verifier) refresh(ctx context.Context) error {
+	v.lastAttempt = time.Now()
+
+	data, err := v.fetch(ctx)
+	if err == nil {
+		var keys map[string]verificationKey
+		keys, err = parseKeySet(data)
+		if err == nil {
+			v.mu.Lock()
+			v.keys = keys
+			v.mu.Unlock()
+
+			slog.DebugContext(ctx, "jwks refreshed", "source", v.source, "keys", len(keys))
+			return nil
+		}
+	}
+
+	slog.ErrorContext(ctx, "jwks refresh failed, keeping the previous keys", "source", v.source, "error", err)
+	return err
+}
+
+func (v *Verifier) fetch(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(v.source, "http://") && !strings.HasPrefix(v.source, "https://") {
+		return os.ReadFile(strings.TrimPrefix(v.source, "file://"))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, v.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks endpoint returned %s", resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+}
@@ -0,0 +1,490 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var b64 = base64.RawURLEncoding
+
+func generateRSA(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatalf("generating rsa key: %v", err)
+	}
+	return key
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   b64.EncodeToString(key.N.Bytes()),
+		E:   b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(t *testing.T, kid string, key *ecdsa.PublicKey) jsonWebKey {
+	t.Helper()
+
+	point, err := key.Bytes()
+	if err != nil {
+		t.Fatalf("encoding ec key: %v", err)
+	}
+	size := (len(point) - 1) / 2
+
+	return jsonWebKey{
+		Kty: "EC",
+		Kid: kid,
+		Crv: key.Curve.Params().Name,
+		X:   b64.EncodeToString(point[1 : 1+size]),
+		Y:   b64.EncodeToString(point[1+size:]),
+	}
+}
+
+func keySet(t *testing.T, keys ...jsonWebKey) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string][]jsonWebKey{"keys": keys})
+	if err != nil {
+		t.Fatalf("encoding jwks: %v", err)
+	}
+	return data
+}
+
+func validClaims() jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		Subject:   "8a4f2d1e-3c5b-4e6f-9a7b-1c2d3e4f5a6b",
+		Audience:  jwt.ClaimStrings{"vault"},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.Claims) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+
+	signed, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return signed
+}
+
+// jwksServer serves the current document and counts fetches. A nil
+// document answers 500.
+type jwksServer struct {
+	*httptest.Server
+	doc     atomic.Pointer[[]byte]
+	fetches atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, doc []byte) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{}
+	s.set(doc)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+
+		doc := s.doc.Load()
+		if doc == nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(*doc)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *jwksServer) set(doc []byte) {
+	if doc == nil {
+		s.doc.Store(nil)
+		return
+	}
+	s.doc.Store(&doc)
+}
+
+// startVerifier fetches from srv and runs the refresh loop for the test.
+func startVerifier(t *testing.T, srv *jwksServer, opts Options) *Verifier {
+	t.Helper()
+
+	v, err := New(t.Context(), srv.URL, opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		v.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return v
+}
+
+// waitFor polls cond until it holds or a few seconds have passed.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestValidateSelectsKeyByKid(t *testing.T) {
+	keyA := generateRSA(t, 2048)
+	keyB, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating ec key: %v", err)
+	}
+
+	srv := newJWKSServer(t, keySet(t, rsaJWK("a", &keyA.PublicKey), ecJWK(t, "b", &keyB.PublicKey)))
+	v := startVerifier(t, srv, Options{RefreshInterval: time.Hour, MinRefreshInterval: time.Hour})
+
+	if _, err := v.Validate(sign(t, jwt.SigningMethodRS256, keyA, "a", validClaims())); err != nil {
+		t.Errorf("token of key a: %v", err)
+	}
+	if _, err := v.Validate(sign(t, jwt.SigningMethodES256, keyB, "b", validClaims())); err != nil {
+		t.Errorf("token of key b: %v", err)
+	}
+
+	// Signed by a but naming b, so b is used and the signature fails
+	_, err = v.Validate(sign(t, jwt.SigningMethodRS256, keyA, "b", validClaims()))
+	if !errors.Is(err, jwt.ErrTokenSignatureInvalid) && !errors.Is(err, jwt.ErrTokenUnverifiable) {
+		t.Errorf("token of key a with kid b: %v, want a signature error", err)
+	}
+
+	// Without a kid every key is tried
+	if _, err := v.Validate(sign(t, jwt.SigningMethodES256, keyB, "", validClaims())); err != nil {
+		t.Errorf("token without kid: %v", err)
+	}
+}
+
+func TestUnknownKidTriggersRateLimitedRefresh(t *testing.T) {
+	keyA := generateRSA(t, 2048)
+	keyB := generateRSA(t, 2048)
+
+	const minRefresh = 300 * time.Millisecond
+
+	srv := newJWKSServer(t, keySet(t, rsaJWK("a", &keyA.PublicKey)))
+	v := startVerifier(t, srv, Options{RefreshInterval: time.Hour, MinRefreshInterval: minRefresh})
+
+	// The auth service rotates to b
+	srv.set(keySet(t, rsaJWK("a", &keyA.PublicKey), rsaJWK("b", &keyB.PublicKey)))
+	tokenB := sign(t, jwt.SigningMethodRS256, keyB, "b", validClaims())
+
+	start := time.Now()
+	if _, err := v.Validate(tokenB); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("token of the new key before the refresh: %v, want ErrUnknownKey", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("Validate waited %s for the refresh", elapsed)
+	}
+
+	waitFor(t, "the refresh", func() bool { return srv.fetches.Load() == 2 })
+	refreshed := time.Now()
+	if elapsed := refreshed.Sub(start); elapsed < minRefresh-50*time.Millisecond {
+		t.Fatalf("refreshed %s after the initial fetch, want at least %s", elapsed, minRefresh)
+	}
+
+	waitFor(t, "key b", func() bool {
+		_, err := v.Validate(tokenB)
+		return err == nil
+	})
+
+	// A burst of forged kids right after a refresh leads to one more fetch,
+	// and only once the minimum interval has passed
+	forged := sign(t, jwt.SigningMethodRS256, keyB, "forged", validClaims())
+	for range 20 {
+		if _, err := v.Validate(forged); !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("forged kid: %v, want ErrUnknownKey", err)
+		}
+	}
+
+	time.Sleep(minRefresh / 3)
+	if got := srv.fetches.Load(); got != 2 {
+		t.Fatalf("%d fetches within the minimum interval, want 2", got)
+	}
+
+	waitFor(t, "the rate limited refresh", func() bool { return srv.fetches.Load() == 3 })
+	if elapsed := time.Since(refreshed); elapsed < minRefresh-50*time.Millisecond {
+		t.Fatalf("refetched %s after the previous fetch, want at least %s", elapsed, minRefresh)
+	}
+
+	time.Sleep(2 * minRefresh)
+	if got := srv.fetches.Load(); got != 3 {
+		t.Fatalf("%d fetches after the burst, want 3", got)
+	}
+}
+
+func TestFailedRefreshKeepsKeys(t *testing.T) {
+	keyA := generateRSA(t, 2048)
+
+	srv := newJWKSServer(t, keySet(t, rsaJWK("a", &keyA.PublicKey)))
+	v := startVerifier(t, srv, Options{RefreshInterval: time.Hour, MinRefreshInterval: 20 * time.Millisecond})
+
+	srv.set(nil)
+
+	// An unknown kid makes Run refetch, which fails
+	v.Validate(sign(t, jwt.SigningMethodRS256, keyA, "unknown", validClaims()))
+	waitFor(t, "the failed refresh", func() bool { return srv.fetches.Load() >= 2 })
+
+	if _, err := v.Validate(sign(t, jwt.SigningMethodRS256, keyA, "a", validClaims())); err != nil {
+		t.Fatalf("token of the previous key after a failed refresh: %v", err)
+	}
+
+	// Failures are retried after the minimum interval
+	waitFor(t, "a retry", func() bool { return srv.fetches.Load() >= 3 })
+}
+
+func TestValidateRejectsSymmetricAndNone(t *testing.T) {
+	key := generateRSA(t, 2048)
+	v := NewStatic(&key.PublicKey, Options{})
+
+	// HS256 keyed with the public key, the classic algorithm confusion
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("encoding public key: %v", err)
+	}
+	hs256 := sign(t, jwt.SigningMethodHS256, der, "", validClaims())
+	if _, err := v.Validate(hs256); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("HS256 token: %v, want ErrTokenSignatureInvalid", err)
+	}
+
+	none := sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", validClaims())
+	if _, err := v.Validate(none); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("none token: %v, want ErrTokenSignatureInvalid", err)
+	}
+}
+
+func TestStaticKeyMethods(t *testing.T) {
+	key := generateRSA(t, 2048)
+
+	// RS512 only unless configured otherwise
+	v := NewStatic(&key.PublicKey, Options{})
+	if _, err := v.Validate(sign(t, jwt.SigningMethodRS512, key, "", validClaims())); err != nil {
+		t.Errorf("RS512 token: %v", err)
+	}
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodRS256, jwt.SigningMethodPS512} {
+		if _, err := v.Validate(sign(t, method, key, "", validClaims())); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			t.Errorf("%s token: %v, want ErrTokenSignatureInvalid", method.Alg(), err)
+		}
+	}
+
+	v = NewStatic(&key.PublicKey, Options{StaticMethods: []string{"PS256"}})
+	if _, err := v.Validate(sign(t, jwt.SigningMethodPS256, key, "", validClaims())); err != nil {
+		t.Errorf("PS256 token with PS256 configured: %v", err)
+	}
+	if _, err := v.Validate(sign(t, jwt.SigningMethodRS512, key, "", validClaims())); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("RS512 token with PS256 configured: %v, want ErrTokenSignatureInvalid", err)
+	}
+}
+
+func TestJWKSKeyMethods(t *testing.T) {
+	withAlg := generateRSA(t, 2048)
+	withoutAlg := generateRSA(t, 2048)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating ec key: %v", err)
+	}
+
+	noAlg := rsaJWK("b", &withoutAlg.PublicKey)
+	noAlg.Alg = ""
+
+	srv := newJWKSServer(t, keySet(t, rsaJWK("a", &withAlg.PublicKey), noAlg, ecJWK(t, "ec", &ecKey.PublicKey)))
+	v := startVerifier(t, srv, Options{RefreshInterval: time.Hour, MinRefreshInterval: time.Hour})
+
+	tests := []struct {
+		name    string
+		method  jwt.SigningMethod
+		key     any
+		kid     string
+		wantErr error
+	}{
+		{"alg of the key", jwt.SigningMethodRS256, withAlg, "a", nil},
+		{"other rsa method than alg", jwt.SigningMethodPS256, withAlg, "a", ErrMethodNotForKey},
+		{"any rsa method without alg", jwt.SigningMethodPS384, withoutAlg, "b", nil},
+		{"curve of the key", jwt.SigningMethodES256, ecKey, "ec", nil},
+		{"rsa method on an ec key", jwt.SigningMethodRS256, withAlg, "ec", ErrMethodNotForKey},
+		{"no kid, only keys allowing the method are tried", jwt.SigningMethodPS256, withoutAlg, "", nil},
+		{"no kid, no key allows the method", jwt.SigningMethodES384, mustECKey(t, elliptic.P384()), "", ErrMethodNotForKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Validate(sign(t, tt.method, tt.key, tt.kid, validClaims()))
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Validate: %v, want ok", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate: %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func mustECKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("generating ec key: %v", err)
+	}
+	return key
+}
+
+func TestValidateAudience(t *testing.T) {
+	key := generateRSA(t, 2048)
+	v := NewStatic(&key.PublicKey, Options{Audience: "vault"})
+
+	if _, err := v.Validate(sign(t, jwt.SigningMethodRS512, key, "", validClaims())); err != nil {
+		t.Errorf("token for vault: %v", err)
+	}
+
+	claims := validClaims()
+	claims.Audience = jwt.ClaimStrings{"billing"}
+	if _, err := v.Validate(sign(t, jwt.SigningMethodRS512, key, "", claims)); !errors.Is(err, jwt.ErrTokenInvalidAudience) {
+		t.Errorf("token for billing: %v, want ErrTokenInvalidAudience", err)
+	}
+
+	claims.Audience = nil
+	if _, err := v.Validate(sign(t, jwt.SigningMethodRS512, key, "", claims)); !errors.Is(err, jwt.ErrTokenRequiredClaimMissing) && !errors.Is(err, jwt.ErrTokenInvalidAudience) {
+		t.Errorf("token without aud: %v, want an audience error", err)
+	}
+}
+
+func TestValidateLeeway(t *testing.T) {
+	key := generateRSA(t, 2048)
+
+	const leeway = 30 * time.Second
+	v := NewStatic(&key.PublicKey, Options{Leeway: leeway})
+
+	tests := []struct {
+		name    string
+		exp     time.Duration
+		nbf     time.Duration
+		wantErr error
+	}{
+		{"exp just inside leeway", -leeway + 5*time.Second, 0, nil},
+		{"exp just outside leeway", -leeway - 5*time.Second, 0, jwt.ErrTokenExpired},
+		{"nbf just inside leeway", time.Hour, leeway - 5*time.Second, nil},
+		{"nbf just outside leeway", time.Hour, leeway + 5*time.Second, jwt.ErrTokenNotValidYet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			claims := jwt.RegisteredClaims{
+				Subject:   "8a4f2d1e-3c5b-4e6f-9a7b-1c2d3e4f5a6b",
+				IssuedAt:  jwt.NewNumericDate(now.Add(-time.Hour)),
+				ExpiresAt: jwt.NewNumericDate(now.Add(tt.exp)),
+			}
+			if tt.nbf != 0 {
+				claims.NotBefore = jwt.NewNumericDate(now.Add(tt.nbf))
+			}
+
+			_, err := v.Validate(sign(t, jwt.SigningMethodRS512, key, "", claims))
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Validate: %v, want ok", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate: %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseKeySet(t *testing.T) {
+	rsaKey := generateRSA(t, 2048)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating ec key: %v", err)
+	}
+
+	keys, err := parseKeySet(keySet(t, rsaJWK("rsa", &rsaKey.PublicKey), ecJWK(t, "ec", &ecKey.PublicKey)))
+	if err != nil {
+		t.Fatalf("valid set: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("valid set: %d keys, want 2", len(keys))
+	}
+
+	// Encryption keys are skipped, not rejected
+	enc := rsaJWK("enc", &rsaKey.PublicKey)
+	enc.Use = "enc"
+	if keys, err := parseKeySet(keySet(t, rsaJWK("rsa", &rsaKey.PublicKey), enc)); err != nil || len(keys) != 1 {
+		t.Errorf("set with an encryption key: %d keys, %v, want 1 key", len(keys), err)
+	}
+
+	offCurve := ecJWK(t, "ec", &ecKey.PublicKey)
+	y, _ := b64.DecodeString(offCurve.Y)
+	y[len(y)-1] ^= 1
+	offCurve.Y = b64.EncodeToString(y)
+
+	wrongAlg := rsaJWK("rsa", &rsaKey.PublicKey)
+	wrongAlg.Alg = "ES256"
+	wrongCurve := ecJWK(t, "ec", &ecKey.PublicKey)
+	wrongCurve.Alg = "ES384"
+
+	tests := []struct {
+		name string
+		doc  []byte
+		want string
+	}{
+		{"short rsa key", keySet(t, rsaJWK("short", &generateRSA(t, 1024).PublicKey)), "at least 2048 bits"},
+		{"off-curve ec point", keySet(t, offCurve), "invalid key"},
+		{"alg of another key type", keySet(t, wrongAlg), "does not match the key"},
+		{"alg of another curve", keySet(t, wrongCurve), "does not match the key"},
+		{"duplicate kid", keySet(t, rsaJWK("a", &rsaKey.PublicKey), ecJWK(t, "a", &ecKey.PublicKey)), "duplicate key id"},
+		{"no signing keys", keySet(t, enc), "no usable signing keys"},
+		{"not json", []byte("<html>"), "invalid jwks document"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseKeySet(tt.doc)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("parseKeySet: %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const redacted = "[REDACTED]"
+
+type requestIDKey struct{}
+
+// WithRequestID stores the request ID on ctx. Records logged with ctx carry
+// it as request_id.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID stored on ctx, or "".
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// New returns a JSON logger that adds the request and trace IDs from the
+// context and redacts secret fields.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(&contextHandler{
+		Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{
+			Level:       level,
+			ReplaceAttr: redact,
+		}),
+	})
+}
+
+// sensitive reports whether a field named key may carry ciphertext, nonces,
+// key material or credentials.
+func sensitive(key string) bool {
+	key = strings.ToLower(key)
+
+	if strings.HasPrefix(key, "enc_") {
+		return true
+	}
+
+	for _, part := range []string{"nonce", "key", "secret", "password", "token", "signature"} {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redact replaces sensitive fields and raw bytes, which in this service are
+// almost always ciphertext or keys.
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindGroup {
+		return a
+	}
+
+	if sensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	if a.Value.Kind() == slog.KindAny {
+		if _, ok := a.Value.Any().([]byte); ok {
+			return slog.String(a.Key, redacted)
+		}
+	}
+
+	return a
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		r.AddAttrs(slog.String("request_id", requestID))
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// totalsTimeout bounds the count queries run on every scrape
+const totalsTimeout = 5 * time.Second
+
+func newDesc(subsystem string, name string, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, name), help, nil, nil)
+}
+
+// PoolCollector reports pgxpool.Pool.Stat() on every scrape.
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	return &PoolCollector{
+		pool:                 pool,
+		acquireCount:         newDesc("db_pool", "acquire_total", "Successful connection acquires from the pool."),
+		acquireDuration:      newDesc("db_pool", "acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyAcquireCount:    newDesc("db_pool", "empty_acquire_total", "Acquires that had to wait because the pool was empty."),
+		canceledAcquireCount: newDesc("db_pool", "canceled_acquire_total", "Acquires canceled by their context."),
+		acquiredConns:        newDesc("db_pool", "acquired_connections", "Connections currently in use."),
+		idleConns:            newDesc("db_pool", "idle_connections", "Idle connections in the pool."),
+		constructingConns:    newDesc("db_pool", "constructing_connections", "Connections being established."),
+		totalConns:           newDesc("db_pool", "connections", "Total connections in the pool."),
+		maxConns:             newDesc("db_pool", "max_connections", "Maximum size of the pool."),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquireCount
+	ch <- c.acquireDuration
+	ch <- c.emptyAcquireCount
+	ch <- c.canceledAcquireCount
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.constructingConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+}
+
+// TotalsCollector reports row counts of the main vault tables. The counts
+// are queried on every scrape, so keep the scrape interval reasonable.
+type TotalsCollector struct {
+	q *db.Queries
+
+	folders       *prometheus.Desc
+	items         *prometheus.Desc
+	organizations *prometheus.Desc
+}
+
+func NewTotalsCollector(q *db.Queries) *TotalsCollector {
+	return &TotalsCollector{
+		q:             q,
+		folders:       newDesc("", "folders", "Folders stored in the vault."),
+		items:         newDesc("", "items", "Items stored in the vault."),
+		organizations: newDesc("", "organizations", "Organizations."),
+	}
+}
+
+func (c *TotalsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.folders
+	ch <- c.items
+	ch <- c.organizations
+}
+
+func (c *TotalsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), totalsTimeout)
+	defer cancel()
+
+	totals, err := c.q.CountVaultTotals(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to collect vault totals", "error", err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.folders, prometheus.GaugeValue, float64(totals.Folders))
+	ch <- prometheus.MustNewConstMetric(c.items, prometheus.GaugeValue, float64(totals.Items))
+	ch <- prometheus.MustNewConstMetric(c.organizations, prometheus.GaugeValue, float64(totals.Organizations))
+}
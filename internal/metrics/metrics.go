@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "vault"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	auditLockWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "audit_chain_lock_wait_seconds",
+		Help:      "Time audit writers waited for the audit chain lock.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	serviceErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "service_errors_total",
+		Help:      "Failed vault service operations by operation.",
+	}, []string{"operation"})
+)
+
+// ObserveRequest records one served HTTP request. route is the route
+// template, e.g. /v1/items/:id, so ids do not create new series.
+func ObserveRequest(method string, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveOperation counts *err as a failure of operation when it is set.
+// Call it deferred with a pointer to the named error result.
+func ObserveOperation(operation string, err *error) {
+	if *err != nil {
+		serviceErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// ObserveAuditLockWait records how long an audit write waited for the chain
+// lock. Every audited request takes it, so this is where they queue up.
+func ObserveAuditLockWait(wait time.Duration) {
+	auditLockWait.Observe(wait.Seconds())
+}
+
+// Serve exposes the default registry at /metrics on port until ctx is done.
+func Serve(ctx context.Context, port string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics server failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,343 @@
+// Package migrate applies the SQL files in migrations/ to the database.
+// Progress is kept in the schema_migrations table in the same layout as
+// golang-migrate, so databases migrated with its CLI are picked up as is.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockID is the pg_advisory_lock key held while migrating, so replicas
+// starting together apply each migration once
+const lockID int64 = 0x7661756c74 // "vault"
+
+var (
+	ErrDirty       = errors.New("database is dirty after a failed migration, fix it by hand and reset schema_migrations")
+	ErrSchemaNewer = errors.New("database schema is newer than this binary")
+	ErrSchemaOlder = errors.New("database schema is older than this binary, run migrations first")
+	ErrNoDown      = errors.New("migration has no down file")
+)
+
+type Migration struct {
+	Version uint64 `json:"version"`
+	Name    string `json:"name"`
+	Up      string `json:"-"`
+	Down    string `json:"-"`
+}
+
+type MigrationStatus struct {
+	Version uint64 `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
+
+type Status struct {
+	Version    uint64            `json:"version"`
+	Dirty      bool              `json:"dirty"`
+	Latest     uint64            `json:"latest"`
+	Migrations []MigrationStatus `json:"migrations"`
+}
+
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// New loads the migrations at the root of fsys, e.g. migrations.FS.
+func New(pool *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		pool:       pool,
+		migrations: migrations,
+	}, nil
+}
+
+// Load reads the NNN_name.up.sql files at the root of fsys and their
+// optional NNN_name.down.sql counterparts, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(files))
+	for _, file := range files {
+		base := strings.TrimSuffix(path.Base(file), ".up.sql")
+
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s is not named NNN_name.up.sql", file)
+		}
+
+		version, err := strconv.ParseUint(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version: %w", file, err)
+		}
+
+		up, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+
+		down, err := fs.ReadFile(fsys, base+".down.sql")
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read migration %s: %w", base+".down.sql", err)
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			Up:      string(up),
+			Down:    string(down),
+		})
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+
+	return migrations, nil
+}
+
+// Latest returns the version of the newest migration, 0 when there are none.
+func (m *Migrator) Latest() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every migration past the current version, each in its own
+// transaction, and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied := []Migration{}
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := m.lockedVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if mig.Version <= current {
+				continue
+			}
+
+			if err := setVersion(ctx, conn, mig.Up, mig.Version); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+
+			applied = append(applied, mig)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// the ones it reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	reverted := []Migration{}
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := m.lockedVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		i := slices.IndexFunc(m.migrations, func(mig Migration) bool { return mig.Version == current })
+		if current != 0 && i < 0 {
+			return fmt.Errorf("applied version %d has no migration file", current)
+		}
+
+		for ; i >= 0 && len(reverted) < steps; i-- {
+			mig := m.migrations[i]
+			if mig.Down == "" {
+				return fmt.Errorf("%d_%s: %w", mig.Version, mig.Name, ErrNoDown)
+			}
+
+			var previous uint64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+
+			if err := setVersion(ctx, conn, mig.Down, previous); err != nil {
+				return fmt.Errorf("reverting %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+
+			reverted = append(reverted, mig)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists the migrations and whether each is applied. It does not
+// take the lock or write anything.
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	current, dirty, err := readVersion(ctx, m.pool)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Version:    current,
+		Dirty:      dirty,
+		Latest:     m.Latest(),
+		Migrations: make([]MigrationStatus, 0, len(m.migrations)),
+	}
+
+	for _, mig := range m.migrations {
+		status.Migrations = append(status.Migrations, MigrationStatus{
+			Version: mig.Version,
+			Name:    mig.Name,
+			Applied: mig.Version <= current,
+		})
+	}
+
+	return status, nil
+}
+
+// Check returns an error unless the database is exactly at the latest
+// migration, so a binary never runs against a schema it was not built for.
+func (m *Migrator) Check(ctx context.Context) error {
+	current, dirty, err := readVersion(ctx, m.pool)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case dirty:
+		return fmt.Errorf("version %d: %w", current, ErrDirty)
+	case current > m.Latest():
+		return fmt.Errorf("database at version %d, binary at %d: %w", current, m.Latest(), ErrSchemaNewer)
+	case current < m.Latest():
+		return fmt.Errorf("database at version %d, binary at %d: %w", current, m.Latest(), ErrSchemaOlder)
+	}
+
+	return nil
+}
+
+// withLock runs fn on one connection holding the migration advisory lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	// The lock belongs to the session, release it even if ctx is done
+	defer conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	if _, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// lockedVersion reads the version under the lock and refuses dirty or newer
+// schemas before anything is applied.
+func (m *Migrator) lockedVersion(ctx context.Context, conn *pgxpool.Conn) (uint64, error) {
+	current, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	if dirty {
+		return 0, fmt.Errorf("version %d: %w", current, ErrDirty)
+	}
+
+	if current > m.Latest() {
+		return 0, fmt.Errorf("database at version %d, binary at %d: %w", current, m.Latest(), ErrSchemaNewer)
+	}
+
+	return current, nil
+}
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// readVersion returns the applied version, 0 for an empty database.
+func readVersion(ctx context.Context, q querier) (version uint64, dirty bool, err error) {
+	var exists bool
+	if err := q.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, false, fmt.Errorf("failed to look up schema_migrations: %w", err)
+	}
+
+	if !exists {
+		return 0, false, nil
+	}
+
+	err = q.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return version, dirty, nil
+}
+
+// setVersion runs a migration file and records the resulting version in
+// one transaction.
+func setVersion(ctx context.Context, conn *pgxpool.Conn, sql string, version uint64) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Without arguments pgx uses the simple protocol, which runs every
+	// statement of the file
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return fmt.Errorf("failed to clear schema version: %w", err)
+	}
+
+	if version > 0 {
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, FALSE)`, int64(version)); err != nil {
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
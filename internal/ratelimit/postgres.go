@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresStore shares buckets between replicas through the
+// rate_limit_buckets table. Every Take is one round trip.
+type PostgresStore struct {
+	q *db.Queries
+}
+
+func NewPostgresStore(q *db.Queries) *PostgresStore {
+	return &PostgresStore{q: q}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	tokens, err := s.q.TakeRateLimitToken(ctx, db.TakeRateLimitTokenParams{
+		Key:   key,
+		Burst: float64(limit.Burst),
+		Rate:  limit.Rate,
+	})
+	if err == nil {
+		return newResult(true, tokens, limit), nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Result{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	// No row means the bucket was left untouched, read it for the headers
+	tokens, err = s.q.GetRateLimitTokens(ctx, db.GetRateLimitTokensParams{
+		Key:   key,
+		Burst: float64(limit.Burst),
+		Rate:  limit.Rate,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read rate limit bucket: %w", err)
+	}
+
+	return newResult(false, tokens, limit), nil
+}
+
+func (s *PostgresStore) Cleanup(ctx context.Context, idleBefore time.Time) error {
+	if _, err := s.q.DeleteIdleRateLimitBuckets(ctx, idleBefore); err != nil {
+		return fmt.Errorf("failed to delete idle rate limit buckets: %w", err)
+	}
+	return nil
+}
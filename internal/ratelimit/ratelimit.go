@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket refilled at Rate tokens per second up to Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Window is how long an empty bucket takes to refill completely.
+func (l Limit) Window() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+// Result describes a bucket after a Take.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is when the bucket is full again
+	Reset time.Duration
+	// RetryAfter is when the next token is available, set when not allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets by key.
+type Store interface {
+	// Take removes one token from the bucket for key if one is available
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Cleanup drops buckets not used since idleBefore
+	Cleanup(ctx context.Context, idleBefore time.Time) error
+}
+
+// newResult builds a Result from the tokens left in the bucket.
+func newResult(allowed bool, tokens float64, limit Limit) Result {
+	r := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     secondsToDuration((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+
+	if !allowed {
+		r.RetryAfter = secondsToDuration((1 - tokens) / limit.Rate)
+	}
+
+	return r
+}
+
+func secondsToDuration(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
+
+// RunCleanup drops buckets idle for longer than interval, every interval,
+// until ctx is done.
+func RunCleanup(ctx context.Context, store Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := store.Cleanup(ctx, time.Now().Add(-interval)); err != nil {
+				slog.ErrorContext(ctx, "rate limit cleanup failed", "error", err)
+			}
+		}
+	}
+}
+
+// Class groups routes that share a limit.
+type Class string
+
+const (
+	ClassRead  Class = "read"
+	ClassWrite Class = "write"
+	ClassShare Class = "share"
+	// ClassIP limits every request of a client address, before it is
+	// authenticated
+	ClassIP Class = "ip"
+)
+
+// Limiter applies a Limit per class on top of a Store.
+type Limiter struct {
+	store  Store
+	limits map[Class]Limit
+}
+
+func NewLimiter(store Store, limits map[Class]Limit) *Limiter {
+	return &Limiter{
+		store:  store,
+		limits: limits,
+	}
+}
+
+// Take takes a token from the bucket of subject, e.g. "user:<id>", for class.
+func (l *Limiter) Take(ctx context.Context, class Class, subject string) (Result, error) {
+	return l.store.Take(ctx, string(class)+":"+subject, l.limits[class])
+}
+
+// MaxWindow returns the longest refill window of all classes. Buckets idle
+// for longer are full and can be dropped.
+func (l *Limiter) MaxWindow() time.Duration {
+	var window time.Duration
+	for _, limit := range l.limits {
+		window = max(window, limit.Window())
+	}
+	return window
+}
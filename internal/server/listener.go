@@ -0,0 +1,46 @@
+// Package server sets up the api listener: TCP or a Unix socket, with
+// optional TLS and client certificate verification.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+)
+
+// socketMode lets the owner and group, e.g. a reverse proxy, connect
+const socketMode fs.FileMode = 0o660
+
+// Listen listens on the Unix socket at socketPath when it is set, otherwise
+// on TCP port.
+func Listen(port string, socketPath string) (net.Listener, error) {
+	if socketPath == "" {
+		return net.Listen("tcp", ":"+port)
+	}
+
+	// A socket left behind by a crash would make the bind fail
+	if info, err := os.Lstat(socketPath); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(socketPath, socketMode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+
+	return ln, nil
+}
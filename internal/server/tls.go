@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/axosec/vault/internal/config"
+)
+
+// NewTLSConfig returns the TLS settings for the api listener, or nil when
+// no certificate is configured. The certificate and key are reloaded when
+// either file changes until ctx is done, so rotations need no restart.
+func NewTLSConfig(ctx context.Context, cfg config.TLSConfig) (*tls.Config, error) {
+	if cfg.CertPath == "" {
+		return nil, nil
+	}
+
+	reloader := &certReloader{
+		certPath: cfg.CertPath,
+		keyPath:  cfg.KeyPath,
+	}
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+
+	go reloader.run(ctx, time.Duration(cfg.ReloadIntervalSeconds)*time.Second)
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if cfg.ClientCAPath != "" {
+		pem, err := os.ReadFile(cfg.ClientCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("client CA file holds no PEM certificates")
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		if cfg.ClientAuth == "request" {
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+type certReloader struct {
+	certPath string
+	keyPath  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// modified returns the newest modification time of the two files. Stat
+// follows symlinks, so swapped Kubernetes secret mounts are noticed too.
+func (r *certReloader) modified() (time.Time, error) {
+	var latest time.Time
+
+	for _, path := range []string{r.certPath, r.keyPath} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+func (r *certReloader) load() error {
+	modTime, err := r.modified()
+	if err != nil {
+		return fmt.Errorf("failed to stat tls certificate: %w", err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load tls certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return nil
+}
+
+// run polls the files and reloads them on change. A broken pair, e.g. the
+// certificate written before the key, keeps the previous one in use.
+func (r *certReloader) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTime, err := r.modified()
+			if err != nil {
+				slog.ErrorContext(ctx, "tls certificate check failed", "error", err)
+				continue
+			}
+
+			r.mu.RLock()
+			changed := !modTime.Equal(r.modTime)
+			r.mu.RUnlock()
+
+			if !changed {
+				continue
+			}
+
+			if err := r.load(); err != nil {
+				slog.ErrorContext(ctx, "tls certificate reload failed, keeping the previous one", "error", err)
+				continue
+			}
+			slog.InfoContext(ctx, "tls certificate reloaded", "path", r.certPath)
+		}
+	}
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AdminService backs the vaultctl operator commands. It bypasses per-user
+// access checks, audit events it writes have the nil UUID as actor.
+type AdminService struct {
+	pool *pgxpool.Pool
+	q    *db.Queries
+}
+
+func NewAdminService(pool *pgxpool.Pool, q *db.Queries) *AdminService {
+	return &AdminService{
+		pool: pool,
+		q:    q,
+	}
+}
+
+func (s *AdminService) Stats(ctx context.Context) (*dto.VaultStats, error) {
+	stats, err := s.q.GetVaultStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stats: %w", err)
+	}
+
+	return &dto.VaultStats{
+		Folders:                  stats.Folders,
+		Items:                    stats.Items,
+		TrashedFolders:           stats.TrashedFolders,
+		TrashedItems:             stats.TrashedItems,
+		Grants:                   stats.Grants,
+		Users:                    stats.Users,
+		Organizations:            stats.Organizations,
+		Groups:                   stats.Groups,
+		ServiceAccounts:          stats.ServiceAccounts,
+		ActiveAPITokens:          stats.ActiveApiTokens,
+		Projects:                 stats.Projects,
+		AuditEvents:              stats.AuditEvents,
+		PendingWebhookDeliveries: stats.PendingWebhookDeliveries,
+		DeadWebhookDeliveries:    stats.DeadWebhookDeliveries,
+	}, nil
+}
+
+// Purge permanently deletes folders and items soft deleted before the given
+// time. Keys and other rows referencing them go with them.
+func (s *AdminService) Purge(ctx context.Context, before time.Time) (*dto.PurgeResult, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	// Items first so their folders are empty
+	items, err := qtx.PurgeDeletedItems(ctx, &before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge items: %w", err)
+	}
+
+	folders, err := qtx.PurgeDeletedFolders(ctx, &before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge folders: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return &dto.PurgeResult{
+		Before:  before,
+		Items:   items,
+		Folders: folders,
+	}, nil
+}
+
+// RevokeAllGrants removes every key shared with the user and their group
+// memberships, e.g. when offboarding. Resources the user owns are kept.
+func (s *AdminService) RevokeAllGrants(ctx context.Context, userID uuid.UUID) (*dto.RevokeAllResult, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	revoked, err := qtx.DeleteUserGrants(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke grants: %w", err)
+	}
+
+	groups, err := qtx.RemoveUserFromAllGroups(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove group memberships: %w", err)
+	}
+
+	// Audit events go last, they hold the chain lock until commit
+	for _, grant := range revoked {
+		resourceID, resourceType := grant.ItemID, dto.TypeItem
+		if grant.FolderID != nil {
+			resourceID, resourceType = grant.FolderID, dto.TypeFolder
+		}
+
+		ownerID, err := resourceOwner(ctx, qtx, *resourceID, resourceType)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := recordResourceEvent(ctx, qtx, uuid.Nil, dto.EventRevoked, *resourceID, resourceType, ownerID, &userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return &dto.RevokeAllResult{
+		UserID: userID,
+		Grants: len(revoked),
+		Groups: groups,
+	}, nil
+}
+
+// ExportUser returns the folders and items the user holds keys to, as stored.
+func (s *AdminService) ExportUser(ctx context.Context, userID uuid.UUID) (*dto.UserExport, error) {
+	foldersDb, err := s.q.GetUserFolders(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch folders: %w", err)
+	}
+
+	itemsDb, err := s.q.GetUserItemsExport(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch items: %w", err)
+	}
+
+	folders := make([]dto.FolderSummary, 0, len(foldersDb))
+	for _, folder := range foldersDb {
+		folders = append(folders, dto.FolderSummary{
+			ID:            folder.ID,
+			OrgID:         folder.OrgID,
+			EncMetadata:   folder.EncMetadata,
+			Nonce:         folder.Nonce,
+			KeyNonce:      folder.KeyNonce,
+			WrappedKey:    folder.WrappedKey,
+			AccessLevel:   folder.AccessLevel,
+			IsOwner:       folder.IsOwner,
+			GroupID:       folder.GroupID,
+			GroupKey:      folder.GroupKey,
+			GroupKeyNonce: folder.GroupKeyNonce,
+		})
+	}
+
+	items := make([]dto.ExportedItem, 0, len(itemsDb))
+	for _, item := range itemsDb {
+		items = append(items, dto.ExportedItem{
+			ID:            item.ID,
+			FolderID:      item.FolderID,
+			OrgID:         item.OrgID,
+			Type:          item.Type,
+			EncData:       item.EncData,
+			DataNonce:     item.DataNonce,
+			EncOverview:   item.EncOverview,
+			OverviewNonce: item.OverviewNonce,
+			WrappedKey:    item.WrappedKey,
+			KeyNonce:      item.KeyNonce,
+			AccessLevel:   item.AccessLevel,
+			CreatedAt:     item.CreatedAt,
+			UpdatedAt:     item.UpdatedAt,
+		})
+	}
+
+	return &dto.UserExport{
+		UserID:     userID,
+		ExportedAt: time.Now().UTC(),
+		Folders:    folders,
+		Items:      items,
+	}, nil
+}
+
+// Fsck runs consistency checks that the schema alone does not enforce.
+func (s *AdminService) Fsck(ctx context.Context) (*dto.FsckReport, error) {
+	checks := []struct {
+		name        string
+		description string
+		run         func(context.Context) ([]uuid.UUID, error)
+	}{
+		{"items_in_deleted_folders", "Live items in a deleted folder, they block purging the folder", s.q.FsckItemsInDeletedFolders},
+		{"folders_without_owner_key", "Live folders nobody holds an OWNER key to", s.q.FsckFoldersWithoutOwnerKey},
+		{"items_without_owner_key", "Live items nobody holds an OWNER key to", s.q.FsckItemsWithoutOwnerKey},
+		{"expired_grants", "Expired keys that are no longer usable", s.q.FsckExpiredGrants},
+		{"environments_with_deleted_folder", "Environments whose folder was deleted directly", s.q.FsckEnvironmentsWithDeletedFolder},
+	}
+
+	report := &dto.FsckReport{}
+	for _, check := range checks {
+		ids, err := check.run(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("check %s failed: %w", check.name, err)
+		}
+
+		if ids == nil {
+			ids = []uuid.UUID{}
+		}
+
+		report.Checks = append(report.Checks, dto.FsckCheck{
+			Name:        check.name,
+			Description: check.description,
+			IDs:         ids,
+		})
+		report.Problems += len(ids)
+	}
+
+	return report, nil
+}
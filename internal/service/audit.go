@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+)
+
+const defaultAuditLimit = 100
+
+// RequestMeta describes the HTTP request behind a service call. It is stored
+// on audit events.
+type RequestMeta struct {
+	IP        string
+	UserAgent string
+}
+
+type requestMetaKey struct{}
+
+// WithRequestMeta returns a copy of ctx carrying meta for audit events.
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+func requestMetaFrom(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optionalUUID(s string) (*uuid.UUID, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+// ListAuditEvents returns events on resources the user owns and on anything
+// in organizations where they are ADMIN or OWNER, newest first.
+func (s *VaultService) ListAuditEvents(ctx context.Context, userID uuid.UUID, query dto.AuditQuery) (_ []dto.AuditEvent, err error) {
+	ctx, end := startOperation(ctx, "list_audit_events")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, false, nil); err != nil {
+		return nil, err
+	}
+
+	actorID, err := optionalUUID(query.ActorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actor_id: %w", err)
+	}
+
+	resourceID, err := optionalUUID(query.ResourceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource_id: %w", err)
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultAuditLimit
+	}
+
+	eventsDb, err := s.q.ListAuditEvents(ctx, db.ListAuditEventsParams{
+		UserID:       userID,
+		Since:        query.Since,
+		Until:        query.Until,
+		ActorID:      actorID,
+		ResourceID:   resourceID,
+		ResourceType: optionalString(query.ResourceType),
+		Action:       optionalString(query.Action),
+		RowLimit:     limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audit events: %w", err)
+	}
+
+	events := make([]dto.AuditEvent, len(eventsDb))
+	for i, event := range eventsDb {
+		events[i] = dto.AuditEvent{
+			Event: dto.Event{
+				ID:           event.ID,
+				ActorID:      event.ActorID,
+				Action:       event.Action,
+				ResourceType: (*dto.ResourceType)(event.ResourceType),
+				ResourceID:   event.ResourceID,
+				TargetUserID: event.TargetUserID,
+				CreatedAt:    event.CreatedAt,
+			},
+			OwnerID:   event.OwnerID,
+			OrgID:     event.OrgID,
+			IP:        event.Ip,
+			UserAgent: event.UserAgent,
+		}
+	}
+
+	return events, nil
+}
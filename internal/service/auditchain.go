@@ -0,0 +1,334 @@
+package service
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/axosec/vault/internal/metrics"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const verifyBatchSize = 1000
+
+// auditRecord holds the fields of an audit event covered by its hash.
+type auditRecord struct {
+	ID           uuid.UUID
+	ActorID      uuid.UUID
+	Action       string
+	ResourceType *string
+	ResourceID   *uuid.UUID
+	OwnerID      *uuid.UUID
+	OrgID        *uuid.UUID
+	TargetUserID *uuid.UUID
+	IP           *string
+	UserAgent    *string
+	CreatedAt    time.Time
+	Seq          int64
+}
+
+// hashAuditRecord returns SHA-256 over the previous hash and a length
+// prefixed encoding of the record. Changing the encoding breaks every
+// existing chain.
+func hashAuditRecord(prevHash []byte, r auditRecord) []byte {
+	h := sha256.New()
+
+	writeField(h, prevHash)
+	writeUint64(h, uint64(r.Seq))
+	writeField(h, r.ID[:])
+	writeField(h, r.ActorID[:])
+	writeField(h, []byte(r.Action))
+	writeOptionalString(h, r.ResourceType)
+	writeOptionalUUID(h, r.ResourceID)
+	writeOptionalUUID(h, r.OwnerID)
+	writeOptionalUUID(h, r.OrgID)
+	writeOptionalUUID(h, r.TargetUserID)
+	writeOptionalString(h, r.IP)
+	writeOptionalString(h, r.UserAgent)
+	writeUint64(h, uint64(r.CreatedAt.UnixMicro()))
+
+	return h.Sum(nil)
+}
+
+func writeUint64(h hash.Hash, v uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	h.Write(buf[:])
+}
+
+func writeField(h hash.Hash, b []byte) {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(len(b)))
+	h.Write(buf[:])
+	h.Write(b)
+}
+
+// writeNull marks an absent field so it hashes differently from an empty one.
+func writeNull(h hash.Hash) {
+	h.Write([]byte{0xff, 0xff, 0xff, 0xff})
+}
+
+func writeOptionalString(h hash.Hash, s *string) {
+	if s == nil {
+		writeNull(h)
+		return
+	}
+	writeField(h, []byte(*s))
+}
+
+func writeOptionalUUID(h hash.Hash, id *uuid.UUID) {
+	if id == nil {
+		writeNull(h)
+		return
+	}
+	writeField(h, id[:])
+}
+
+// appendAuditEvent links r to the current chain head and stores it. The
+// advisory lock is held until the surrounding transaction ends, so audit
+// writes are serialized: pass transaction scoped queries and make this the
+// last statement before commit, so the lock only covers the head read, the
+// insert and the commit.
+func appendAuditEvent(ctx context.Context, q *db.Queries, r auditRecord) error {
+	meta := requestMetaFrom(ctx)
+
+	r.ID = uuid.New()
+	r.IP = optionalString(meta.IP)
+	r.UserAgent = optionalString(meta.UserAgent)
+	// Postgres keeps microseconds, truncate so the stored value hashes the same
+	r.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	// Deliveries do not depend on the chain, queue them before taking the lock
+	if err := enqueueWebhooks(ctx, q, r); err != nil {
+		return err
+	}
+
+	waitStart := time.Now()
+	if err := q.LockAuditChain(ctx); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+	metrics.ObserveAuditLockWait(time.Since(waitStart))
+
+	var prevHash []byte
+	head, err := q.GetAuditChainHead(ctx)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to fetch audit chain head: %w", err)
+	}
+	if err == nil {
+		prevHash = head.Hash
+	}
+
+	r.Seq = head.Seq + 1
+
+	return q.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		ID:           r.ID,
+		ActorID:      r.ActorID,
+		Action:       r.Action,
+		ResourceType: r.ResourceType,
+		ResourceID:   r.ResourceID,
+		OwnerID:      r.OwnerID,
+		OrgID:        r.OrgID,
+		TargetUserID: r.TargetUserID,
+		Ip:           r.IP,
+		UserAgent:    r.UserAgent,
+		CreatedAt:    r.CreatedAt,
+		Seq:          r.Seq,
+		PrevHash:     prevHash,
+		Hash:         hashAuditRecord(prevHash, r),
+	})
+}
+
+// checkpointDigest is the message signed for a checkpoint.
+func checkpointDigest(seq int64, chainHash []byte) []byte {
+	h := sha256.New()
+	writeUint64(h, uint64(seq))
+	writeField(h, chainHash)
+	return h.Sum(nil)
+}
+
+// AuditChainService signs checkpoints of the audit chain and verifies it.
+type AuditChainService struct {
+	pool       *pgxpool.Pool
+	q          *db.Queries
+	privateKey *rsa.PrivateKey
+	publicKey  *rsa.PublicKey
+}
+
+func NewAuditChainService(pool *pgxpool.Pool, q *db.Queries, privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) *AuditChainService {
+	return &AuditChainService{
+		pool:       pool,
+		q:          q,
+		privateKey: privateKey,
+		publicKey:  publicKey,
+	}
+}
+
+// Checkpoint signs the current chain head unless it is already signed. It
+// reports whether a new checkpoint was stored.
+func (s *AuditChainService) Checkpoint(ctx context.Context) (bool, error) {
+	head, err := s.q.GetAuditChainHead(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to fetch audit chain head: %w", err)
+	}
+
+	latest, err := s.q.GetLatestAuditCheckpoint(ctx)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("failed to fetch latest checkpoint: %w", err)
+	}
+	if err == nil && latest.Seq >= head.Seq {
+		return false, nil
+	}
+
+	signature, err := rsa.SignPKCS1v15(nil, s.privateKey, crypto.SHA256, checkpointDigest(head.Seq, head.Hash))
+	if err != nil {
+		return false, fmt.Errorf("failed to sign checkpoint: %w", err)
+	}
+
+	err = s.q.CreateAuditCheckpoint(ctx, db.CreateAuditCheckpointParams{
+		Seq:       head.Seq,
+		Hash:      head.Hash,
+		Signature: signature,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to store checkpoint: %w", err)
+	}
+
+	return true, nil
+}
+
+// RunCheckpointJob calls Checkpoint every interval until ctx is done.
+func (s *AuditChainService) RunCheckpointJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Checkpoint(ctx); err != nil {
+				slog.ErrorContext(ctx, "audit checkpoint job failed", "error", err)
+			}
+		}
+	}
+}
+
+// Verify walks the whole chain, recomputing every hash and checking every
+// checkpoint signature. Breaks are reported rather than returned as errors;
+// the error is only set when the walk itself fails.
+func (s *AuditChainService) Verify(ctx context.Context) (*dto.AuditVerifyReport, error) {
+	report := &dto.AuditVerifyReport{}
+
+	unchained, err := s.q.CountUnchainedAuditEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unchained events: %w", err)
+	}
+	report.Unchained = unchained
+
+	// Every event since the first chained one was chained when written, an
+	// unchained one among them had its seq cleared
+	late, err := s.q.ListLateUnchainedAuditEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch unchained events: %w", err)
+	}
+	for _, id := range late {
+		report.Breaks = append(report.Breaks, dto.AuditChainBreak{EventID: &id, Reason: "event was written after chaining started but is not chained"})
+	}
+
+	checkpointsDb, err := s.q.ListAuditCheckpoints(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch checkpoints: %w", err)
+	}
+	report.Checkpoints = len(checkpointsDb)
+
+	checkpoints := make(map[int64][]byte, len(checkpointsDb))
+	for _, cp := range checkpointsDb {
+		err := rsa.VerifyPKCS1v15(s.publicKey, crypto.SHA256, checkpointDigest(cp.Seq, cp.Hash), cp.Signature)
+		if err != nil {
+			report.Breaks = append(report.Breaks, dto.AuditChainBreak{Seq: cp.Seq, Reason: "checkpoint signature is invalid"})
+			continue
+		}
+		checkpoints[cp.Seq] = cp.Hash
+	}
+
+	var lastSeq int64
+	var lastHash []byte
+
+	for {
+		events, err := s.q.ListChainedAuditEvents(ctx, db.ListChainedAuditEventsParams{
+			AfterSeq: lastSeq,
+			RowLimit: verifyBatchSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch audit events: %w", err)
+		}
+
+		for _, e := range events {
+			if e.Seq != lastSeq+1 {
+				report.Breaks = append(report.Breaks, dto.AuditChainBreak{Seq: e.Seq, Reason: fmt.Sprintf("events %d to %d are missing", lastSeq+1, e.Seq-1)})
+			} else if !bytes.Equal(e.PrevHash, lastHash) {
+				report.Breaks = append(report.Breaks, dto.AuditChainBreak{Seq: e.Seq, Reason: "previous hash does not match"})
+			}
+
+			computed := hashAuditRecord(e.PrevHash, auditRecord{
+				ID:           e.ID,
+				ActorID:      e.ActorID,
+				Action:       e.Action,
+				ResourceType: e.ResourceType,
+				ResourceID:   e.ResourceID,
+				OwnerID:      e.OwnerID,
+				OrgID:        e.OrgID,
+				TargetUserID: e.TargetUserID,
+				IP:           e.Ip,
+				UserAgent:    e.UserAgent,
+				CreatedAt:    e.CreatedAt,
+				Seq:          e.Seq,
+			})
+			if !bytes.Equal(computed, e.Hash) {
+				report.Breaks = append(report.Breaks, dto.AuditChainBreak{Seq: e.Seq, Reason: "event hash does not match its contents"})
+			}
+
+			if cpHash, ok := checkpoints[e.Seq]; ok && !bytes.Equal(cpHash, e.Hash) {
+				report.Breaks = append(report.Breaks, dto.AuditChainBreak{Seq: e.Seq, Reason: "event hash does not match the signed checkpoint"})
+			}
+
+			// Continue from the stored hash so one edit is reported once
+			lastSeq = e.Seq
+			lastHash = e.Hash
+			report.Checked++
+		}
+
+		if len(events) < verifyBatchSize {
+			break
+		}
+	}
+
+	for seq := range checkpoints {
+		if seq > lastSeq {
+			report.Breaks = append(report.Breaks, dto.AuditChainBreak{Seq: seq, Reason: "signed checkpoint is past the end of the chain, events were deleted"})
+		}
+	}
+
+	slices.SortStableFunc(report.Breaks, func(a, b dto.AuditChainBreak) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+
+	return report, nil
+}
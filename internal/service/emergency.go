@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EmergencyService struct {
+	pool             *pgxpool.Pool
+	q                *db.Queries
+	defaultWaitHours int
+}
+
+func NewEmergencyService(pool *pgxpool.Pool, q *db.Queries, defaultWaitHours int) *EmergencyService {
+	return &EmergencyService{
+		pool:             pool,
+		q:                q,
+		defaultWaitHours: defaultWaitHours,
+	}
+}
+
+// emergencyTransitions lists the states each status may move to.
+var emergencyTransitions = map[string][]string{
+	dto.EmergencyActive:    {dto.EmergencyRequested},
+	dto.EmergencyRequested: {dto.EmergencyReleased, dto.EmergencyRejected},
+	dto.EmergencyRejected:  {dto.EmergencyRequested},
+}
+
+func canTransition(from string, to string) bool {
+	return slices.Contains(emergencyTransitions[from], to)
+}
+
+func toEmergencyContact(c db.EmergencyContact, keyCount int64) dto.EmergencyContact {
+	contact := dto.EmergencyContact{
+		ID:          c.ID,
+		GrantorID:   c.GrantorID,
+		GranteeID:   c.GranteeID,
+		WaitHours:   c.WaitHours,
+		Status:      c.Status,
+		RequestedAt: c.RequestedAt,
+		ReleasedAt:  c.ReleasedAt,
+		KeyCount:    keyCount,
+		CreatedAt:   c.CreatedAt,
+	}
+
+	if c.Status == dto.EmergencyRequested && c.RequestedAt != nil {
+		releasesAt := c.RequestedAt.Add(time.Duration(c.WaitHours) * time.Hour)
+		contact.ReleasesAt = &releasesAt
+	}
+
+	return contact
+}
+
+func (s *EmergencyService) CreateContact(ctx context.Context, userID uuid.UUID, req dto.CreateEmergencyContactReq) (*dto.EmergencyContact, error) {
+	if req.GranteeID == userID {
+		return nil, ErrEmergencySelf
+	}
+
+	waitHours := s.defaultWaitHours
+	if req.WaitHours != nil {
+		waitHours = *req.WaitHours
+	}
+
+	contact, err := s.q.CreateEmergencyContact(ctx, db.CreateEmergencyContactParams{
+		GrantorID: userID,
+		GranteeID: req.GranteeID,
+		WaitHours: int32(waitHours),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrEmergencyContactExists
+		}
+		return nil, fmt.Errorf("failed to create emergency contact: %w", err)
+	}
+
+	resp := toEmergencyContact(contact, 0)
+	return &resp, nil
+}
+
+func (s *EmergencyService) ListContacts(ctx context.Context, userID uuid.UUID) (*dto.EmergencyContacts, error) {
+	grantorDb, err := s.q.GetEmergencyContactsByGrantor(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch emergency contacts: %w", err)
+	}
+
+	granteeDb, err := s.q.GetEmergencyContactsByGrantee(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch emergency grants: %w", err)
+	}
+
+	contacts := &dto.EmergencyContacts{
+		AsGrantor: make([]dto.EmergencyContact, len(grantorDb)),
+		AsGrantee: make([]dto.EmergencyContact, len(granteeDb)),
+	}
+
+	for i, c := range grantorDb {
+		contacts.AsGrantor[i] = toEmergencyContact(db.EmergencyContact{
+			ID:          c.ID,
+			GrantorID:   c.GrantorID,
+			GranteeID:   c.GranteeID,
+			WaitHours:   c.WaitHours,
+			Status:      c.Status,
+			RequestedAt: c.RequestedAt,
+			ReleasedAt:  c.ReleasedAt,
+			CreatedAt:   c.CreatedAt,
+			UpdatedAt:   c.UpdatedAt,
+		}, c.KeyCount)
+	}
+
+	for i, c := range granteeDb {
+		contacts.AsGrantee[i] = toEmergencyContact(db.EmergencyContact{
+			ID:          c.ID,
+			GrantorID:   c.GrantorID,
+			GranteeID:   c.GranteeID,
+			WaitHours:   c.WaitHours,
+			Status:      c.Status,
+			RequestedAt: c.RequestedAt,
+			ReleasedAt:  c.ReleasedAt,
+			CreatedAt:   c.CreatedAt,
+			UpdatedAt:   c.UpdatedAt,
+		}, c.KeyCount)
+	}
+
+	return contacts, nil
+}
+
+func (s *EmergencyService) DeleteContact(ctx context.Context, userID uuid.UUID, contactID uuid.UUID) error {
+	rowsAffected, err := s.q.DeleteEmergencyContact(ctx, db.DeleteEmergencyContactParams{
+		ID:        contactID,
+		GrantorID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete emergency contact: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrEmergencyContactNotFound
+	}
+
+	return nil
+}
+
+// SetKeys stores resource keys the grantor pre-wrapped for the contact. The
+// grantor must own every resource.
+func (s *EmergencyService) SetKeys(ctx context.Context, userID uuid.UUID, contactID uuid.UUID, req dto.SetEmergencyKeysReq) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	contact, err := s.lockContact(ctx, qtx, contactID)
+	if err != nil {
+		return err
+	}
+
+	if contact.GrantorID != userID {
+		return ErrEmergencyContactNotFound
+	}
+
+	for _, key := range req.Keys {
+		if err := checkOwner(ctx, qtx, userID, key.ResourceID, key.ResourceType); err != nil {
+			return err
+		}
+
+		switch key.ResourceType {
+		case dto.TypeFolder:
+			err = qtx.UpsertEmergencyFolderKey(ctx, db.UpsertEmergencyFolderKeyParams{
+				ContactID: contactID,
+				FolderID:  &key.ResourceID,
+				EncKey:    key.EncKey,
+				Nonce:     key.KeyNonce,
+			})
+		case dto.TypeItem:
+			err = qtx.UpsertEmergencyItemKey(ctx, db.UpsertEmergencyItemKeyParams{
+				ContactID: contactID,
+				ItemID:    &key.ResourceID,
+				EncKey:    key.EncKey,
+				Nonce:     key.KeyNonce,
+			})
+		default:
+			return ErrInvalidResourceType
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to store emergency key: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// RequestAccess starts the waiting period. Only the grantee may call it.
+func (s *EmergencyService) RequestAccess(ctx context.Context, userID uuid.UUID, contactID uuid.UUID) error {
+	return s.transition(ctx, userID, contactID, dto.EmergencyRequested, dto.EventEmergencyRequested, func(c db.EmergencyContact) bool {
+		return c.GranteeID == userID
+	})
+}
+
+// ApproveRequest releases a pending request before its waiting period ends.
+func (s *EmergencyService) ApproveRequest(ctx context.Context, userID uuid.UUID, contactID uuid.UUID) error {
+	return s.transition(ctx, userID, contactID, dto.EmergencyReleased, dto.EventEmergencyApproved, func(c db.EmergencyContact) bool {
+		return c.GrantorID == userID
+	})
+}
+
+func (s *EmergencyService) RejectRequest(ctx context.Context, userID uuid.UUID, contactID uuid.UUID) error {
+	return s.transition(ctx, userID, contactID, dto.EmergencyRejected, dto.EventEmergencyRejected, func(c db.EmergencyContact) bool {
+		return c.GrantorID == userID
+	})
+}
+
+func (s *EmergencyService) lockContact(ctx context.Context, q *db.Queries, contactID uuid.UUID) (db.EmergencyContact, error) {
+	contact, err := q.GetEmergencyContactForUpdate(ctx, contactID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return contact, ErrEmergencyContactNotFound
+		}
+		return contact, fmt.Errorf("failed to fetch emergency contact: %w", err)
+	}
+
+	return contact, nil
+}
+
+// transition moves a contact to the next status if allowed(contact) holds
+// and the state machine permits it, and records action by actorID.
+// Releasing copies the pre-wrapped keys.
+func (s *EmergencyService) transition(ctx context.Context, actorID uuid.UUID, contactID uuid.UUID, to string, action string, allowed func(db.EmergencyContact) bool) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	contact, err := s.lockContact(ctx, qtx, contactID)
+	if err != nil {
+		return err
+	}
+
+	if !allowed(contact) {
+		return ErrEmergencyContactNotFound
+	}
+
+	if !canTransition(contact.Status, to) {
+		return ErrInvalidTransition
+	}
+
+	released, err := s.setStatus(ctx, qtx, contactID, to)
+	if err != nil {
+		return err
+	}
+
+	if err := recordEmergencyEvent(ctx, qtx, actorID, action, contact, released); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// releasedResource is a folder or item whose key a release granted.
+type releasedResource struct {
+	id           uuid.UUID
+	resourceType dto.ResourceType
+}
+
+// setStatus stores the new status. On release it also copies the contact's
+// keys into regular grants and returns the resources they cover.
+func (s *EmergencyService) setStatus(ctx context.Context, q *db.Queries, contactID uuid.UUID, status string) ([]releasedResource, error) {
+	var released []releasedResource
+
+	if status == dto.EmergencyReleased {
+		folderIDs, err := q.ReleaseEmergencyFolderKeys(ctx, contactID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to release emergency folder keys: %w", err)
+		}
+
+		itemIDs, err := q.ReleaseEmergencyItemKeys(ctx, contactID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to release emergency item keys: %w", err)
+		}
+
+		for _, id := range folderIDs {
+			released = append(released, releasedResource{id: *id, resourceType: dto.TypeFolder})
+		}
+		for _, id := range itemIDs {
+			released = append(released, releasedResource{id: *id, resourceType: dto.TypeItem})
+		}
+	}
+
+	err := q.SetEmergencyContactStatus(ctx, db.SetEmergencyContactStatusParams{
+		Status: status,
+		ID:     contactID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update emergency contact: %w", err)
+	}
+
+	return released, nil
+}
+
+// recordEmergencyEvent stores action on the contact under the grantor, and a
+// SHARED event for every resource a release granted to the grantee.
+func recordEmergencyEvent(ctx context.Context, q *db.Queries, actorID uuid.UUID, action string, contact db.EmergencyContact, released []releasedResource) error {
+	err := appendAuditEvent(ctx, q, auditRecord{
+		ActorID:      actorID,
+		Action:       action,
+		OwnerID:      &contact.GrantorID,
+		TargetUserID: &contact.GranteeID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", action, err)
+	}
+
+	for _, r := range released {
+		ownerID, err := resourceOwner(ctx, q, r.id, r.resourceType)
+		if err != nil {
+			return err
+		}
+
+		if err := recordResourceEvent(ctx, q, actorID, dto.EventShared, r.id, r.resourceType, ownerID, &contact.GranteeID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReleaseDueRequests releases every pending request whose waiting period has
+// ended and returns how many were released. The releases are recorded with
+// a nil actor, as no user made them.
+func (s *EmergencyService) ReleaseDueRequests(ctx context.Context) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	due, err := qtx.ListDueEmergencyRequests(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch due emergency requests: %w", err)
+	}
+
+	type release struct {
+		contact  db.EmergencyContact
+		released []releasedResource
+	}
+	releases := make([]release, 0, len(due))
+
+	for _, contactID := range due {
+		contact, err := s.lockContact(ctx, qtx, contactID)
+		if err != nil {
+			return 0, err
+		}
+
+		released, err := s.setStatus(ctx, qtx, contactID, dto.EmergencyReleased)
+		if err != nil {
+			return 0, err
+		}
+
+		releases = append(releases, release{contact: contact, released: released})
+	}
+
+	// Audit events go last, they hold the chain lock until commit
+	for _, r := range releases {
+		if err := recordEmergencyEvent(ctx, qtx, uuid.Nil, dto.EventEmergencyReleased, r.contact, r.released); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return len(due), nil
+}
+
+// RunReleaseJob calls ReleaseDueRequests every interval until ctx is done.
+func (s *EmergencyService) RunReleaseJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := s.ReleaseDueRequests(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "emergency release job failed", "error", err)
+				continue
+			}
+			if released > 0 {
+				slog.InfoContext(ctx, "emergency release job released requests", "released", released)
+			}
+		}
+	}
+}
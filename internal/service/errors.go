@@ -0,0 +1,52 @@
+package service
+
+import "errors"
+
+var (
+	ErrAccessDenied        = errors.New("resource not found or access denied")
+	ErrReadOnly            = errors.New("requires WRITE or OWNER permission")
+	ErrInvalidResourceType = errors.New("invalid resource type")
+	ErrGrantNotFound       = errors.New("grant not found")
+	ErrLastOwner           = errors.New("resource must keep at least one OWNER")
+	ErrSelfTransfer        = errors.New("cannot transfer ownership to the current owner")
+	ErrTargetHasNoKey      = errors.New("target user has no key to the resource")
+	ErrOwnerCannotLeave    = errors.New("owners cannot leave their own resource")
+
+	ErrOrgNotFound      = errors.New("organization not found or not a member")
+	ErrInsufficientRole = errors.New("insufficient organization role")
+	ErrLastOrgOwner     = errors.New("organization must keep at least one OWNER")
+	ErrOrgNotEmpty      = errors.New("organization still owns folders or items")
+	ErrMemberExists     = errors.New("user is already a member of the organization")
+	ErrMemberNotFound   = errors.New("organization member not found")
+	ErrGroupNotFound    = errors.New("group not found")
+	ErrGroupOrgMismatch = errors.New("folder does not belong to the group's organization")
+	ErrItemNotInFolder  = errors.New("item is not in the shared folder")
+
+	ErrEmergencyContactNotFound = errors.New("emergency contact not found")
+	ErrEmergencySelf            = errors.New("cannot name yourself as emergency contact")
+	ErrEmergencyContactExists   = errors.New("emergency contact already exists")
+	ErrInvalidTransition        = errors.New("invalid state transition")
+
+	ErrRecoveryNotEnabled      = errors.New("organization has no recovery policy")
+	ErrNotEnrolled             = errors.New("member is not enrolled in account recovery")
+	ErrRecoveryRequestNotFound = errors.New("recovery request not found")
+	ErrSecondAdminRequired     = errors.New("recovery must be approved by a different admin")
+
+	ErrWebhookNotFound   = errors.New("webhook not found")
+	ErrInvalidWebhookURL = errors.New("webhook url must be http(s) and publicly routable")
+	ErrDeliveryNotFound  = errors.New("webhook delivery not found")
+
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	ErrAPITokenNotFound       = errors.New("api token not found")
+	ErrInvalidAPIToken        = errors.New("invalid, expired or revoked api token")
+	ErrTokenExpiry            = errors.New("token expiry must be in the future and within the allowed lifetime")
+	ErrOutOfScope             = errors.New("api token scope does not allow this operation")
+
+	ErrProjectNotFound        = errors.New("project not found")
+	ErrProjectNotEmpty        = errors.New("project still has environments")
+	ErrEnvironmentNotFound    = errors.New("environment not found")
+	ErrEnvironmentExists      = errors.New("environment already exists in the project")
+	ErrInvalidEnvironmentName = errors.New("environment names may only contain letters, digits, '-' and '_'")
+
+	ErrTokenWithoutID = errors.New("token has no jti claim and cannot be revoked on its own")
+)
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// resourceOwner returns the owner_id of a folder or item.
+func resourceOwner(ctx context.Context, q *db.Queries, resourceID uuid.UUID, resourceType dto.ResourceType) (uuid.UUID, error) {
+	var ownerID uuid.UUID
+	var err error
+
+	switch resourceType {
+	case dto.TypeFolder:
+		ownerID, err = q.GetFolderOwner(ctx, resourceID)
+	case dto.TypeItem:
+		ownerID, err = q.GetItemOwner(ctx, resourceID)
+	default:
+		return uuid.Nil, ErrInvalidResourceType
+	}
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrAccessDenied
+		}
+		return uuid.Nil, fmt.Errorf("failed to fetch resource owner: %w", err)
+	}
+
+	return ownerID, nil
+}
+
+// recordResourceEvent stores an event about a resource. Pass the transaction
+// scoped queries so the event commits together with the change it describes.
+func recordResourceEvent(ctx context.Context, q *db.Queries, actorID uuid.UUID, action string, resourceID uuid.UUID, resourceType dto.ResourceType, ownerID uuid.UUID, targetUserID *uuid.UUID) error {
+	typeStr := string(resourceType)
+
+	orgID, err := q.GetResourceOrgID(ctx, resourceID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to fetch resource organization: %w", err)
+	}
+
+	err = appendAuditEvent(ctx, q, auditRecord{
+		ActorID:      actorID,
+		Action:       action,
+		ResourceType: &typeStr,
+		ResourceID:   &resourceID,
+		OwnerID:      &ownerID,
+		OrgID:        orgID,
+		TargetUserID: targetUserID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", action, err)
+	}
+
+	return nil
+}
+
+func (s *VaultService) ListResourceEvents(ctx context.Context, userID uuid.UUID, resourceID uuid.UUID, resourceType dto.ResourceType) (_ []dto.Event, err error) {
+	ctx, end := startOperation(ctx, "list_resource_events")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, false, &resourceID); err != nil {
+		return nil, err
+	}
+
+	if err := checkOwner(ctx, s.q, userID, resourceID, resourceType); err != nil {
+		return nil, err
+	}
+
+	eventsDb, err := s.q.GetResourceEvents(ctx, &resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch events: %w", err)
+	}
+
+	return toEvents(eventsDb), nil
+}
+
+func toEvents(eventsDb []db.GetResourceEventsRow) []dto.Event {
+	events := make([]dto.Event, len(eventsDb))
+	for i, event := range eventsDb {
+		events[i] = dto.Event{
+			ID:           event.ID,
+			ActorID:      event.ActorID,
+			Action:       event.Action,
+			ResourceType: (*dto.ResourceType)(event.ResourceType),
+			ResourceID:   event.ResourceID,
+			TargetUserID: event.TargetUserID,
+			CreatedAt:    event.CreatedAt,
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// checkOwner returns ErrAccessDenied unless userID owns the given resource.
+func checkOwner(ctx context.Context, q *db.Queries, userID uuid.UUID, resourceID uuid.UUID, resourceType dto.ResourceType) error {
+	var err error
+
+	switch resourceType {
+	case dto.TypeFolder:
+		_, err = q.IsFolderOwner(ctx, db.IsFolderOwnerParams{
+			ID:      resourceID,
+			OwnerID: userID,
+		})
+	case dto.TypeItem:
+		_, err = q.IsItemOwner(ctx, db.IsItemOwnerParams{
+			ID:      resourceID,
+			OwnerID: userID,
+		})
+	default:
+		return ErrInvalidResourceType
+	}
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAccessDenied
+		}
+		return fmt.Errorf("failed to check ownership: %w", err)
+	}
+
+	return nil
+}
+
+// ensureOwnerRemains returns ErrLastOwner if setting targetUserID's grant to
+// accessLevel, or removing it when accessLevel is empty, would leave the
+// resource without any OWNER grant. It locks the current OWNER rows, so it
+// must run inside the mutating transaction.
+func ensureOwnerRemains(ctx context.Context, q *db.Queries, resourceID uuid.UUID, targetUserID uuid.UUID, accessLevel string) error {
+	if accessLevel == "OWNER" {
+		return nil
+	}
+
+	owners, err := q.LockResourceOwners(ctx, &resourceID)
+	if err != nil {
+		return fmt.Errorf("failed to lock owner grants: %w", err)
+	}
+
+	for _, owner := range owners {
+		if owner != targetUserID {
+			return nil
+		}
+	}
+
+	if len(owners) > 0 {
+		return ErrLastOwner
+	}
+
+	return nil
+}
+
+func (s *VaultService) ListGrants(ctx context.Context, userID uuid.UUID, resourceID uuid.UUID, resourceType dto.ResourceType) (_ []dto.Grant, err error) {
+	ctx, end := startOperation(ctx, "list_grants")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, false, &resourceID); err != nil {
+		return nil, err
+	}
+
+	if err := checkOwner(ctx, s.q, userID, resourceID, resourceType); err != nil {
+		return nil, err
+	}
+
+	grantsDb, err := s.q.GetResourceGrants(ctx, &resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch grants: %w", err)
+	}
+
+	grants := make([]dto.Grant, len(grantsDb))
+	for i, grant := range grantsDb {
+		grants[i] = dto.Grant{
+			UserID:      grant.UserID,
+			AccessLevel: grant.AccessLevel,
+			CreatedAt:   grant.CreatedAt,
+			GrantedBy:   grant.GrantedBy,
+			ExpiresAt:   grant.ExpiresAt,
+			GroupID:     grant.GroupID,
+		}
+	}
+
+	return grants, nil
+}
+
+func (s *VaultService) ListSharedByMe(ctx context.Context, userID uuid.UUID) (_ []dto.SharedGrant, err error) {
+	ctx, end := startOperation(ctx, "list_shared_by_me")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, false, nil); err != nil {
+		return nil, err
+	}
+
+	grantsDb, err := s.q.GetSharedByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shared resources: %w", err)
+	}
+
+	grants := make([]dto.SharedGrant, len(grantsDb))
+	for i, grant := range grantsDb {
+		grants[i] = dto.SharedGrant{
+			ResourceID:   grant.ResourceID,
+			ResourceType: dto.ResourceType(grant.ResourceType),
+			Grant: dto.Grant{
+				UserID:      grant.UserID,
+				AccessLevel: grant.AccessLevel,
+				CreatedAt:   grant.CreatedAt,
+				GrantedBy:   grant.GrantedBy,
+				ExpiresAt:   grant.ExpiresAt,
+			},
+		}
+	}
+
+	return grants, nil
+}
+
+// ListSharedWithMe returns the folders and items the user can access but does not own.
+func (s *VaultService) ListSharedWithMe(ctx context.Context, userID uuid.UUID) (_ *dto.SharedWithMe, err error) {
+	ctx, end := startOperation(ctx, "list_shared_with_me")
+	defer end(&err)
+
+	foldersDb, err := s.q.GetFoldersSharedWithUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shared folders: %w", err)
+	}
+
+	itemsDb, err := s.q.GetItemsSharedWithUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shared items: %w", err)
+	}
+
+	scope, _ := tokenScopeFrom(ctx)
+
+	shared := &dto.SharedWithMe{
+		Folders: make([]dto.SharedFolder, 0, len(foldersDb)),
+		Items:   make([]dto.SharedItem, 0, len(itemsDb)),
+	}
+
+	// A user in several groups sharing the same resource gets one row per group
+	seen := make(map[uuid.UUID]bool, len(foldersDb)+len(itemsDb))
+
+	for _, folder := range foldersDb {
+		if seen[folder.ID] || !scope.AllowsFolder(folder.ID) {
+			continue
+		}
+		seen[folder.ID] = true
+
+		shared.Folders = append(shared.Folders, dto.SharedFolder{
+			FolderSummary: dto.FolderSummary{
+				ID:          folder.ID,
+				EncMetadata: folder.EncMetadata,
+				Nonce:       folder.Nonce,
+				WrappedKey:  folder.WrappedKey,
+				KeyNonce:    folder.KeyNonce,
+				AccessLevel: folder.AccessLevel,
+
+				GroupID:       folder.GroupID,
+				GroupKey:      folder.GroupKey,
+				GroupKeyNonce: folder.GroupKeyNonce,
+			},
+			OwnerID:  folder.OwnerID,
+			SharedAt: folder.SharedAt,
+		})
+	}
+
+	for _, item := range itemsDb {
+		if seen[item.ID] || !scope.AllowsItem(item.ID, item.FolderID) {
+			continue
+		}
+		seen[item.ID] = true
+
+		shared.Items = append(shared.Items, dto.SharedItem{
+			ItemSummary: dto.ItemSummary{
+				ID:            item.ID,
+				Type:          item.Type,
+				EncOverview:   item.EncOverview,
+				OverviewNonce: item.OverviewNonce,
+				WrappedKey:    item.WrappedKey,
+				KeyNonce:      item.KeyNonce,
+				AccessLevel:   item.AccessLevel,
+				UpdatedAt:     item.UpdatedAt,
+				GroupID:       item.GroupID,
+				GroupKey:      item.GroupKey,
+				GroupKeyNonce: item.GroupKeyNonce,
+			},
+			OwnerID:  item.OwnerID,
+			FolderID: item.FolderID,
+			SharedAt: item.SharedAt,
+		})
+	}
+
+	return shared, nil
+}
+
+func (s *VaultService) UpdateGrant(ctx context.Context, ownerID uuid.UUID, resourceID uuid.UUID, resourceType dto.ResourceType, targetUserID uuid.UUID, req dto.UpdateGrantReq) (err error) {
+	ctx, end := startOperation(ctx, "update_grant")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, &resourceID); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if err := checkOwner(ctx, qtx, ownerID, resourceID, resourceType); err != nil {
+		return err
+	}
+
+	if err := ensureOwnerRemains(ctx, qtx, resourceID, targetUserID, req.AccessLevel); err != nil {
+		return err
+	}
+
+	rowsAffected, err := qtx.UpdateGrantAccessLevel(ctx, db.UpdateGrantAccessLevelParams{
+		AccessLevel: req.AccessLevel,
+		UserID:      targetUserID,
+		ResourceID:  &resourceID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update grant: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrGrantNotFound
+	}
+
+	resourceOwnerID, err := resourceOwner(ctx, qtx, resourceID, resourceType)
+	if err != nil {
+		return err
+	}
+
+	if err := recordResourceEvent(ctx, qtx, ownerID, dto.EventGrantUpdated, resourceID, resourceType, resourceOwnerID, &targetUserID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *VaultService) TransferOwnership(ctx context.Context, ownerID uuid.UUID, resourceID uuid.UUID, resourceType dto.ResourceType, req dto.TransferOwnershipReq) (err error) {
+	ctx, end := startOperation(ctx, "transfer_ownership")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, &resourceID); err != nil {
+		return err
+	}
+
+	if req.TargetUserID == ownerID {
+		return ErrSelfTransfer
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if err := checkOwner(ctx, qtx, ownerID, resourceID, resourceType); err != nil {
+		return err
+	}
+
+	// The caller may be an org admin rather than the owner
+	previousOwnerID, err := resourceOwner(ctx, qtx, resourceID, resourceType)
+	if err != nil {
+		return err
+	}
+
+	if req.TargetUserID == previousOwnerID {
+		return ErrSelfTransfer
+	}
+
+	// The target must already hold a wrapped key, we cannot create one for them
+	rowsAffected, err := qtx.UpdateGrantAccessLevel(ctx, db.UpdateGrantAccessLevelParams{
+		AccessLevel: "OWNER",
+		UserID:      req.TargetUserID,
+		ResourceID:  &resourceID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to promote target grant: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrTargetHasNoKey
+	}
+
+	switch resourceType {
+	case dto.TypeFolder:
+		rowsAffected, err = qtx.TransferFolderOwnership(ctx, db.TransferFolderOwnershipParams{
+			NewOwnerID: req.TargetUserID,
+			ID:         resourceID,
+			OwnerID:    ownerID,
+		})
+	case dto.TypeItem:
+		rowsAffected, err = qtx.TransferItemOwnership(ctx, db.TransferItemOwnershipParams{
+			NewOwnerID: req.TargetUserID,
+			ID:         resourceID,
+			OwnerID:    ownerID,
+		})
+	default:
+		return ErrInvalidResourceType
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to transfer ownership: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAccessDenied
+	}
+
+	if req.DemoteTo != "" {
+		_, err = qtx.UpdateGrantAccessLevel(ctx, db.UpdateGrantAccessLevelParams{
+			AccessLevel: req.DemoteTo,
+			UserID:      previousOwnerID,
+			ResourceID:  &resourceID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to demote former owner: %w", err)
+		}
+	}
+
+	// Filed under the former owner, the new one is the target
+	if err := recordResourceEvent(ctx, qtx, ownerID, dto.EventOwnershipTransferred, resourceID, resourceType, previousOwnerID, &req.TargetUserID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// LeaveShare removes the caller's own grant to a resource shared with them.
+func (s *VaultService) LeaveShare(ctx context.Context, userID uuid.UUID, resourceID uuid.UUID, resourceType dto.ResourceType) (err error) {
+	ctx, end := startOperation(ctx, "leave_share")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, &resourceID); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	accessLevel, err := qtx.GetGrantAccessLevel(ctx, db.GetGrantAccessLevelParams{
+		UserID:     userID,
+		ResourceID: &resourceID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrGrantNotFound
+		}
+		return fmt.Errorf("failed to fetch grant: %w", err)
+	}
+
+	if accessLevel == "OWNER" {
+		return ErrOwnerCannotLeave
+	}
+
+	ownerID, err := resourceOwner(ctx, qtx, resourceID, resourceType)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.RevokeUserAccess(ctx, db.RevokeUserAccessParams{
+		UserID:   userID,
+		FolderID: &resourceID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove grant: %w", err)
+	}
+
+	if err := recordResourceEvent(ctx, qtx, userID, dto.EventShareLeft, resourceID, resourceType, ownerID, &userID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
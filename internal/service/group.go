@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// groupOrg returns the organization a group belongs to.
+func groupOrg(ctx context.Context, q *db.Queries, groupID uuid.UUID) (uuid.UUID, error) {
+	group, err := q.GetGroup(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrGroupNotFound
+		}
+		return uuid.Nil, fmt.Errorf("failed to fetch group: %w", err)
+	}
+
+	return group.OrgID, nil
+}
+
+// checkGroupInOrg returns ErrGroupNotFound unless the group belongs to orgID.
+func checkGroupInOrg(ctx context.Context, q *db.Queries, orgID uuid.UUID, groupID uuid.UUID) error {
+	groupOrgID, err := groupOrg(ctx, q, groupID)
+	if err != nil {
+		return err
+	}
+
+	if groupOrgID != orgID {
+		return ErrGroupNotFound
+	}
+
+	return nil
+}
+
+func (s *OrgService) CreateGroup(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, req dto.CreateGroupReq) (*dto.GroupResponse, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := requireOrgRole(ctx, qtx, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	group, err := qtx.CreateGroup(ctx, db.CreateGroupParams{
+		OrgID:     orgID,
+		Name:      req.Name,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+
+	err = qtx.AddGroupMember(ctx, db.AddGroupMemberParams{
+		GroupID: group.ID,
+		UserID:  userID,
+		EncKey:  req.EncKey,
+		Nonce:   req.KeyNonce,
+		AddedBy: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add group creator: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return &dto.GroupResponse{
+		ID:          group.ID,
+		OrgID:       group.OrgID,
+		Name:        group.Name,
+		CreatedBy:   group.CreatedBy,
+		CreatedAt:   group.CreatedAt,
+		MemberCount: 1,
+	}, nil
+}
+
+func (s *OrgService) ListGroups(ctx context.Context, userID uuid.UUID, orgID uuid.UUID) ([]dto.GroupResponse, error) {
+	if _, err := orgRole(ctx, s.q, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	groupsDb, err := s.q.GetOrgGroups(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+
+	groups := make([]dto.GroupResponse, len(groupsDb))
+	for i, group := range groupsDb {
+		groups[i] = dto.GroupResponse{
+			ID:          group.ID,
+			OrgID:       group.OrgID,
+			Name:        group.Name,
+			CreatedBy:   group.CreatedBy,
+			CreatedAt:   group.CreatedAt,
+			MemberCount: group.MemberCount,
+		}
+	}
+
+	return groups, nil
+}
+
+func (s *OrgService) DeleteGroup(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, groupID uuid.UUID) error {
+	if _, err := requireOrgRole(ctx, s.q, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		return err
+	}
+
+	rowsAffected, err := s.q.DeleteGroup(ctx, db.DeleteGroupParams{
+		ID:    groupID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrGroupNotFound
+	}
+
+	return nil
+}
+
+func (s *OrgService) ListGroupMembers(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, groupID uuid.UUID) ([]dto.GroupMember, error) {
+	if _, err := orgRole(ctx, s.q, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := checkGroupInOrg(ctx, s.q, orgID, groupID); err != nil {
+		return nil, err
+	}
+
+	membersDb, err := s.q.GetGroupMembers(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch group members: %w", err)
+	}
+
+	members := make([]dto.GroupMember, len(membersDb))
+	for i, member := range membersDb {
+		members[i] = dto.GroupMember{
+			UserID:    member.UserID,
+			AddedBy:   member.AddedBy,
+			CreatedAt: member.CreatedAt,
+		}
+	}
+
+	return members, nil
+}
+
+// AddGroupMember stores the group key wrapped for a new member. Folder keys
+// shared with the group are untouched, so nothing else needs re-wrapping.
+func (s *OrgService) AddGroupMember(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, groupID uuid.UUID, req dto.AddGroupMemberReq) error {
+	if _, err := requireOrgRole(ctx, s.q, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		return err
+	}
+
+	if err := checkGroupInOrg(ctx, s.q, orgID, groupID); err != nil {
+		return err
+	}
+
+	if _, err := orgRole(ctx, s.q, orgID, req.UserID); err != nil {
+		if errors.Is(err, ErrOrgNotFound) {
+			return ErrMemberNotFound
+		}
+		return err
+	}
+
+	err := s.q.AddGroupMember(ctx, db.AddGroupMemberParams{
+		GroupID: groupID,
+		UserID:  req.UserID,
+		EncKey:  req.EncKey,
+		Nonce:   req.KeyNonce,
+		AddedBy: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+
+	return nil
+}
+
+func (s *OrgService) RemoveGroupMember(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, groupID uuid.UUID, memberID uuid.UUID) error {
+	if memberID != userID {
+		if _, err := requireOrgRole(ctx, s.q, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+			return err
+		}
+	}
+
+	if err := checkGroupInOrg(ctx, s.q, orgID, groupID); err != nil {
+		return err
+	}
+
+	rowsAffected, err := s.q.RemoveGroupMember(ctx, db.RemoveGroupMemberParams{
+		GroupID: groupID,
+		UserID:  memberID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
+
+// ShareFolderWithGroup grants every member of a group access to a folder
+// through a single folder key wrapped with the group key, and to the listed
+// items of the folder through item keys wrapped the same way.
+func (s *VaultService) ShareFolderWithGroup(ctx context.Context, ownerID uuid.UUID, req dto.GroupShareReq) (err error) {
+	ctx, end := startOperation(ctx, "share_folder_with_group")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, &req.FolderID); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if err := checkOwner(ctx, qtx, ownerID, req.FolderID, dto.TypeFolder); err != nil {
+		return err
+	}
+
+	orgID, err := groupOrg(ctx, qtx, req.GroupID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := orgRole(ctx, qtx, orgID, ownerID); err != nil {
+		if errors.Is(err, ErrOrgNotFound) {
+			return ErrGroupNotFound
+		}
+		return err
+	}
+
+	// Groups only hold folders of their own organization, personal folders included
+	folderOrgID, err := qtx.GetResourceOrgID(ctx, req.FolderID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch folder organization: %w", err)
+	}
+	if folderOrgID == nil || *folderOrgID != orgID {
+		return ErrGroupOrgMismatch
+	}
+
+	err = qtx.CreateGroupFolderKey(ctx, db.CreateGroupFolderKeyParams{
+		GroupID:     req.GroupID,
+		FolderID:    &req.FolderID,
+		EncKey:      req.EncKey,
+		Nonce:       req.KeyNonce,
+		AccessLevel: req.AccessLevel,
+		GrantedBy:   ownerID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to share folder with group: %w", err)
+	}
+
+	for _, item := range req.Items {
+		itemFolderID, err := qtx.GetItemFolderID(ctx, item.ItemID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to fetch item folder: %w", err)
+		}
+		if itemFolderID == nil || *itemFolderID != req.FolderID {
+			return ErrItemNotInFolder
+		}
+
+		err = qtx.CreateGroupItemKey(ctx, db.CreateGroupItemKeyParams{
+			GroupID:     req.GroupID,
+			ItemID:      &item.ItemID,
+			EncKey:      item.EncKey,
+			Nonce:       item.KeyNonce,
+			AccessLevel: req.AccessLevel,
+			GrantedBy:   ownerID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to share item with group: %w", err)
+		}
+	}
+
+	if err := recordGroupEvent(ctx, qtx, ownerID, dto.EventGroupShared, req.FolderID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *VaultService) RevokeGroupAccess(ctx context.Context, ownerID uuid.UUID, req dto.GroupRevokeReq) (err error) {
+	ctx, end := startOperation(ctx, "revoke_group_access")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, &req.FolderID); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if err := checkOwner(ctx, qtx, ownerID, req.FolderID, dto.TypeFolder); err != nil {
+		return err
+	}
+
+	rowsAffected, err := qtx.RevokeGroupFolderKey(ctx, db.RevokeGroupFolderKeyParams{
+		GroupID:  req.GroupID,
+		FolderID: &req.FolderID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke group access: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrGrantNotFound
+	}
+
+	err = qtx.RevokeGroupFolderItemKeys(ctx, db.RevokeGroupFolderItemKeysParams{
+		GroupID:  req.GroupID,
+		FolderID: &req.FolderID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke group item access: %w", err)
+	}
+
+	if err := recordGroupEvent(ctx, qtx, ownerID, dto.EventGroupRevoked, req.FolderID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// recordGroupEvent stores a group share event on the folder.
+func recordGroupEvent(ctx context.Context, q *db.Queries, actorID uuid.UUID, action string, folderID uuid.UUID) error {
+	ownerID, err := resourceOwner(ctx, q, folderID, dto.TypeFolder)
+	if err != nil {
+		return err
+	}
+
+	return recordResourceEvent(ctx, q, actorID, action, folderID, dto.TypeFolder, ownerID, nil)
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+
+	"github.com/axosec/vault/internal/metrics"
+	"github.com/axosec/vault/internal/tracing"
+)
+
+// startOperation starts a span for a VaultService method. Defer the returned
+// func with a pointer to the named error result; it records the error on the
+// span and in the operation error metric.
+func startOperation(ctx context.Context, operation string) (context.Context, func(*error)) {
+	ctx, span := tracing.Start(ctx, "vault."+operation)
+
+	return ctx, func(err *error) {
+		metrics.ObserveOperation(operation, err)
+		tracing.End(span, *err)
+	}
+}
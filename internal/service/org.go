@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OrgService struct {
+	pool *pgxpool.Pool
+	q    *db.Queries
+}
+
+func NewOrgService(pool *pgxpool.Pool, q *db.Queries) *OrgService {
+	return &OrgService{
+		pool: pool,
+		q:    q,
+	}
+}
+
+// orgRole returns the user's role in the organization, or ErrOrgNotFound if
+// they are not a member.
+func orgRole(ctx context.Context, q *db.Queries, orgID uuid.UUID, userID uuid.UUID) (string, error) {
+	role, err := q.GetOrgMemberRole(ctx, db.GetOrgMemberRoleParams{
+		OrgID:  orgID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrOrgNotFound
+		}
+		return "", fmt.Errorf("failed to fetch organization role: %w", err)
+	}
+
+	return role, nil
+}
+
+// requireOrgRole returns ErrInsufficientRole unless the user holds one of the given roles.
+func requireOrgRole(ctx context.Context, q *db.Queries, orgID uuid.UUID, userID uuid.UUID, allowed ...string) (string, error) {
+	role, err := orgRole(ctx, q, orgID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if !slices.Contains(allowed, role) {
+		return "", ErrInsufficientRole
+	}
+
+	return role, nil
+}
+
+// ensureOrgOwnerRemains returns ErrLastOrgOwner if removing one OWNER would
+// leave the organization without any. It must run inside a transaction.
+func ensureOrgOwnerRemains(ctx context.Context, q *db.Queries, orgID uuid.UUID) error {
+	owners, err := q.LockOrgOwners(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to lock organization owners: %w", err)
+	}
+
+	if len(owners) <= 1 {
+		return ErrLastOrgOwner
+	}
+
+	return nil
+}
+
+func (s *OrgService) CreateOrg(ctx context.Context, userID uuid.UUID, req dto.CreateOrgReq) (*dto.OrgResponse, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	org, err := qtx.CreateOrganization(ctx, db.CreateOrganizationParams{
+		Name:      req.Name,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	_, err = qtx.AddOrgMember(ctx, db.AddOrgMemberParams{
+		OrgID:  org.ID,
+		UserID: userID,
+		Role:   dto.OrgRoleOwner,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add organization owner: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return &dto.OrgResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		CreatedBy: org.CreatedBy,
+		CreatedAt: org.CreatedAt,
+		UpdatedAt: org.UpdatedAt,
+		Role:      dto.OrgRoleOwner,
+	}, nil
+}
+
+func (s *OrgService) ListOrgs(ctx context.Context, userID uuid.UUID) ([]dto.OrgResponse, error) {
+	orgsDb, err := s.q.GetUserOrganizations(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch organizations: %w", err)
+	}
+
+	orgs := make([]dto.OrgResponse, len(orgsDb))
+	for i, org := range orgsDb {
+		orgs[i] = dto.OrgResponse{
+			ID:        org.ID,
+			Name:      org.Name,
+			CreatedBy: org.CreatedBy,
+			CreatedAt: org.CreatedAt,
+			UpdatedAt: org.UpdatedAt,
+			Role:      org.Role,
+		}
+	}
+
+	return orgs, nil
+}
+
+func (s *OrgService) GetOrg(ctx context.Context, userID uuid.UUID, orgID uuid.UUID) (*dto.OrgResponse, error) {
+	role, err := orgRole(ctx, s.q, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := s.q.GetOrganization(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch organization: %w", err)
+	}
+
+	return &dto.OrgResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		CreatedBy: org.CreatedBy,
+		CreatedAt: org.CreatedAt,
+		UpdatedAt: org.UpdatedAt,
+		Role:      role,
+	}, nil
+}
+
+func (s *OrgService) UpdateOrg(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, req dto.UpdateOrgReq) error {
+	if _, err := requireOrgRole(ctx, s.q, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		return err
+	}
+
+	_, err := s.q.UpdateOrganization(ctx, db.UpdateOrganizationParams{
+		Name: req.Name,
+		ID:   orgID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update organization: %w", err)
+	}
+
+	return nil
+}
+
+func (s *OrgService) DeleteOrg(ctx context.Context, userID uuid.UUID, orgID uuid.UUID) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := requireOrgRole(ctx, qtx, orgID, userID, dto.OrgRoleOwner); err != nil {
+		return err
+	}
+
+	total, err := qtx.CountOrgResources(ctx, &orgID)
+	if err != nil {
+		return fmt.Errorf("failed to count organization resources: %w", err)
+	}
+
+	if total > 0 {
+		return ErrOrgNotEmpty
+	}
+
+	if _, err := qtx.DeleteOrganization(ctx, orgID); err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *OrgService) ListMembers(ctx context.Context, userID uuid.UUID, orgID uuid.UUID) ([]dto.OrgMember, error) {
+	if _, err := orgRole(ctx, s.q, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	membersDb, err := s.q.GetOrgMembers(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch organization members: %w", err)
+	}
+
+	members := make([]dto.OrgMember, len(membersDb))
+	for i, member := range membersDb {
+		members[i] = dto.OrgMember{
+			UserID:    member.UserID,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt,
+		}
+	}
+
+	return members, nil
+}
+
+func (s *OrgService) AddMember(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, req dto.AddOrgMemberReq) error {
+	role, err := requireOrgRole(ctx, s.q, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	if req.Role == dto.OrgRoleOwner && role != dto.OrgRoleOwner {
+		return ErrInsufficientRole
+	}
+
+	rowsAffected, err := s.q.AddOrgMember(ctx, db.AddOrgMemberParams{
+		OrgID:  orgID,
+		UserID: req.UserID,
+		Role:   req.Role,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add organization member: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrMemberExists
+	}
+
+	return nil
+}
+
+func (s *OrgService) UpdateMemberRole(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, memberID uuid.UUID, req dto.UpdateOrgMemberReq) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	role, err := requireOrgRole(ctx, qtx, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	currentRole, err := orgRole(ctx, qtx, orgID, memberID)
+	if err != nil {
+		if errors.Is(err, ErrOrgNotFound) {
+			return ErrMemberNotFound
+		}
+		return err
+	}
+
+	// Only owners may grant or take away the OWNER role
+	if (currentRole == dto.OrgRoleOwner || req.Role == dto.OrgRoleOwner) && role != dto.OrgRoleOwner {
+		return ErrInsufficientRole
+	}
+
+	if currentRole == dto.OrgRoleOwner && req.Role != dto.OrgRoleOwner {
+		if err := ensureOrgOwnerRemains(ctx, qtx, orgID); err != nil {
+			return err
+		}
+	}
+
+	_, err = qtx.UpdateOrgMemberRole(ctx, db.UpdateOrgMemberRoleParams{
+		Role:   req.Role,
+		OrgID:  orgID,
+		UserID: memberID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update organization member: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMember removes a member from the organization. Members may always
+// remove themselves; removing anyone else requires ADMIN or OWNER.
+func (s *OrgService) RemoveMember(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, memberID uuid.UUID) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	role, err := orgRole(ctx, qtx, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	currentRole := role
+	if memberID != userID {
+		if role != dto.OrgRoleOwner && role != dto.OrgRoleAdmin {
+			return ErrInsufficientRole
+		}
+
+		currentRole, err = orgRole(ctx, qtx, orgID, memberID)
+		if err != nil {
+			if errors.Is(err, ErrOrgNotFound) {
+				return ErrMemberNotFound
+			}
+			return err
+		}
+
+		if currentRole == dto.OrgRoleOwner && role != dto.OrgRoleOwner {
+			return ErrInsufficientRole
+		}
+	}
+
+	if currentRole == dto.OrgRoleOwner {
+		if err := ensureOrgOwnerRemains(ctx, qtx, orgID); err != nil {
+			return err
+		}
+	}
+
+	_, err = qtx.RemoveOrgMember(ctx, db.RemoveOrgMemberParams{
+		OrgID:  orgID,
+		UserID: memberID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove organization member: %w", err)
+	}
+
+	err = qtx.RemoveUserFromOrgGroups(ctx, db.RemoveUserFromOrgGroupsParams{
+		OrgID:  orgID,
+		UserID: memberID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove member from groups: %w", err)
+	}
+
+	_, err = qtx.DeleteRecoveryEnrollment(ctx, db.DeleteRecoveryEnrollmentParams{
+		OrgID:  orgID,
+		UserID: memberID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove recovery enrollment: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// environmentName keeps names usable as a path segment, e.g. /environments/prod/secrets
+var environmentName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ownedProject returns ErrProjectNotFound unless the user owns the project or
+// administers its organization.
+func ownedProject(ctx context.Context, q *db.Queries, projectID uuid.UUID, userID uuid.UUID) (db.Project, error) {
+	project, err := q.GetOwnedProject(ctx, db.GetOwnedProjectParams{
+		ID:      projectID,
+		OwnerID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Project{}, ErrProjectNotFound
+		}
+		return db.Project{}, fmt.Errorf("failed to fetch project: %w", err)
+	}
+
+	return project, nil
+}
+
+// visibleProject returns ErrProjectNotFound unless the user may see the
+// project. Restricted API tokens must have one of its environments in scope.
+func visibleProject(ctx context.Context, q *db.Queries, projectID uuid.UUID, userID uuid.UUID) (db.GetVisibleProjectRow, error) {
+	project, err := q.GetVisibleProject(ctx, db.GetVisibleProjectParams{
+		ID:     projectID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.GetVisibleProjectRow{}, ErrProjectNotFound
+		}
+		return db.GetVisibleProjectRow{}, fmt.Errorf("failed to fetch project: %w", err)
+	}
+
+	scope, _ := tokenScopeFrom(ctx)
+	if scope.Restricted() && !slices.ContainsFunc(project.FolderIds, scope.AllowsFolder) {
+		return db.GetVisibleProjectRow{}, ErrProjectNotFound
+	}
+
+	return project, nil
+}
+
+func (s *VaultService) CreateProject(ctx context.Context, userID uuid.UUID, req dto.CreateProjectReq) (_ *dto.Project, err error) {
+	ctx, end := startOperation(ctx, "create_project")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, nil); err != nil {
+		return nil, err
+	}
+
+	if req.OrgID != nil {
+		if _, err := orgRole(ctx, s.q, *req.OrgID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	project, err := s.q.CreateProject(ctx, db.CreateProjectParams{
+		OwnerID: userID,
+		OrgID:   req.OrgID,
+		Name:    req.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return &dto.Project{
+		ID:        project.ID,
+		OrgID:     project.OrgID,
+		Name:      project.Name,
+		IsOwner:   true,
+		CreatedAt: project.CreatedAt,
+		UpdatedAt: project.UpdatedAt,
+	}, nil
+}
+
+func (s *VaultService) ListProjects(ctx context.Context, userID uuid.UUID) (_ []dto.Project, err error) {
+	ctx, end := startOperation(ctx, "list_projects")
+	defer end(&err)
+
+	projectsDb, err := s.q.GetVisibleProjects(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, _ := tokenScopeFrom(ctx)
+
+	projects := make([]dto.Project, 0, len(projectsDb))
+	for _, project := range projectsDb {
+		if scope.Restricted() && !slices.ContainsFunc(project.FolderIds, scope.AllowsFolder) {
+			continue
+		}
+
+		projects = append(projects, dto.Project{
+			ID:        project.ID,
+			OrgID:     project.OrgID,
+			Name:      project.Name,
+			IsOwner:   project.OwnerID == userID,
+			CreatedAt: project.CreatedAt,
+			UpdatedAt: project.UpdatedAt,
+		})
+	}
+
+	return projects, nil
+}
+
+// DeleteProject deletes a project without environments. Deleting those first
+// removes and audits their folders like any other folder deletion.
+func (s *VaultService) DeleteProject(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) (err error) {
+	ctx, end := startOperation(ctx, "delete_project")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, nil); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := ownedProject(ctx, qtx, projectID, userID); err != nil {
+		return err
+	}
+
+	total, err := qtx.CountProjectEnvironments(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to count project environments: %w", err)
+	}
+
+	if total > 0 {
+		return ErrProjectNotEmpty
+	}
+
+	if err := qtx.DeleteProject(ctx, projectID); err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// CreateEnvironment creates an environment and the folder backing it. The
+// folder belongs to the project's organization and is shared like any other.
+func (s *VaultService) CreateEnvironment(ctx context.Context, userID uuid.UUID, projectID uuid.UUID, req dto.CreateEnvironmentReq) (_ *dto.Environment, err error) {
+	ctx, end := startOperation(ctx, "create_environment")
+	defer end(&err)
+
+	if !environmentName.MatchString(req.Name) {
+		return nil, ErrInvalidEnvironmentName
+	}
+
+	if err := checkScope(ctx, s.q, true, nil); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	project, err := ownedProject(ctx, qtx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := qtx.CreateFolder(ctx, db.CreateFolderParams{
+		OwnerID:     userID,
+		Nonce:       req.NameNonce,
+		EncMetadata: req.EncMetadata,
+		OrgID:       project.OrgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	err = qtx.CreateFolderKey(ctx, db.CreateFolderKeyParams{
+		UserID:   userID,
+		FolderID: &folder.ID,
+
+		EncKey:      req.EncKey,
+		Nonce:       req.KeyNonce,
+		AccessLevel: "OWNER",
+		GrantedBy:   &userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create folder key: %w", err)
+	}
+
+	env, err := qtx.CreateEnvironment(ctx, db.CreateEnvironmentParams{
+		ProjectID: projectID,
+		FolderID:  folder.ID,
+		Name:      req.Name,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEnvironmentExists
+		}
+		return nil, fmt.Errorf("failed to create environment: %w", err)
+	}
+
+	if err := recordResourceEvent(ctx, qtx, userID, dto.EventCreated, folder.ID, dto.TypeFolder, userID, nil); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return &dto.Environment{
+		ID:          env.ID,
+		ProjectID:   env.ProjectID,
+		FolderID:    env.FolderID,
+		Name:        env.Name,
+		AccessLevel: "OWNER",
+		CreatedAt:   env.CreatedAt,
+	}, nil
+}
+
+func (s *VaultService) ListEnvironments(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) (_ []dto.Environment, err error) {
+	ctx, end := startOperation(ctx, "list_environments")
+	defer end(&err)
+
+	if _, err := visibleProject(ctx, s.q, projectID, userID); err != nil {
+		return nil, err
+	}
+
+	envsDb, err := s.q.GetProjectEnvironments(ctx, db.GetProjectEnvironmentsParams{
+		ProjectID: projectID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scope, _ := tokenScopeFrom(ctx)
+
+	envs := make([]dto.Environment, 0, len(envsDb))
+	for _, env := range envsDb {
+		if !scope.AllowsFolder(env.FolderID) {
+			continue
+		}
+
+		var accessLevel string
+		if env.AccessLevel != nil {
+			accessLevel = *env.AccessLevel
+		}
+
+		envs = append(envs, dto.Environment{
+			ID:          env.ID,
+			ProjectID:   env.ProjectID,
+			FolderID:    env.FolderID,
+			Name:        env.Name,
+			AccessLevel: accessLevel,
+			CreatedAt:   env.CreatedAt,
+		})
+	}
+
+	return envs, nil
+}
+
+// DeleteEnvironment removes the environment and soft deletes its folder.
+func (s *VaultService) DeleteEnvironment(ctx context.Context, userID uuid.UUID, projectID uuid.UUID, envID uuid.UUID) (err error) {
+	ctx, end := startOperation(ctx, "delete_environment")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, nil); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := ownedProject(ctx, qtx, projectID, userID); err != nil {
+		return err
+	}
+
+	env, err := qtx.GetEnvironment(ctx, db.GetEnvironmentParams{
+		ID:        envID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrEnvironmentNotFound
+		}
+		return fmt.Errorf("failed to fetch environment: %w", err)
+	}
+
+	ownerID, err := resourceOwner(ctx, qtx, env.FolderID, dto.TypeFolder)
+	if err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteEnvironment(ctx, db.DeleteEnvironmentParams{
+		ID:        envID,
+		ProjectID: projectID,
+	}); err != nil {
+		return fmt.Errorf("failed to delete environment: %w", err)
+	}
+
+	if err := qtx.SoftDeleteEnvironmentFolder(ctx, env.FolderID); err != nil {
+		return fmt.Errorf("failed to delete environment folder: %w", err)
+	}
+
+	if err := recordResourceEvent(ctx, qtx, userID, dto.EventDeleted, env.FolderID, dto.TypeFolder, ownerID, nil); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetEnvironmentSecrets returns every secret of an environment the caller
+// holds a key to in one call, for service accounts loading their config.
+// Each secret is audited as a read like GetItem.
+func (s *VaultService) GetEnvironmentSecrets(ctx context.Context, userID uuid.UUID, projectID uuid.UUID, envName string) (_ *dto.EnvironmentSecrets, err error) {
+	ctx, end := startOperation(ctx, "get_environment_secrets")
+	defer end(&err)
+
+	if _, err := visibleProject(ctx, s.q, projectID, userID); err != nil {
+		return nil, err
+	}
+
+	env, err := s.q.GetEnvironmentByName(ctx, db.GetEnvironmentByNameParams{
+		ProjectID: projectID,
+		Name:      envName,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEnvironmentNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch environment: %w", err)
+	}
+
+	if err := checkScope(ctx, s.q, false, &env.FolderID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	secretsDb, err := qtx.GetFolderSecrets(ctx, db.GetFolderSecretsParams{
+		FolderID: &env.FolderID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secrets: %w", err)
+	}
+
+	secrets := make([]dto.Secret, 0, len(secretsDb))
+	for _, secret := range secretsDb {
+		// Reads are audited too, the events must commit before the data is returned
+		if err := recordResourceEvent(ctx, qtx, userID, dto.EventRead, secret.ID, dto.TypeItem, secret.OwnerID, nil); err != nil {
+			return nil, err
+		}
+
+		secrets = append(secrets, dto.Secret{
+			ID:            secret.ID,
+			EncOverview:   secret.EncOverview,
+			OverviewNonce: secret.OverviewNonce,
+			EncData:       secret.EncData,
+			DataNonce:     secret.DataNonce,
+			WrappedKey:    secret.WrappedKey,
+			KeyNonce:      secret.KeyNonce,
+			UpdatedAt:     secret.UpdatedAt,
+		})
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return &dto.EnvironmentSecrets{
+		Environment: dto.Environment{
+			ID:        env.ID,
+			ProjectID: env.ProjectID,
+			FolderID:  env.FolderID,
+			Name:      env.Name,
+			CreatedAt: env.CreatedAt,
+		},
+		Secrets: secrets,
+	}, nil
+}
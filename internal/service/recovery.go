@@ -0,0 +1,527 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// recoveryTransitions lists the states each recovery request may move to.
+var recoveryTransitions = map[string][]string{
+	dto.RecoveryPending:  {dto.RecoveryApproved, dto.RecoveryCancelled},
+	dto.RecoveryApproved: {dto.RecoveryCompleted, dto.RecoveryCancelled},
+}
+
+// recordOrgEvent stores an event about an organization. Pass the transaction
+// scoped queries so the event commits together with the change it describes.
+func recordOrgEvent(ctx context.Context, q *db.Queries, actorID uuid.UUID, action string, orgID uuid.UUID, targetUserID *uuid.UUID) error {
+	typeStr := "ORGANIZATION"
+
+	err := appendAuditEvent(ctx, q, auditRecord{
+		ActorID:      actorID,
+		Action:       action,
+		ResourceType: &typeStr,
+		ResourceID:   &orgID,
+		OrgID:        &orgID,
+		TargetUserID: targetUserID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", action, err)
+	}
+
+	return nil
+}
+
+func toRecoveryRequest(r db.RecoveryRequest) dto.RecoveryRequest {
+	return dto.RecoveryRequest{
+		ID:              r.ID,
+		OrgID:           r.OrgID,
+		UserID:          r.UserID,
+		DevicePublicKey: r.DevicePublicKey,
+		Status:          r.Status,
+		InitiatedBy:     r.InitiatedBy,
+		ApprovedBy:      r.ApprovedBy,
+		CreatedAt:       r.CreatedAt,
+		ApprovedAt:      r.ApprovedAt,
+		CompletedAt:     r.CompletedAt,
+	}
+}
+
+func (s *OrgService) GetRecoveryPolicy(ctx context.Context, userID uuid.UUID, orgID uuid.UUID) (*dto.RecoveryPolicy, error) {
+	if _, err := orgRole(ctx, s.q, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.q.GetRecoveryPolicy(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecoveryNotEnabled
+		}
+		return nil, fmt.Errorf("failed to fetch recovery policy: %w", err)
+	}
+
+	enrolled := true
+	_, err = s.q.GetRecoveryEnrollment(ctx, db.GetRecoveryEnrollmentParams{
+		OrgID:  orgID,
+		UserID: userID,
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to fetch recovery enrollment: %w", err)
+		}
+		enrolled = false
+	}
+
+	return &dto.RecoveryPolicy{
+		OrgID:     policy.OrgID,
+		PublicKey: policy.PublicKey,
+		UpdatedBy: policy.UpdatedBy,
+		CreatedAt: policy.CreatedAt,
+		UpdatedAt: policy.UpdatedAt,
+		Enrolled:  enrolled,
+	}, nil
+}
+
+// SetRecoveryPolicy enables recovery or rotates the recovery key. Existing
+// enrollments are wrapped with the old key, so they are dropped and open
+// requests are cancelled.
+func (s *OrgService) SetRecoveryPolicy(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, req dto.SetRecoveryPolicyReq) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := requireOrgRole(ctx, qtx, orgID, userID, dto.OrgRoleOwner); err != nil {
+		return err
+	}
+
+	if err := s.clearRecovery(ctx, qtx, orgID); err != nil {
+		return err
+	}
+
+	err = qtx.UpsertRecoveryPolicy(ctx, db.UpsertRecoveryPolicyParams{
+		OrgID:     orgID,
+		PublicKey: req.PublicKey,
+		UpdatedBy: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store recovery policy: %w", err)
+	}
+
+	if err := recordOrgEvent(ctx, qtx, userID, dto.EventRecoveryPolicySet, orgID, nil); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *OrgService) DisableRecoveryPolicy(ctx context.Context, userID uuid.UUID, orgID uuid.UUID) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := requireOrgRole(ctx, qtx, orgID, userID, dto.OrgRoleOwner); err != nil {
+		return err
+	}
+
+	rowsAffected, err := qtx.DeleteRecoveryPolicy(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete recovery policy: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecoveryNotEnabled
+	}
+
+	if err := s.clearRecovery(ctx, qtx, orgID); err != nil {
+		return err
+	}
+
+	if err := recordOrgEvent(ctx, qtx, userID, dto.EventRecoveryPolicyDisabled, orgID, nil); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *OrgService) clearRecovery(ctx context.Context, q *db.Queries, orgID uuid.UUID) error {
+	if err := q.DeleteOrgRecoveryEnrollments(ctx, orgID); err != nil {
+		return fmt.Errorf("failed to delete recovery enrollments: %w", err)
+	}
+
+	if err := q.CancelOpenRecoveryRequests(ctx, orgID); err != nil {
+		return fmt.Errorf("failed to cancel recovery requests: %w", err)
+	}
+
+	return nil
+}
+
+// Enroll stores the caller's keyring wrapped with the org recovery public key.
+func (s *OrgService) Enroll(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, req dto.RecoveryEnrollReq) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := orgRole(ctx, qtx, orgID, userID); err != nil {
+		return err
+	}
+
+	if _, err := qtx.GetRecoveryPolicy(ctx, orgID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRecoveryNotEnabled
+		}
+		return fmt.Errorf("failed to fetch recovery policy: %w", err)
+	}
+
+	err = qtx.UpsertRecoveryEnrollment(ctx, db.UpsertRecoveryEnrollmentParams{
+		OrgID:          orgID,
+		UserID:         userID,
+		WrappedKeyring: req.WrappedKeyring,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store recovery enrollment: %w", err)
+	}
+
+	if err := recordOrgEvent(ctx, qtx, userID, dto.EventRecoveryEnrolled, orgID, &userID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *OrgService) Unenroll(ctx context.Context, userID uuid.UUID, orgID uuid.UUID) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := orgRole(ctx, qtx, orgID, userID); err != nil {
+		return err
+	}
+
+	rowsAffected, err := qtx.DeleteRecoveryEnrollment(ctx, db.DeleteRecoveryEnrollmentParams{
+		OrgID:  orgID,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete recovery enrollment: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotEnrolled
+	}
+
+	if err := recordOrgEvent(ctx, qtx, userID, dto.EventRecoveryUnenrolled, orgID, &userID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *OrgService) ListRecoveryEnrollments(ctx context.Context, userID uuid.UUID, orgID uuid.UUID) ([]dto.RecoveryEnrollment, error) {
+	if _, err := requireOrgRole(ctx, s.q, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	enrollmentsDb, err := s.q.GetOrgRecoveryEnrollments(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recovery enrollments: %w", err)
+	}
+
+	enrollments := make([]dto.RecoveryEnrollment, len(enrollmentsDb))
+	for i, enrollment := range enrollmentsDb {
+		enrollments[i] = dto.RecoveryEnrollment{
+			UserID:    enrollment.UserID,
+			CreatedAt: enrollment.CreatedAt,
+			UpdatedAt: enrollment.UpdatedAt,
+		}
+	}
+
+	return enrollments, nil
+}
+
+// StartRecovery opens a recovery request for an enrolled member. It has to be
+// approved by a second admin before the wrapped keyring can be read.
+func (s *OrgService) StartRecovery(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, req dto.CreateRecoveryReq) (*dto.RecoveryRequest, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := requireOrgRole(ctx, qtx, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	_, err = qtx.GetRecoveryEnrollment(ctx, db.GetRecoveryEnrollmentParams{
+		OrgID:  orgID,
+		UserID: req.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotEnrolled
+		}
+		return nil, fmt.Errorf("failed to fetch recovery enrollment: %w", err)
+	}
+
+	request, err := qtx.CreateRecoveryRequest(ctx, db.CreateRecoveryRequestParams{
+		OrgID:           orgID,
+		UserID:          req.UserID,
+		DevicePublicKey: req.DevicePublicKey,
+		InitiatedBy:     userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recovery request: %w", err)
+	}
+
+	if err := recordOrgEvent(ctx, qtx, userID, dto.EventRecoveryInitiated, orgID, &req.UserID); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	resp := toRecoveryRequest(request)
+	return &resp, nil
+}
+
+func (s *OrgService) ListRecoveryRequests(ctx context.Context, userID uuid.UUID, orgID uuid.UUID) ([]dto.RecoveryRequest, error) {
+	if _, err := requireOrgRole(ctx, s.q, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	requestsDb, err := s.q.GetOrgRecoveryRequests(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recovery requests: %w", err)
+	}
+
+	requests := make([]dto.RecoveryRequest, len(requestsDb))
+	for i, request := range requestsDb {
+		requests[i] = toRecoveryRequest(request)
+	}
+
+	return requests, nil
+}
+
+// GetRecoveryRequest returns a request to an org admin or to the member being
+// recovered. Only the member receives the re-wrapped keyring.
+func (s *OrgService) GetRecoveryRequest(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, requestID uuid.UUID) (*dto.RecoveryRequest, error) {
+	role, err := orgRole(ctx, s.q, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := s.q.GetRecoveryRequest(ctx, db.GetRecoveryRequestParams{
+		ID:    requestID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecoveryRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch recovery request: %w", err)
+	}
+
+	isAdmin := role == dto.OrgRoleOwner || role == dto.OrgRoleAdmin
+	if request.UserID != userID && !isAdmin {
+		return nil, ErrRecoveryRequestNotFound
+	}
+
+	resp := toRecoveryRequest(request)
+	if request.UserID == userID {
+		resp.RecoveredKeyring = request.RecoveredKeyring
+	}
+
+	return &resp, nil
+}
+
+func (s *OrgService) ApproveRecovery(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, requestID uuid.UUID) error {
+	return s.recoveryStep(ctx, userID, orgID, requestID, dto.RecoveryApproved, func(q *db.Queries, request db.RecoveryRequest) error {
+		if request.InitiatedBy == userID {
+			return ErrSecondAdminRequired
+		}
+
+		err := q.ApproveRecoveryRequest(ctx, db.ApproveRecoveryRequestParams{
+			ID:         requestID,
+			ApprovedBy: &userID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to approve recovery request: %w", err)
+		}
+
+		return recordOrgEvent(ctx, q, userID, dto.EventRecoveryApproved, orgID, &request.UserID)
+	})
+}
+
+// CompleteRecovery stores the keyring an admin re-wrapped for the member's device.
+func (s *OrgService) CompleteRecovery(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, requestID uuid.UUID, req dto.CompleteRecoveryReq) error {
+	return s.recoveryStep(ctx, userID, orgID, requestID, dto.RecoveryCompleted, func(q *db.Queries, request db.RecoveryRequest) error {
+		err := q.CompleteRecoveryRequest(ctx, db.CompleteRecoveryRequestParams{
+			ID:               requestID,
+			RecoveredKeyring: req.RecoveredKeyring,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to complete recovery request: %w", err)
+		}
+
+		return recordOrgEvent(ctx, q, userID, dto.EventRecoveryCompleted, orgID, &request.UserID)
+	})
+}
+
+func (s *OrgService) CancelRecovery(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, requestID uuid.UUID) error {
+	return s.recoveryStep(ctx, userID, orgID, requestID, dto.RecoveryCancelled, func(q *db.Queries, request db.RecoveryRequest) error {
+		if err := q.CancelRecoveryRequest(ctx, requestID); err != nil {
+			return fmt.Errorf("failed to cancel recovery request: %w", err)
+		}
+
+		return recordOrgEvent(ctx, q, userID, dto.EventRecoveryCancelled, orgID, &request.UserID)
+	})
+}
+
+// recoveryStep locks a request, checks the caller is an org admin and the
+// state machine permits moving to the next status, then runs apply.
+func (s *OrgService) recoveryStep(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, requestID uuid.UUID, to string, apply func(*db.Queries, db.RecoveryRequest) error) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := requireOrgRole(ctx, qtx, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		return err
+	}
+
+	request, err := qtx.GetRecoveryRequestForUpdate(ctx, db.GetRecoveryRequestForUpdateParams{
+		ID:    requestID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRecoveryRequestNotFound
+		}
+		return fmt.Errorf("failed to fetch recovery request: %w", err)
+	}
+
+	if !slices.Contains(recoveryTransitions[request.Status], to) {
+		return ErrInvalidTransition
+	}
+
+	if err := apply(qtx, request); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecoveryKeyring hands an approved request's wrapped keyring to an admin
+// so it can be re-wrapped for the member's device. Every read is recorded.
+func (s *OrgService) GetRecoveryKeyring(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, requestID uuid.UUID) (*dto.RecoveryKeyring, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if _, err := requireOrgRole(ctx, qtx, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	request, err := qtx.GetRecoveryRequest(ctx, db.GetRecoveryRequestParams{
+		ID:    requestID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecoveryRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch recovery request: %w", err)
+	}
+
+	if request.Status != dto.RecoveryApproved {
+		return nil, ErrInvalidTransition
+	}
+
+	enrollment, err := qtx.GetRecoveryEnrollment(ctx, db.GetRecoveryEnrollmentParams{
+		OrgID:  orgID,
+		UserID: request.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotEnrolled
+		}
+		return nil, fmt.Errorf("failed to fetch recovery enrollment: %w", err)
+	}
+
+	if err := recordOrgEvent(ctx, qtx, userID, dto.EventRecoveryKeyringRead, orgID, &request.UserID); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return &dto.RecoveryKeyring{
+		WrappedKeyring:  enrollment.WrappedKeyring,
+		DevicePublicKey: request.DevicePublicKey,
+	}, nil
+}
+
+func (s *OrgService) ListRecoveryEvents(ctx context.Context, userID uuid.UUID, orgID uuid.UUID) ([]dto.Event, error) {
+	if _, err := requireOrgRole(ctx, s.q, orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	eventsDb, err := s.q.GetResourceEvents(ctx, &orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch events: %w", err)
+	}
+
+	return toEvents(eventsDb), nil
+}
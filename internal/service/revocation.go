@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RevocationService keeps the deny-list of user JWTs: single tokens by jti
+// and per-user markers rejecting every token issued before a time. Lookups
+// are cached for cacheTTL, revocations made on this replica apply at once.
+type RevocationService struct {
+	pool     *pgxpool.Pool
+	q        *db.Queries
+	cacheTTL time.Duration
+
+	mu       sync.Mutex
+	tokens   map[string]cachedToken
+	sessions map[uuid.UUID]cachedSessions
+}
+
+type cachedToken struct {
+	revoked   bool
+	fetchedAt time.Time
+}
+
+type cachedSessions struct {
+	// revokedBefore is zero when the user has no marker
+	revokedBefore time.Time
+	fetchedAt     time.Time
+}
+
+func NewRevocationService(pool *pgxpool.Pool, q *db.Queries, cacheSeconds int) *RevocationService {
+	return &RevocationService{
+		pool:     pool,
+		q:        q,
+		cacheTTL: time.Duration(cacheSeconds) * time.Second,
+		tokens:   map[string]cachedToken{},
+		sessions: map[uuid.UUID]cachedSessions{},
+	}
+}
+
+// IsRevoked reports whether a user token was revoked by its jti or by a
+// marker of its user. Tokens without an iat cannot be placed before a
+// marker, so they count as revoked once the user has one.
+func (s *RevocationService) IsRevoked(ctx context.Context, userID uuid.UUID, jti string, issuedAt *time.Time) (bool, error) {
+	revokedBefore, err := s.sessionsRevokedBefore(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	if !revokedBefore.IsZero() && (issuedAt == nil || issuedAt.Before(revokedBefore)) {
+		return true, nil
+	}
+
+	if jti == "" {
+		return false, nil
+	}
+
+	return s.tokenRevoked(ctx, jti)
+}
+
+func (s *RevocationService) sessionsRevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	s.mu.Lock()
+	cached, ok := s.sessions[userID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < s.cacheTTL {
+		return cached.revokedBefore, nil
+	}
+
+	revokedBefore, err := s.q.GetSessionsRevokedBefore(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("failed to fetch session revocation: %w", err)
+	}
+
+	s.mu.Lock()
+	s.sessions[userID] = cachedSessions{revokedBefore: revokedBefore, fetchedAt: time.Now()}
+	s.mu.Unlock()
+
+	return revokedBefore, nil
+}
+
+func (s *RevocationService) tokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	cached, ok := s.tokens[jti]
+	s.mu.Unlock()
+	// A revocation is never lifted, so only negative lookups go stale
+	if ok && (cached.revoked || time.Since(cached.fetchedAt) < s.cacheTTL) {
+		return cached.revoked, nil
+	}
+
+	revoked, err := s.q.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch token revocation: %w", err)
+	}
+
+	s.mu.Lock()
+	s.tokens[jti] = cachedToken{revoked: revoked, fetchedAt: time.Now()}
+	s.mu.Unlock()
+
+	return revoked, nil
+}
+
+// RevokeToken denies a single token of the user until it expires.
+func (s *RevocationService) RevokeToken(ctx context.Context, userID uuid.UUID, jti string, expiresAt time.Time) (err error) {
+	ctx, end := startOperation(ctx, "revoke_token")
+	defer end(&err)
+
+	if jti == "" {
+		return ErrTokenWithoutID
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	err = qtx.RevokeToken(ctx, db.RevokeTokenParams{
+		Jti:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+		RevokedBy: &userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	err = appendAuditEvent(ctx, qtx, auditRecord{
+		ActorID: userID,
+		Action:  dto.EventSessionRevoked,
+		OwnerID: &userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", dto.EventSessionRevoked, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	s.mu.Lock()
+	s.tokens[jti] = cachedToken{revoked: true, fetchedAt: time.Now()}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// RevokeAllSessions rejects every token of the user issued until now and
+// returns the cutoff. actorID is uuid.Nil when an operator revokes them
+// with vaultctl. JWT iat has second precision, so a token issued in the
+// same second as the cutoff is rejected as well and the user signs in again.
+func (s *RevocationService) RevokeAllSessions(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) (_ time.Time, err error) {
+	ctx, end := startOperation(ctx, "revoke_all_sessions")
+	defer end(&err)
+
+	var revokedBy *uuid.UUID
+	if actorID != uuid.Nil {
+		revokedBy = &actorID
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	err = qtx.RevokeSessions(ctx, db.RevokeSessionsParams{
+		UserID:        userID,
+		RevokedBefore: time.Now(),
+		RevokedBy:     revokedBy,
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	// The stored marker may be later than ours if another revocation won
+	revokedBefore, err := qtx.GetSessionsRevokedBefore(ctx, userID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch session revocation: %w", err)
+	}
+
+	err = appendAuditEvent(ctx, qtx, auditRecord{
+		ActorID:      actorID,
+		Action:       dto.EventSessionsRevoked,
+		OwnerID:      &userID,
+		TargetUserID: &userID,
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to record %s event: %w", dto.EventSessionsRevoked, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return time.Time{}, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	s.mu.Lock()
+	s.sessions[userID] = cachedSessions{revokedBefore: revokedBefore, fetchedAt: time.Now()}
+	s.mu.Unlock()
+
+	return revokedBefore, nil
+}
+
+// Cleanup deletes deny-list entries of tokens that have expired anyway and
+// drops stale cache entries.
+func (s *RevocationService) Cleanup(ctx context.Context) (int64, error) {
+	deleted, err := s.q.DeleteExpiredRevokedTokens(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired revocations: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Revoked tokens are cached without expiry, refetching them drops the
+	// ones deleted above
+	for jti, cached := range s.tokens {
+		if time.Since(cached.fetchedAt) >= s.cacheTTL {
+			delete(s.tokens, jti)
+		}
+	}
+	for userID, cached := range s.sessions {
+		if time.Since(cached.fetchedAt) >= s.cacheTTL {
+			delete(s.sessions, userID)
+		}
+	}
+
+	return deleted, nil
+}
+
+// RunCleanupJob calls Cleanup every interval until ctx is done.
+func (s *RevocationService) RunCleanupJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.Cleanup(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "revocation cleanup job failed", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				slog.InfoContext(ctx, "revocation cleanup job deleted expired tokens", "deleted", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type tokenScopeKey struct{}
+
+// WithTokenScope marks ctx as authenticated by an API token with scope.
+// VaultService enforces it; requests without one are unrestricted.
+func WithTokenScope(ctx context.Context, scope dto.TokenScope) context.Context {
+	return context.WithValue(ctx, tokenScopeKey{}, scope)
+}
+
+func tokenScopeFrom(ctx context.Context) (dto.TokenScope, bool) {
+	scope, ok := ctx.Value(tokenScopeKey{}).(dto.TokenScope)
+	return scope, ok
+}
+
+// checkScope returns ErrOutOfScope when the API token on ctx may not reach
+// resourceID, or may not write when write is set. A nil resourceID stands
+// for operations across all resources, which restricted tokens may not do.
+func checkScope(ctx context.Context, q *db.Queries, write bool, resourceID *uuid.UUID) error {
+	scope, ok := tokenScopeFrom(ctx)
+	if !ok {
+		return nil
+	}
+
+	if write && scope.ReadOnly {
+		return ErrOutOfScope
+	}
+
+	if !scope.Restricted() {
+		return nil
+	}
+
+	if resourceID == nil {
+		return ErrOutOfScope
+	}
+
+	if scope.AllowsFolder(*resourceID) {
+		return nil
+	}
+
+	// The id may be an item stored in a folder in scope
+	folderID, err := q.GetItemFolderID(ctx, *resourceID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to fetch item folder: %w", err)
+	}
+
+	if scope.AllowsItem(*resourceID, folderID) {
+		return nil
+	}
+
+	return ErrOutOfScope
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// tokenPrefixLength is how much of a token is kept to tell tokens apart
+const tokenPrefixLength = 12
+
+type ServiceAccountService struct {
+	pool        *pgxpool.Pool
+	q           *db.Queries
+	maxLifetime time.Duration
+}
+
+func NewServiceAccountService(pool *pgxpool.Pool, q *db.Queries, maxLifetimeDays int) *ServiceAccountService {
+	return &ServiceAccountService{
+		pool:        pool,
+		q:           q,
+		maxLifetime: time.Duration(maxLifetimeDays) * 24 * time.Hour,
+	}
+}
+
+// hashAPIToken returns the stored form of a token. Tokens carry 256 random
+// bits, so a plain SHA-256 is enough.
+func hashAPIToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+func toServiceAccount(a db.ServiceAccount) dto.ServiceAccount {
+	return dto.ServiceAccount{
+		ID:        a.ID,
+		UserID:    a.UserID,
+		OrgID:     a.OrgID,
+		Name:      a.Name,
+		PublicKey: a.PublicKey,
+		CreatedBy: a.CreatedBy,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+// checkAccountAccess returns ErrServiceAccountNotFound unless the user owns
+// the service account or administers its organization.
+func (s *ServiceAccountService) checkAccountAccess(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) error {
+	account, err := s.q.GetServiceAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrServiceAccountNotFound
+		}
+		return fmt.Errorf("failed to fetch service account: %w", err)
+	}
+
+	if account.OrgID == nil {
+		if account.UserID == nil || *account.UserID != userID {
+			return ErrServiceAccountNotFound
+		}
+		return nil
+	}
+
+	if _, err := requireOrgRole(ctx, s.q, *account.OrgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		if errors.Is(err, ErrOrgNotFound) || errors.Is(err, ErrInsufficientRole) {
+			return ErrServiceAccountNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *ServiceAccountService) CreateServiceAccount(ctx context.Context, userID uuid.UUID, req dto.CreateServiceAccountReq) (*dto.ServiceAccount, error) {
+	params := db.CreateServiceAccountParams{
+		Name:      req.Name,
+		PublicKey: req.PublicKey,
+		CreatedBy: userID,
+	}
+
+	if req.OrgID != nil {
+		if _, err := requireOrgRole(ctx, s.q, *req.OrgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+			return nil, err
+		}
+		params.OrgID = req.OrgID
+	} else {
+		params.UserID = &userID
+	}
+
+	account, err := s.q.CreateServiceAccount(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	resp := toServiceAccount(account)
+	return &resp, nil
+}
+
+// ListServiceAccounts returns the user's own service accounts, or the
+// organization's when the query names one.
+func (s *ServiceAccountService) ListServiceAccounts(ctx context.Context, userID uuid.UUID, query dto.ServiceAccountQuery) ([]dto.ServiceAccount, error) {
+	orgID, err := optionalUUID(query.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid org_id: %w", err)
+	}
+
+	var accountsDb []db.ServiceAccount
+
+	if orgID != nil {
+		if _, err := requireOrgRole(ctx, s.q, *orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+			return nil, err
+		}
+		accountsDb, err = s.q.GetOrgServiceAccounts(ctx, orgID)
+	} else {
+		accountsDb, err = s.q.GetUserServiceAccounts(ctx, &userID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch service accounts: %w", err)
+	}
+
+	accounts := make([]dto.ServiceAccount, len(accountsDb))
+	for i, account := range accountsDb {
+		accounts[i] = toServiceAccount(account)
+	}
+
+	return accounts, nil
+}
+
+// DeleteServiceAccount deletes the account and its tokens. Keys shared with
+// it stay until the owners revoke them.
+func (s *ServiceAccountService) DeleteServiceAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) error {
+	if err := s.checkAccountAccess(ctx, userID, accountID); err != nil {
+		return err
+	}
+
+	if err := s.q.DeleteServiceAccount(ctx, accountID); err != nil {
+		return fmt.Errorf("failed to delete service account: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ServiceAccountService) CreateToken(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, req dto.CreateAPITokenReq) (*dto.APIToken, error) {
+	if err := s.checkAccountAccess(ctx, userID, accountID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !req.ExpiresAt.After(now) || req.ExpiresAt.After(now.Add(s.maxLifetime)) {
+		return nil, ErrTokenExpiry
+	}
+
+	// Tokens are read-only unless asked otherwise
+	readOnly := true
+	if req.ReadOnly != nil {
+		readOnly = *req.ReadOnly
+	}
+
+	if req.FolderIDs == nil {
+		req.FolderIDs = []uuid.UUID{}
+	}
+	if req.ItemIDs == nil {
+		req.ItemIDs = []uuid.UUID{}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate api token: %w", err)
+	}
+	token := dto.APITokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	row, err := s.q.CreateAPIToken(ctx, db.CreateAPITokenParams{
+		ServiceAccountID: accountID,
+		Name:             req.Name,
+		TokenHash:        hashAPIToken(token),
+		TokenPrefix:      token[:tokenPrefixLength],
+		ReadOnly:         readOnly,
+		FolderIds:        req.FolderIDs,
+		ItemIds:          req.ItemIDs,
+		ExpiresAt:        req.ExpiresAt,
+		CreatedBy:        userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api token: %w", err)
+	}
+
+	return &dto.APIToken{
+		ID:               row.ID,
+		ServiceAccountID: row.ServiceAccountID,
+		Name:             row.Name,
+		TokenPrefix:      row.TokenPrefix,
+		Scope: dto.TokenScope{
+			ReadOnly:  row.ReadOnly,
+			FolderIDs: row.FolderIds,
+			ItemIDs:   row.ItemIds,
+		},
+		ExpiresAt:  row.ExpiresAt,
+		RevokedAt:  row.RevokedAt,
+		LastUsedAt: row.LastUsedAt,
+		CreatedBy:  row.CreatedBy,
+		CreatedAt:  row.CreatedAt,
+		Token:      token,
+	}, nil
+}
+
+func (s *ServiceAccountService) ListTokens(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) ([]dto.APIToken, error) {
+	if err := s.checkAccountAccess(ctx, userID, accountID); err != nil {
+		return nil, err
+	}
+
+	tokensDb, err := s.q.GetServiceAccountTokens(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch api tokens: %w", err)
+	}
+
+	tokens := make([]dto.APIToken, len(tokensDb))
+	for i, t := range tokensDb {
+		tokens[i] = dto.APIToken{
+			ID:               t.ID,
+			ServiceAccountID: t.ServiceAccountID,
+			Name:             t.Name,
+			TokenPrefix:      t.TokenPrefix,
+			Scope: dto.TokenScope{
+				ReadOnly:  t.ReadOnly,
+				FolderIDs: t.FolderIds,
+				ItemIDs:   t.ItemIds,
+			},
+			ExpiresAt:  t.ExpiresAt,
+			RevokedAt:  t.RevokedAt,
+			LastUsedAt: t.LastUsedAt,
+			CreatedBy:  t.CreatedBy,
+			CreatedAt:  t.CreatedAt,
+		}
+	}
+
+	return tokens, nil
+}
+
+func (s *ServiceAccountService) RevokeToken(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, tokenID uuid.UUID) error {
+	if err := s.checkAccountAccess(ctx, userID, accountID); err != nil {
+		return err
+	}
+
+	rowsAffected, err := s.q.RevokeAPIToken(ctx, db.RevokeAPITokenParams{
+		ID:               tokenID,
+		ServiceAccountID: accountID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke api token: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAPITokenNotFound
+	}
+
+	return nil
+}
+
+// Authenticate resolves an API token to its service account and scope.
+func (s *ServiceAccountService) Authenticate(ctx context.Context, token string) (uuid.UUID, dto.TokenScope, error) {
+	if !strings.HasPrefix(token, dto.APITokenPrefix) {
+		return uuid.Nil, dto.TokenScope{}, ErrInvalidAPIToken
+	}
+
+	row, err := s.q.GetActiveAPIToken(ctx, hashAPIToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, dto.TokenScope{}, ErrInvalidAPIToken
+		}
+		return uuid.Nil, dto.TokenScope{}, fmt.Errorf("failed to fetch api token: %w", err)
+	}
+
+	if err := s.q.TouchAPIToken(ctx, row.ID); err != nil {
+		return uuid.Nil, dto.TokenScope{}, fmt.Errorf("failed to update api token: %w", err)
+	}
+
+	return row.ServiceAccountID, dto.TokenScope{
+		ReadOnly:  row.ReadOnly,
+		FolderIDs: row.FolderIds,
+		ItemIDs:   row.ItemIds,
+	}, nil
+}
@@ -2,11 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/axosec/vault/internal/data/db"
 	"github.com/axosec/vault/internal/dto"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -22,7 +24,14 @@ func NewVaultService(pool *pgxpool.Pool, q *db.Queries) *VaultService {
 	}
 }
 
-func (s *VaultService) CreateFolder(ctx context.Context, userID uuid.UUID, req dto.CreateFolderReq) (*dto.FolderResponse, error) {
+func (s *VaultService) CreateFolder(ctx context.Context, userID uuid.UUID, req dto.CreateFolderReq) (_ *dto.FolderResponse, err error) {
+	ctx, end := startOperation(ctx, "create_folder")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, nil); err != nil {
+		return nil, err
+	}
+
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -31,10 +40,17 @@ func (s *VaultService) CreateFolder(ctx context.Context, userID uuid.UUID, req d
 
 	qtx := s.q.WithTx(tx)
 
+	if req.OrgID != nil {
+		if _, err := orgRole(ctx, qtx, *req.OrgID, userID); err != nil {
+			return nil, err
+		}
+	}
+
 	folder, err := qtx.CreateFolder(ctx, db.CreateFolderParams{
 		OwnerID:     userID,
 		Nonce:       req.NameNonce,
 		EncMetadata: req.EncMetadata,
+		OrgID:       req.OrgID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create folder: %w", err)
@@ -47,11 +63,16 @@ func (s *VaultService) CreateFolder(ctx context.Context, userID uuid.UUID, req d
 		EncKey:      req.EncKey,
 		Nonce:       req.KeyNonce,
 		AccessLevel: "OWNER",
+		GrantedBy:   &userID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create folder key: %w", err)
 	}
 
+	if err := recordResourceEvent(ctx, qtx, userID, dto.EventCreated, folder.ID, dto.TypeFolder, userID, nil); err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("transaction commit failed: %w", err)
 	}
@@ -61,27 +82,62 @@ func (s *VaultService) CreateFolder(ctx context.Context, userID uuid.UUID, req d
 	}, nil
 }
 
-func (s *VaultService) ListFolders(ctx context.Context, userID uuid.UUID) ([]dto.FolderSummary, error) {
+func (s *VaultService) ListFolders(ctx context.Context, userID uuid.UUID) (_ []dto.FolderSummary, err error) {
+	ctx, end := startOperation(ctx, "list_folders")
+	defer end(&err)
+
 	foldersDb, err := s.q.GetUserFolders(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	folders := make([]dto.FolderSummary, len(foldersDb))
+	scope, _ := tokenScopeFrom(ctx)
+
+	folders := make([]dto.FolderSummary, 0, len(foldersDb))
+	seen := make(map[uuid.UUID]bool, len(foldersDb))
 
-	for i, folder := range foldersDb {
-		folders[i].ID = folder.ID
-		folders[i].EncMetadata = folder.EncMetadata
-		folders[i].Nonce = folder.Nonce
-		folders[i].KeyNonce = folder.KeyNonce
-		folders[i].WrappedKey = folder.WrappedKey
+	for _, folder := range foldersDb {
+		// A user in several groups sharing the same folder gets one row per group
+		if seen[folder.ID] || !scope.AllowsFolder(folder.ID) {
+			continue
+		}
+		seen[folder.ID] = true
+
+		folders = append(folders, dto.FolderSummary{
+			ID:            folder.ID,
+			OrgID:         folder.OrgID,
+			EncMetadata:   folder.EncMetadata,
+			Nonce:         folder.Nonce,
+			KeyNonce:      folder.KeyNonce,
+			WrappedKey:    folder.WrappedKey,
+			AccessLevel:   folder.AccessLevel,
+			IsOwner:       folder.IsOwner,
+			GroupID:       folder.GroupID,
+			GroupKey:      folder.GroupKey,
+			GroupKeyNonce: folder.GroupKeyNonce,
+		})
 	}
 
 	return folders, nil
 }
 
-func (s *VaultService) UpdateFolder(ctx context.Context, userID uuid.UUID, folderID uuid.UUID, req dto.UpdateFolderReq) error {
-	rowsAffected, err := s.q.UpdateFolderMetadata(ctx, db.UpdateFolderMetadataParams{
+func (s *VaultService) UpdateFolder(ctx context.Context, userID uuid.UUID, folderID uuid.UUID, req dto.UpdateFolderReq) (err error) {
+	ctx, end := startOperation(ctx, "update_folder")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, &folderID); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	rowsAffected, err := qtx.UpdateFolderMetadata(ctx, db.UpdateFolderMetadataParams{
 		EncMetadata: req.EncMetadata,
 		Nonce:       req.MetadataNonce,
 		ID:          folderID,
@@ -93,13 +149,39 @@ func (s *VaultService) UpdateFolder(ctx context.Context, userID uuid.UUID, folde
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("folder not found or access denied")
+		return ErrAccessDenied
+	}
+
+	// Org admins may update folders of other members, file it under the owner
+	ownerID, err := resourceOwner(ctx, qtx, folderID, dto.TypeFolder)
+	if err != nil {
+		return err
+	}
+
+	if err := recordResourceEvent(ctx, qtx, userID, dto.EventUpdated, folderID, dto.TypeFolder, ownerID, nil); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
 	}
 
 	return nil
 }
 
-func (s *VaultService) CreateItem(ctx context.Context, userID uuid.UUID, req dto.CreateItemReq) (*dto.ItemResponse, error) {
+func (s *VaultService) CreateItem(ctx context.Context, userID uuid.UUID, req dto.CreateItemReq) (_ *dto.ItemResponse, err error) {
+	ctx, end := startOperation(ctx, "create_item")
+	defer end(&err)
+
+	var folderIDPtr *uuid.UUID
+	if req.FolderID != uuid.Nil {
+		folderIDPtr = &req.FolderID
+	}
+
+	if err := checkScope(ctx, s.q, true, folderIDPtr); err != nil {
+		return nil, err
+	}
+
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -108,9 +190,10 @@ func (s *VaultService) CreateItem(ctx context.Context, userID uuid.UUID, req dto
 
 	qtx := s.q.WithTx(tx)
 
-	var folderIDPtr *uuid.UUID
-	if req.FolderID != uuid.Nil {
-		folderIDPtr = &req.FolderID
+	if req.OrgID != nil {
+		if _, err := orgRole(ctx, qtx, *req.OrgID, userID); err != nil {
+			return nil, err
+		}
 	}
 
 	item, err := qtx.CreateItem(ctx, db.CreateItemParams{
@@ -121,6 +204,7 @@ func (s *VaultService) CreateItem(ctx context.Context, userID uuid.UUID, req dto
 		EncData:       req.EncData,
 		EncOverview:   req.EncOverview,
 		OverviewNonce: req.OverviewNonce,
+		OrgID:         req.OrgID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create item: %w", err)
@@ -133,11 +217,16 @@ func (s *VaultService) CreateItem(ctx context.Context, userID uuid.UUID, req dto
 		EncKey:      req.EncKey,
 		Nonce:       req.KeyNonce,
 		AccessLevel: "OWNER",
+		GrantedBy:   &userID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create item key: %w", err)
 	}
 
+	if err := recordResourceEvent(ctx, qtx, userID, dto.EventCreated, item.ID, dto.TypeItem, userID, nil); err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("transaction commit failed: %w", err)
 	}
@@ -147,7 +236,10 @@ func (s *VaultService) CreateItem(ctx context.Context, userID uuid.UUID, req dto
 	}, nil
 }
 
-func (s *VaultService) ListItems(ctx context.Context, userID uuid.UUID, folderID uuid.UUID) ([]dto.ItemSummary, error) {
+func (s *VaultService) ListItems(ctx context.Context, userID uuid.UUID, folderID uuid.UUID) (_ []dto.ItemSummary, err error) {
+	ctx, end := startOperation(ctx, "list_items")
+	defer end(&err)
+
 	var folderIDPtr *uuid.UUID
 	if folderID != uuid.Nil {
 		folderIDPtr = &folderID
@@ -161,29 +253,77 @@ func (s *VaultService) ListItems(ctx context.Context, userID uuid.UUID, folderID
 		return nil, err
 	}
 
-	items := make([]dto.ItemSummary, len(itemsDb))
-	for i, item := range itemsDb {
-		items[i] = dto.ItemSummary{
+	scope, _ := tokenScopeFrom(ctx)
+
+	items := make([]dto.ItemSummary, 0, len(itemsDb))
+	seen := make(map[uuid.UUID]bool, len(itemsDb))
+
+	for _, item := range itemsDb {
+		// A user in several groups sharing the same item gets one row per group
+		if seen[item.ID] || !scope.AllowsItem(item.ID, folderIDPtr) {
+			continue
+		}
+		seen[item.ID] = true
+
+		items = append(items, dto.ItemSummary{
 			ID:            item.ID,
+			OrgID:         item.OrgID,
 			Type:          item.Type,
 			EncOverview:   item.EncOverview,
 			OverviewNonce: item.OverviewNonce,
 			WrappedKey:    item.WrappedKey,
 			KeyNonce:      item.KeyNonce,
+			AccessLevel:   item.AccessLevel,
+			IsOwner:       item.IsOwner,
 			UpdatedAt:     item.UpdatedAt,
-		}
+			GroupID:       item.GroupID,
+			GroupKey:      item.GroupKey,
+			GroupKeyNonce: item.GroupKeyNonce,
+		})
 	}
 
 	return items, nil
 }
 
-func (s *VaultService) GetItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) (*dto.ItemDetail, error) {
-	item, err := s.q.GetItemData(ctx, db.GetItemDataParams{
+func (s *VaultService) GetItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) (_ *dto.ItemDetail, err error) {
+	ctx, end := startOperation(ctx, "get_item")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, false, &itemID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	item, err := qtx.GetItemData(ctx, db.GetItemDataParams{
 		ID:     itemID,
 		UserID: userID,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("item not found or access denied: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAccessDenied
+		}
+		return nil, fmt.Errorf("failed to fetch item: %w", err)
+	}
+
+	ownerID, err := resourceOwner(ctx, qtx, itemID, dto.TypeItem)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reads are audited too, the event must commit before the data is returned
+	if err := recordResourceEvent(ctx, qtx, userID, dto.EventRead, itemID, dto.TypeItem, ownerID, nil); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("transaction commit failed: %w", err)
 	}
 
 	return &dto.ItemDetail{
@@ -195,10 +335,21 @@ func (s *VaultService) GetItem(ctx context.Context, userID uuid.UUID, itemID uui
 		WrappedKey: item.WrappedKey,
 		KeyNonce:   item.KeyNonce,
 		UpdatedAt:  item.UpdatedAt,
+
+		GroupID:       item.GroupID,
+		GroupKey:      item.GroupKey,
+		GroupKeyNonce: item.GroupKeyNonce,
 	}, nil
 }
 
-func (s *VaultService) UpdateItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, req dto.UpdateItemReq) error {
+func (s *VaultService) UpdateItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, req dto.UpdateItemReq) (err error) {
+	ctx, end := startOperation(ctx, "update_item")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, &itemID); err != nil {
+		return err
+	}
+
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -212,7 +363,10 @@ func (s *VaultService) UpdateItem(ctx context.Context, userID uuid.UUID, itemID
 		UserID: userID,
 	})
 	if err != nil {
-		return fmt.Errorf("cannot access item: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAccessDenied
+		}
+		return fmt.Errorf("failed to fetch item: %w", err)
 	}
 
 	canWrite := false
@@ -221,7 +375,7 @@ func (s *VaultService) UpdateItem(ctx context.Context, userID uuid.UUID, itemID
 	}
 
 	if !canWrite {
-		return fmt.Errorf("access denied: requires WRITE or OWNER permission")
+		return ErrReadOnly
 	}
 
 	err = qtx.UpdateItemBlob(ctx, db.UpdateItemBlobParams{
@@ -235,6 +389,15 @@ func (s *VaultService) UpdateItem(ctx context.Context, userID uuid.UUID, itemID
 		return fmt.Errorf("failed to update item blob: %w", err)
 	}
 
+	ownerID, err := resourceOwner(ctx, qtx, itemID, dto.TypeItem)
+	if err != nil {
+		return err
+	}
+
+	if err := recordResourceEvent(ctx, qtx, userID, dto.EventUpdated, itemID, dto.TypeItem, ownerID, nil); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit failed: %w", err)
 	}
@@ -242,25 +405,39 @@ func (s *VaultService) UpdateItem(ctx context.Context, userID uuid.UUID, itemID
 	return nil
 }
 
-func (s *VaultService) DeleteResource(ctx context.Context, userID uuid.UUID, resourceID uuid.UUID, resourceType dto.ResourceType) error {
+func (s *VaultService) DeleteResource(ctx context.Context, userID uuid.UUID, resourceID uuid.UUID, resourceType dto.ResourceType) (err error) {
+	ctx, end := startOperation(ctx, "delete_resource")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, &resourceID); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
 	var rowsAffected int64
-	var err error
 
 	switch resourceType {
 	case dto.TypeFolder:
-		rowsAffected, err = s.q.SoftDeleteFolder(ctx, db.SoftDeleteFolderParams{
+		rowsAffected, err = qtx.SoftDeleteFolder(ctx, db.SoftDeleteFolderParams{
 			ID:      resourceID,
 			OwnerID: userID,
 		})
 
 	case dto.TypeItem:
-		rowsAffected, err = s.q.SoftDeleteItem(ctx, db.SoftDeleteItemParams{
+		rowsAffected, err = qtx.SoftDeleteItem(ctx, db.SoftDeleteItemParams{
 			ID:      resourceID,
 			OwnerID: userID,
 		})
 
 	default:
-		return fmt.Errorf("invalid resource type")
+		return ErrInvalidResourceType
 	}
 
 	if err != nil {
@@ -268,86 +445,134 @@ func (s *VaultService) DeleteResource(ctx context.Context, userID uuid.UUID, res
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("resource not found or access denied (not owner)")
+		return ErrAccessDenied
+	}
+
+	ownerID, err := resourceOwner(ctx, qtx, resourceID, resourceType)
+	if err != nil {
+		return err
+	}
+
+	if err := recordResourceEvent(ctx, qtx, userID, dto.EventDeleted, resourceID, resourceType, ownerID, nil); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
 	}
 
 	return nil
 }
 
-func (s *VaultService) ShareResource(ctx context.Context, ownerID uuid.UUID, req dto.ShareParams) error {
-	var err error
-	if req.ResourceType == dto.TypeFolder {
-		_, err = s.q.IsFolderOwner(ctx, db.IsFolderOwnerParams{
-			ID:      req.ResourceID,
-			OwnerID: ownerID,
-		})
-	} else {
-		_, err = s.q.IsItemOwner(ctx, db.IsItemOwnerParams{
-			ID:      req.ResourceID,
-			OwnerID: ownerID,
-		})
+func (s *VaultService) ShareResource(ctx context.Context, ownerID uuid.UUID, req dto.ShareParams) (err error) {
+	ctx, end := startOperation(ctx, "share_resource")
+	defer end(&err)
+
+	if err := checkScope(ctx, s.q, true, &req.ResourceID); err != nil {
+		return err
 	}
 
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("resource not found or access denied")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if err := checkOwner(ctx, qtx, ownerID, req.ResourceID, req.ResourceType); err != nil {
+		return err
+	}
+
+	// Sharing is an upsert, so re-sharing to an existing OWNER may demote them
+	if err := ensureOwnerRemains(ctx, qtx, req.ResourceID, req.TargetUserID, req.AccessLevel); err != nil {
+		return err
 	}
 
 	switch req.ResourceType {
 	case dto.TypeFolder:
-		err := s.q.CreateFolderKey(ctx, db.CreateFolderKeyParams{
+		err := qtx.CreateFolderKey(ctx, db.CreateFolderKeyParams{
 			UserID:      req.TargetUserID,
 			FolderID:    &req.ResourceID,
 			EncKey:      req.EncKey,
 			Nonce:       req.KeyNonce,
 			AccessLevel: req.AccessLevel,
+			GrantedBy:   &ownerID,
+			ExpiresAt:   req.ExpiresAt,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to share folder: %w", err)
 		}
 
 	case dto.TypeItem:
-		err := s.q.CreateItemKey(ctx, db.CreateItemKeyParams{
+		err := qtx.CreateItemKey(ctx, db.CreateItemKeyParams{
 			UserID:      req.TargetUserID,
 			ItemID:      &req.ResourceID,
 			EncKey:      req.EncKey,
 			Nonce:       req.KeyNonce,
 			AccessLevel: req.AccessLevel,
+			GrantedBy:   &ownerID,
+			ExpiresAt:   req.ExpiresAt,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to share item: %w", err)
 		}
 
 	default:
-		return fmt.Errorf("invalid resource type")
+		return ErrInvalidResourceType
+	}
+
+	// The caller may be an org admin rather than the owner of record
+	resourceOwnerID, err := resourceOwner(ctx, qtx, req.ResourceID, req.ResourceType)
+	if err != nil {
+		return err
+	}
+
+	if err := recordResourceEvent(ctx, qtx, ownerID, dto.EventShared, req.ResourceID, req.ResourceType, resourceOwnerID, &req.TargetUserID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
 	}
 
 	return nil
 }
 
-func (s *VaultService) RevokeAccess(ctx context.Context, ownerID uuid.UUID, targetUserID uuid.UUID, resourceID uuid.UUID) error {
-	isOwner := false
+func (s *VaultService) RevokeAccess(ctx context.Context, ownerID uuid.UUID, targetUserID uuid.UUID, resourceID uuid.UUID) (err error) {
+	ctx, end := startOperation(ctx, "revoke_access")
+	defer end(&err)
 
-	_, err := s.q.IsFolderOwner(ctx, db.IsFolderOwnerParams{
-		ID:      resourceID,
-		OwnerID: ownerID,
-	})
-	if err == nil {
-		isOwner = true
-	} else {
-		_, err := s.q.IsItemOwner(ctx, db.IsItemOwnerParams{
-			ID:      resourceID,
-			OwnerID: ownerID,
-		})
-		if err == nil {
-			isOwner = true
-		}
+	if err := checkScope(ctx, s.q, true, &resourceID); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	// The request carries no resource type, so try the folder first
+	resourceType := dto.TypeFolder
+	err = checkOwner(ctx, qtx, ownerID, resourceID, dto.TypeFolder)
+	if errors.Is(err, ErrAccessDenied) {
+		resourceType = dto.TypeItem
+		err = checkOwner(ctx, qtx, ownerID, resourceID, dto.TypeItem)
 	}
 
-	if !isOwner {
-		return fmt.Errorf("access denied: you are not the owner of this resource")
+	if err != nil {
+		return err
 	}
 
-	err = s.q.RevokeUserAccess(ctx, db.RevokeUserAccessParams{
+	// Revoking an OWNER, the caller included, must leave another one
+	if err := ensureOwnerRemains(ctx, qtx, resourceID, targetUserID, ""); err != nil {
+		return err
+	}
+
+	err = qtx.RevokeUserAccess(ctx, db.RevokeUserAccessParams{
 		UserID:   targetUserID,
 		FolderID: &resourceID,
 	})
@@ -356,5 +581,18 @@ func (s *VaultService) RevokeAccess(ctx context.Context, ownerID uuid.UUID, targ
 		return fmt.Errorf("failed to revoke access: %w", err)
 	}
 
+	resourceOwnerID, err := resourceOwner(ctx, qtx, resourceID, resourceType)
+	if err != nil {
+		return err
+	}
+
+	if err := recordResourceEvent(ctx, qtx, ownerID, dto.EventRevoked, resourceID, resourceType, resourceOwnerID, &targetUserID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
 	return nil
 }
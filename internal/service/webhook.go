@@ -0,0 +1,403 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	webhookBatchSize = 50
+	// A claimed delivery is retried by any worker once its lease runs out
+	webhookLeaseSeconds = 300
+	webhookBaseBackoff  = 30 * time.Second
+	webhookMaxBackoff   = 6 * time.Hour
+)
+
+// deliveryQueue is the part of the queries the delivery job and replays run on
+type deliveryQueue interface {
+	GetWebhook(ctx context.Context, id uuid.UUID) (db.Webhook, error)
+	ClaimWebhookDeliveries(ctx context.Context, arg db.ClaimWebhookDeliveriesParams) ([]db.ClaimWebhookDeliveriesRow, error)
+	MarkWebhookDelivered(ctx context.Context, arg db.MarkWebhookDeliveredParams) error
+	MarkWebhookFailed(ctx context.Context, arg db.MarkWebhookFailedParams) error
+	ReplayWebhookDelivery(ctx context.Context, arg db.ReplayWebhookDeliveryParams) (int64, error)
+}
+
+type WebhookService struct {
+	pool        *pgxpool.Pool
+	q           *db.Queries
+	deliveries  deliveryQueue
+	client      *http.Client
+	maxAttempts int32
+}
+
+func NewWebhookService(pool *pgxpool.Pool, q *db.Queries, timeout time.Duration, maxAttempts int) *WebhookService {
+	return &WebhookService{
+		pool:        pool,
+		q:           q,
+		deliveries:  q,
+		client:      newWebhookClient(timeout, false),
+		maxAttempts: int32(maxAttempts),
+	}
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of "timestamp.body" keyed
+// with the webhook secret, as sent in the X-Vault-Signature header.
+func SignWebhookPayload(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff returns how long to wait after the given failed attempt.
+func webhookBackoff(attempts int32) time.Duration {
+	delay := webhookBaseBackoff
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return delay
+}
+
+// enqueueWebhooks queues a delivery of r for every matching webhook. It runs
+// in the audit transaction, so nothing is delivered for rolled back changes.
+func enqueueWebhooks(ctx context.Context, q *db.Queries, r auditRecord) error {
+	payload, err := json.Marshal(dto.WebhookPayload{
+		ID:           r.ID,
+		Event:        r.Action,
+		ActorID:      r.ActorID,
+		ResourceType: r.ResourceType,
+		ResourceID:   r.ResourceID,
+		OwnerID:      r.OwnerID,
+		OrgID:        r.OrgID,
+		TargetUserID: r.TargetUserID,
+		CreatedAt:    r.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	err = q.EnqueueWebhookDeliveries(ctx, db.EnqueueWebhookDeliveriesParams{
+		EventID:      r.ID,
+		EventType:    r.Action,
+		Payload:      payload,
+		OwnerID:      r.OwnerID,
+		TargetUserID: r.TargetUserID,
+		OrgID:        r.OrgID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+func toWebhookResponse(w db.Webhook) dto.WebhookResponse {
+	return dto.WebhookResponse{
+		ID:        w.ID,
+		UserID:    w.UserID,
+		OrgID:     w.OrgID,
+		URL:       w.Url,
+		Events:    w.Events,
+		CreatedBy: w.CreatedBy,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+// checkWebhookAccess returns ErrWebhookNotFound unless the user owns the
+// webhook or administers its organization.
+func (s *WebhookService) checkWebhookAccess(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) error {
+	webhook, err := s.deliveries.GetWebhook(ctx, webhookID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWebhookNotFound
+		}
+		return fmt.Errorf("failed to fetch webhook: %w", err)
+	}
+
+	if webhook.OrgID == nil {
+		if webhook.UserID == nil || *webhook.UserID != userID {
+			return ErrWebhookNotFound
+		}
+		return nil
+	}
+
+	if _, err := requireOrgRole(ctx, s.q, *webhook.OrgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+		if errors.Is(err, ErrOrgNotFound) || errors.Is(err, ErrInsufficientRole) {
+			return ErrWebhookNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *WebhookService) CreateWebhook(ctx context.Context, userID uuid.UUID, req dto.CreateWebhookReq) (*dto.WebhookResponse, error) {
+	if err := resolveWebhookURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	params := db.CreateWebhookParams{
+		Url:       req.URL,
+		Events:    req.Events,
+		CreatedBy: userID,
+	}
+
+	if req.OrgID != nil {
+		if _, err := requireOrgRole(ctx, s.q, *req.OrgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+			return nil, err
+		}
+		params.OrgID = req.OrgID
+	} else {
+		params.UserID = &userID
+	}
+
+	if params.Events == nil {
+		params.Events = []string{}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	params.Secret = hex.EncodeToString(secret)
+
+	webhook, err := s.q.CreateWebhook(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	resp := toWebhookResponse(webhook)
+	resp.Secret = webhook.Secret
+	return &resp, nil
+}
+
+// ListWebhooks returns the user's own webhooks, or the organization's when
+// the query names one.
+func (s *WebhookService) ListWebhooks(ctx context.Context, userID uuid.UUID, query dto.WebhookQuery) ([]dto.WebhookResponse, error) {
+	orgID, err := optionalUUID(query.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid org_id: %w", err)
+	}
+
+	var webhooksDb []db.Webhook
+
+	if orgID != nil {
+		if _, err := requireOrgRole(ctx, s.q, *orgID, userID, dto.OrgRoleOwner, dto.OrgRoleAdmin); err != nil {
+			return nil, err
+		}
+		webhooksDb, err = s.q.GetOrgWebhooks(ctx, orgID)
+	} else {
+		webhooksDb, err = s.q.GetUserWebhooks(ctx, &userID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhooks: %w", err)
+	}
+
+	webhooks := make([]dto.WebhookResponse, len(webhooksDb))
+	for i, webhook := range webhooksDb {
+		webhooks[i] = toWebhookResponse(webhook)
+	}
+
+	return webhooks, nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) error {
+	if err := s.checkWebhookAccess(ctx, userID, webhookID); err != nil {
+		return err
+	}
+
+	if err := s.q.DeleteWebhook(ctx, webhookID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID, query dto.WebhookDeliveryQuery) ([]dto.WebhookDelivery, error) {
+	if err := s.checkWebhookAccess(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveriesDb, err := s.q.GetWebhookDeliveries(ctx, db.GetWebhookDeliveriesParams{
+		WebhookID: webhookID,
+		Status:    optionalString(query.Status),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]dto.WebhookDelivery, len(deliveriesDb))
+	for i, d := range deliveriesDb {
+		deliveries[i] = dto.WebhookDelivery{
+			ID:             d.ID,
+			EventID:        d.EventID,
+			EventType:      d.EventType,
+			Payload:        d.Payload,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			NextAttemptAt:  d.NextAttemptAt,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			CreatedAt:      d.CreatedAt,
+			DeliveredAt:    d.DeliveredAt,
+		}
+	}
+
+	return deliveries, nil
+}
+
+// ReplayDelivery queues a delivery again with a fresh set of attempts.
+func (s *WebhookService) ReplayDelivery(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID, deliveryID uuid.UUID) error {
+	if err := s.checkWebhookAccess(ctx, userID, webhookID); err != nil {
+		return err
+	}
+
+	rowsAffected, err := s.deliveries.ReplayWebhookDelivery(ctx, db.ReplayWebhookDeliveryParams{
+		ID:        deliveryID,
+		WebhookID: webhookID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+// DeliverDue sends one batch of due deliveries and returns how many were claimed.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	due, err := s.deliveries.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+		LeaseSeconds: webhookLeaseSeconds,
+		BatchSize:    webhookBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	for _, d := range due {
+		statusCode, sendErr := s.send(ctx, d)
+
+		var code *int32
+		if statusCode != 0 {
+			c := int32(statusCode)
+			code = &c
+		}
+
+		if sendErr == nil {
+			err = s.deliveries.MarkWebhookDelivered(ctx, db.MarkWebhookDeliveredParams{
+				ID:             d.ID,
+				LastStatusCode: code,
+			})
+		} else {
+			status := dto.DeliveryPending
+			if d.Attempts >= s.maxAttempts {
+				status = dto.DeliveryDead
+			}
+
+			lastError := sendErr.Error()
+			err = s.deliveries.MarkWebhookFailed(ctx, db.MarkWebhookFailedParams{
+				Status:         status,
+				NextAttemptAt:  time.Now().Add(webhookBackoff(d.Attempts)),
+				LastStatusCode: code,
+				LastError:      &lastError,
+				ID:             d.ID,
+			})
+		}
+
+		if err != nil {
+			return 0, fmt.Errorf("failed to update webhook delivery: %w", err)
+		}
+	}
+
+	return len(due), nil
+}
+
+// send posts one delivery. Any 2xx response counts as delivered.
+func (s *WebhookService) send(ctx context.Context, d db.ClaimWebhookDeliveriesRow) (int, error) {
+	// Webhooks created before URLs were checked may use other schemes,
+	// addresses are checked by the client's dialer
+	if u, err := url.Parse(d.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return 0, ErrInvalidWebhookURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.Url, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "axosec-vault-webhooks")
+	req.Header.Set("X-Vault-Event", d.EventType)
+	req.Header.Set("X-Vault-Delivery", d.ID.String())
+	req.Header.Set("X-Vault-Timestamp", timestamp)
+	req.Header.Set("X-Vault-Signature", "sha256="+SignWebhookPayload(d.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// RunDeliveryJob calls DeliverDue every interval until ctx is done. Full
+// batches are followed immediately by the next one.
+func (s *WebhookService) RunDeliveryJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				claimed, err := s.DeliverDue(ctx)
+				if err != nil {
+					slog.ErrorContext(ctx, "webhook delivery job failed", "error", err)
+					break
+				}
+				if claimed < webhookBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
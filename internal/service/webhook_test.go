@@ -0,0 +1,480 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/axosec/vault/internal/data/db"
+	"github.com/axosec/vault/internal/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeDelivery is a webhook_deliveries row joined with its webhook
+type fakeDelivery struct {
+	id             uuid.UUID
+	webhookID      uuid.UUID
+	url            string
+	secret         string
+	eventType      string
+	payload        []byte
+	status         string
+	attempts       int32
+	nextAttemptAt  time.Time
+	lastStatusCode *int32
+	lastError      *string
+}
+
+// fakeQueue mirrors the delivery queries of webhook.sql in memory.
+type fakeQueue struct {
+	mu         sync.Mutex
+	webhooks   []db.Webhook
+	deliveries []*fakeDelivery
+}
+
+func (f *fakeQueue) addWebhook(w db.Webhook) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.webhooks = append(f.webhooks, w)
+}
+
+func (f *fakeQueue) GetWebhook(_ context.Context, id uuid.UUID) (db.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, w := range f.webhooks {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return db.Webhook{}, pgx.ErrNoRows
+}
+
+func (f *fakeQueue) add(d *fakeDelivery) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d.status = dto.DeliveryPending
+	d.nextAttemptAt = time.Now()
+	f.deliveries = append(f.deliveries, d)
+}
+
+// makeDue moves the next attempt of every delivery to now, as if the
+// backoff had passed.
+func (f *fakeQueue) makeDue() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, d := range f.deliveries {
+		d.nextAttemptAt = time.Now()
+	}
+}
+
+func (f *fakeQueue) ClaimWebhookDeliveries(_ context.Context, arg db.ClaimWebhookDeliveriesParams) ([]db.ClaimWebhookDeliveriesRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rows := []db.ClaimWebhookDeliveriesRow{}
+	for _, d := range f.deliveries {
+		if len(rows) == int(arg.BatchSize) {
+			break
+		}
+		if d.status != dto.DeliveryPending || d.nextAttemptAt.After(time.Now()) {
+			continue
+		}
+
+		d.attempts++
+		d.nextAttemptAt = time.Now().Add(time.Duration(arg.LeaseSeconds) * time.Second)
+		rows = append(rows, db.ClaimWebhookDeliveriesRow{
+			ID:        d.id,
+			EventType: d.eventType,
+			Payload:   d.payload,
+			Attempts:  d.attempts,
+			Url:       d.url,
+			Secret:    d.secret,
+		})
+	}
+
+	return rows, nil
+}
+
+func (f *fakeQueue) find(id uuid.UUID) *fakeDelivery {
+	for _, d := range f.deliveries {
+		if d.id == id {
+			return d
+		}
+	}
+	return nil
+}
+
+func (f *fakeQueue) MarkWebhookDelivered(_ context.Context, arg db.MarkWebhookDeliveredParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d := f.find(arg.ID)
+	d.status = dto.DeliveryDelivered
+	d.lastStatusCode = arg.LastStatusCode
+	d.lastError = nil
+	return nil
+}
+
+func (f *fakeQueue) MarkWebhookFailed(_ context.Context, arg db.MarkWebhookFailedParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d := f.find(arg.ID)
+	d.status = arg.Status
+	d.nextAttemptAt = arg.NextAttemptAt
+	d.lastStatusCode = arg.LastStatusCode
+	d.lastError = arg.LastError
+	return nil
+}
+
+func (f *fakeQueue) ReplayWebhookDelivery(_ context.Context, arg db.ReplayWebhookDeliveryParams) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d := f.find(arg.ID)
+	if d == nil || d.webhookID != arg.WebhookID || d.status == dto.DeliveryPending {
+		return 0, nil
+	}
+
+	d.status = dto.DeliveryPending
+	d.attempts = 0
+	d.nextAttemptAt = time.Now()
+	d.lastError = nil
+	return 1, nil
+}
+
+func (f *fakeQueue) get(id uuid.UUID) fakeDelivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return *f.find(id)
+}
+
+func newTestWebhookService(queue deliveryQueue, maxAttempts int32) *WebhookService {
+	return &WebhookService{
+		deliveries:  queue,
+		client:      newWebhookClient(5*time.Second, true),
+		maxAttempts: maxAttempts,
+	}
+}
+
+func newTestDelivery(url string) *fakeDelivery {
+	return &fakeDelivery{
+		id:        uuid.New(),
+		webhookID: uuid.New(),
+		url:       url,
+		secret:    "whsec_test",
+		eventType: dto.EventShared,
+		payload:   []byte(`{"event":"SHARED"}`),
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// printf '1700000000.{"event":"SHARED"}' | openssl dgst -sha256 -hmac whsec_test
+	const want = "0e79e18ffa1beb093c3668f531546acb10d29d934208da2349031f5809f90d64"
+
+	got := SignWebhookPayload("whsec_test", "1700000000", []byte(`{"event":"SHARED"}`))
+	if got != want {
+		t.Fatalf("SignWebhookPayload = %s, want %s", got, want)
+	}
+}
+
+func TestDeliverDueSignsRequests(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan received, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header.Clone(), body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	queue := &fakeQueue{}
+	d := newTestDelivery(srv.URL)
+	queue.add(d)
+
+	claimed, err := newTestWebhookService(queue, 3).DeliverDue(t.Context())
+	if err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+	if claimed != 1 {
+		t.Fatalf("claimed %d deliveries, want 1", claimed)
+	}
+
+	var req received
+	select {
+	case req = <-requests:
+	default:
+		t.Fatalf("no request was sent: %v", queue.get(d.id).lastError)
+	}
+
+	// Check the request the way a receiver would
+	timestamp := req.header.Get("X-Vault-Timestamp")
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("X-Vault-Timestamp %q: %v", timestamp, err)
+	}
+	if age := time.Since(time.Unix(sent, 0)); age < -time.Minute || age > time.Minute {
+		t.Fatalf("X-Vault-Timestamp is %s off", age)
+	}
+
+	signature, ok := strings.CutPrefix(req.header.Get("X-Vault-Signature"), "sha256=")
+	if !ok {
+		t.Fatalf("X-Vault-Signature %q has no sha256= prefix", req.header.Get("X-Vault-Signature"))
+	}
+	mac, err := hex.DecodeString(signature)
+	if err != nil {
+		t.Fatalf("X-Vault-Signature is not hex: %v", err)
+	}
+	expected, _ := hex.DecodeString(SignWebhookPayload(d.secret, timestamp, req.body))
+	if !hmac.Equal(mac, expected) {
+		t.Fatal("X-Vault-Signature does not match the body")
+	}
+
+	if got := req.header.Get("X-Vault-Delivery"); got != d.id.String() {
+		t.Errorf("X-Vault-Delivery = %s, want %s", got, d.id)
+	}
+	if got := req.header.Get("X-Vault-Event"); got != dto.EventShared {
+		t.Errorf("X-Vault-Event = %s, want %s", got, dto.EventShared)
+	}
+
+	if got := queue.get(d.id); got.status != dto.DeliveryDelivered || got.lastStatusCode == nil || *got.lastStatusCode != http.StatusNoContent {
+		t.Fatalf("delivery is %s with status code %v, want DELIVERED with 204", got.status, got.lastStatusCode)
+	}
+}
+
+func TestDeliverDueRetriesWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	queue := &fakeQueue{}
+	d := newTestDelivery(srv.URL)
+	queue.add(d)
+	s := newTestWebhookService(queue, 5)
+
+	for attempt := int32(1); attempt <= 2; attempt++ {
+		before := time.Now()
+		if _, err := s.DeliverDue(t.Context()); err != nil {
+			t.Fatalf("DeliverDue: %v", err)
+		}
+
+		got := queue.get(d.id)
+		if got.status != dto.DeliveryPending {
+			t.Fatalf("attempt %d: status %s, want PENDING", attempt, got.status)
+		}
+		if got.lastStatusCode == nil || *got.lastStatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("attempt %d: last status code %v, want 503", attempt, got.lastStatusCode)
+		}
+
+		wait := webhookBaseBackoff << (attempt - 1)
+		if delay := got.nextAttemptAt.Sub(before); delay < wait || delay > wait+time.Minute {
+			t.Fatalf("attempt %d: next attempt in %s, want %s", attempt, delay, wait)
+		}
+
+		// Not due yet, so nothing is claimed
+		if claimed, _ := s.DeliverDue(t.Context()); claimed != 0 {
+			t.Fatalf("attempt %d: delivery retried before its backoff passed", attempt)
+		}
+
+		queue.makeDue()
+	}
+
+	if _, err := s.DeliverDue(t.Context()); err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+	if got := queue.get(d.id); got.status != dto.DeliveryDelivered || got.attempts != 3 {
+		t.Fatalf("delivery is %s after %d attempts, want DELIVERED after 3", got.status, got.attempts)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{9, 256 * 30 * time.Second},
+		{10, 512 * 30 * time.Second},
+		{11, webhookMaxBackoff},
+		{100, webhookMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverDueMarksDeadAndReplays(t *testing.T) {
+	var healthy atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	const maxAttempts = 3
+
+	ownerID := uuid.New()
+	queue := &fakeQueue{}
+	d := newTestDelivery(srv.URL)
+	queue.addWebhook(db.Webhook{ID: d.webhookID, UserID: &ownerID, Url: d.url, Secret: d.secret})
+	queue.add(d)
+	s := newTestWebhookService(queue, maxAttempts)
+
+	// A pending delivery may be in flight and is not replayed
+	if err := s.ReplayDelivery(t.Context(), ownerID, d.webhookID, d.id); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Fatalf("ReplayDelivery of a pending delivery = %v, want ErrDeliveryNotFound", err)
+	}
+
+	for range maxAttempts {
+		if _, err := s.DeliverDue(t.Context()); err != nil {
+			t.Fatalf("DeliverDue: %v", err)
+		}
+		queue.makeDue()
+	}
+
+	got := queue.get(d.id)
+	if got.status != dto.DeliveryDead || got.attempts != maxAttempts {
+		t.Fatalf("delivery is %s after %d attempts, want DEAD after %d", got.status, got.attempts, maxAttempts)
+	}
+
+	// DEAD deliveries are not claimed again
+	if claimed, _ := s.DeliverDue(t.Context()); claimed != 0 {
+		t.Fatal("DEAD delivery was claimed")
+	}
+
+	healthy.Store(true)
+
+	if err := s.ReplayDelivery(t.Context(), uuid.New(), d.webhookID, d.id); !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("ReplayDelivery by another user = %v, want ErrWebhookNotFound", err)
+	}
+	if err := s.ReplayDelivery(t.Context(), ownerID, uuid.New(), d.id); !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("ReplayDelivery of an unknown webhook = %v, want ErrWebhookNotFound", err)
+	}
+	if got := queue.get(d.id); got.status != dto.DeliveryDead {
+		t.Fatalf("refused replay changed the delivery to %s", got.status)
+	}
+
+	if err := s.ReplayDelivery(t.Context(), ownerID, d.webhookID, d.id); err != nil {
+		t.Fatalf("ReplayDelivery: %v", err)
+	}
+
+	if _, err := s.DeliverDue(t.Context()); err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+	if got := queue.get(d.id); got.status != dto.DeliveryDelivered || got.attempts != 1 {
+		t.Fatalf("replayed delivery is %s after %d attempts, want DELIVERED after 1", got.status, got.attempts)
+	}
+}
+
+func TestWebhookClientRefusesInternalAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// The default client must not reach the loopback test server
+	_, err := newWebhookClient(5*time.Second, false).Get(srv.URL)
+	if !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("GET %s: %v, want errBlockedAddress", srv.URL, err)
+	}
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	var followed atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/target" {
+			followed.Store(true)
+			return
+		}
+		http.Redirect(w, r, "/target", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	queue := &fakeQueue{}
+	d := newTestDelivery(srv.URL + "/hook")
+	queue.add(d)
+
+	if _, err := newTestWebhookService(queue, 3).DeliverDue(t.Context()); err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+
+	if followed.Load() {
+		t.Fatal("redirect was followed")
+	}
+	if got := queue.get(d.id); got.status != dto.DeliveryPending || got.lastStatusCode == nil || *got.lastStatusCode != http.StatusFound {
+		t.Fatalf("delivery is %s with status code %v, want a failed attempt with 302", got.status, got.lastStatusCode)
+	}
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://hooks.example.com/vault", true},
+		{"http://93.184.216.34:8080/hook", true},
+		{"https://[2606:2800:220:1:248:1893:25c8:1946]/hook", true},
+		{"ftp://hooks.example.com/vault", false},
+		{"file:///etc/passwd", false},
+		{"/relative", false},
+		{"http://localhost:9090/metrics", false},
+		{"http://api.localhost/", false},
+		{"http://127.0.0.1:5432/", false},
+		{"http://[::1]/", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://10.0.0.5/", false},
+		{"http://172.16.0.1/", false},
+		{"http://192.168.1.1/", false},
+		{"http://[fd00::1]/", false},
+		{"http://[fe80::1]/", false},
+		{"http://0.0.0.0:8080/", false},
+		{"http://100.64.0.1/", false},
+		{"http://100.127.255.254/", false},
+		{"http://100.128.0.1/", true},
+		{"http://[::ffff:127.0.0.1]/", false},
+	}
+
+	for _, tt := range tests {
+		_, err := checkWebhookURL(tt.url)
+		if tt.ok && err != nil {
+			t.Errorf("checkWebhookURL(%q) = %v, want ok", tt.url, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidWebhookURL) {
+			t.Errorf("checkWebhookURL(%q) = %v, want ErrInvalidWebhookURL", tt.url, err)
+		}
+	}
+}
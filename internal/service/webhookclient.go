@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errBlockedAddress is returned by the webhook dialer for internal addresses
+var errBlockedAddress = errors.New("webhook address is not publicly routable")
+
+// blockedWebhookPrefixes are internal ranges the netip predicates miss
+var blockedWebhookPrefixes = []netip.Prefix{
+	// 0.0.0.0/8 reaches the local host on Linux
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// Carrier-grade NAT, used for internal networks by several clouds
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// blockedWebhookAddr reports whether webhooks must not reach addr: loopback,
+// private, CGNAT, link-local, unspecified and multicast addresses, so a
+// webhook cannot be pointed at the metadata service, Postgres or the metrics
+// port.
+func blockedWebhookAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return true
+	}
+
+	for _, prefix := range blockedWebhookPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkWebhookURL accepts absolute http(s) URLs whose host is not localhost
+// or a blocked IP literal. Hostnames are checked again when dialing, as they
+// may resolve differently by then.
+func checkWebhookURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebhookURL, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: scheme must be http or https", ErrInvalidWebhookURL)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return nil, fmt.Errorf("%w: missing host", ErrInvalidWebhookURL)
+	}
+
+	if host = strings.ToLower(strings.TrimSuffix(host, ".")); host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebhookURL, errBlockedAddress)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil && blockedWebhookAddr(addr) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebhookURL, errBlockedAddress)
+	}
+
+	return u, nil
+}
+
+// resolveWebhookURL is checkWebhookURL plus a lookup of the host, so URLs
+// naming an internal host are refused when the webhook is created.
+func resolveWebhookURL(ctx context.Context, raw string) error {
+	u, err := checkWebhookURL(raw)
+	if err != nil {
+		return err
+	}
+
+	if _, err := netip.ParseAddr(u.Hostname()); err == nil {
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidWebhookURL, err)
+	}
+
+	for _, addr := range addrs {
+		if blockedWebhookAddr(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrInvalidWebhookURL, u.Hostname(), addr)
+		}
+	}
+
+	return nil
+}
+
+// newWebhookClient returns the client deliveries are sent with. Addresses
+// are checked after DNS resolution, right before connecting, so a host that
+// rebinds to an internal address is still refused. Redirects are not
+// followed and no proxy is used, as either would bypass that check.
+// allowInternal lifts the address check for tests against local servers.
+func newWebhookClient(timeout time.Duration, allowInternal bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if network != "tcp4" && network != "tcp6" {
+				return fmt.Errorf("webhook network %s: %w", network, errBlockedAddress)
+			}
+
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("webhook address %s: %w", address, err)
+			}
+
+			if !allowInternal && blockedWebhookAddr(addrPort.Addr()) {
+				return fmt.Errorf("webhook address %s: %w", addrPort.Addr(), errBlockedAddress)
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+		// The 3xx response is returned and counts as a failed attempt
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS keys;
+DROP TABLE IF EXISTS items;
+DROP TABLE IF EXISTS folders;
@@ -0,0 +1,3 @@
+ALTER TABLE keys
+    DROP COLUMN IF EXISTS granted_by,
+    DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,13 @@
+ALTER TABLE keys
+    ADD COLUMN granted_by UUID,
+    ADD COLUMN expires_at TIMESTAMPTZ;
+
+UPDATE keys k
+SET granted_by = f.owner_id
+FROM folders f
+WHERE k.folder_id = f.id;
+
+UPDATE keys k
+SET granted_by = i.owner_id
+FROM items i
+WHERE k.item_id = i.id;
@@ -0,0 +1,4 @@
+-- Duplicate grants removed by the up migration are not restored
+ALTER TABLE keys
+    DROP CONSTRAINT IF EXISTS uq_keys_user_folder,
+    DROP CONSTRAINT IF EXISTS uq_keys_user_item;
@@ -0,0 +1,10 @@
+-- Keep only the most recent grant per user and resource before enforcing uniqueness
+DELETE FROM keys a
+USING keys b
+WHERE a.user_id = b.user_id
+  AND (a.folder_id = b.folder_id OR a.item_id = b.item_id)
+  AND (a.created_at, a.id) < (b.created_at, b.id);
+
+ALTER TABLE keys
+    ADD CONSTRAINT uq_keys_user_folder UNIQUE (user_id, folder_id),
+    ADD CONSTRAINT uq_keys_user_item UNIQUE (user_id, item_id);
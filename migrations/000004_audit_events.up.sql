@@ -0,0 +1,17 @@
+CREATE TABLE audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    actor_id UUID NOT NULL,
+    action VARCHAR(50) NOT NULL,
+
+    resource_type VARCHAR(20),
+    resource_id UUID,
+    -- Owner of the resource when the event happened, so owners can see activity on their resources
+    owner_id UUID,
+    target_user_id UUID,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_events_resource ON audit_events(resource_id, created_at);
+CREATE INDEX idx_audit_events_owner ON audit_events(owner_id, created_at);
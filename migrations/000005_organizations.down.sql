@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_items_org;
+DROP INDEX IF EXISTS idx_folders_org;
+
+ALTER TABLE items DROP COLUMN IF EXISTS org_id;
+ALTER TABLE folders DROP COLUMN IF EXISTS org_id;
+
+DROP TABLE IF EXISTS org_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,25 @@
+CREATE TABLE organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    created_by UUID NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE org_members (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'MEMBER',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (org_id, user_id),
+    CONSTRAINT check_org_role CHECK (role IN ('OWNER', 'ADMIN', 'MEMBER'))
+);
+
+ALTER TABLE folders ADD COLUMN org_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+ALTER TABLE items ADD COLUMN org_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_org_members_user ON org_members(user_id);
+CREATE INDEX idx_folders_org ON folders(org_id);
+CREATE INDEX idx_items_org ON items(org_id);
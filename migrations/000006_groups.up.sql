@@ -0,0 +1,41 @@
+CREATE TABLE groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The group key wrapped for each member
+CREATE TABLE group_members (
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+
+    enc_key BYTEA NOT NULL,
+    nonce BYTEA NOT NULL,
+
+    added_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (group_id, user_id)
+);
+
+-- Folder keys wrapped with a group key
+CREATE TABLE group_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    folder_id UUID NOT NULL REFERENCES folders(id) ON DELETE CASCADE,
+
+    enc_key BYTEA NOT NULL,
+    nonce BYTEA NOT NULL,
+    access_level VARCHAR(20) NOT NULL DEFAULT 'READ',
+
+    granted_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_group_keys_group_folder UNIQUE (group_id, folder_id)
+);
+
+CREATE INDEX idx_groups_org ON groups(org_id);
+CREATE INDEX idx_group_members_user ON group_members(user_id);
+CREATE INDEX idx_group_keys_folder ON group_keys(folder_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS emergency_keys;
+DROP TABLE IF EXISTS emergency_contacts;
@@ -0,0 +1,42 @@
+CREATE TABLE emergency_contacts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    grantor_id UUID NOT NULL,
+    grantee_id UUID NOT NULL,
+    wait_hours INT NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE',
+    requested_at TIMESTAMPTZ,
+    released_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_emergency_contacts_pair UNIQUE (grantor_id, grantee_id),
+    CONSTRAINT check_emergency_status CHECK (status IN ('ACTIVE', 'REQUESTED', 'RELEASED', 'REJECTED'))
+);
+
+-- Resource keys the grantor pre-wrapped for the contact, copied into keys on release
+CREATE TABLE emergency_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    contact_id UUID NOT NULL REFERENCES emergency_contacts(id) ON DELETE CASCADE,
+
+    folder_id UUID REFERENCES folders(id) ON DELETE CASCADE,
+    item_id   UUID REFERENCES items(id)   ON DELETE CASCADE,
+
+    CONSTRAINT check_emergency_resource_target
+        CHECK (
+            (folder_id IS NOT NULL AND item_id IS NULL) OR
+            (folder_id IS NULL AND item_id IS NOT NULL)
+        ),
+
+    enc_key BYTEA NOT NULL,
+    nonce BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_emergency_keys_folder UNIQUE (contact_id, folder_id),
+    CONSTRAINT uq_emergency_keys_item UNIQUE (contact_id, item_id)
+);
+
+CREATE INDEX idx_emergency_contacts_grantee ON emergency_contacts(grantee_id);
+CREATE INDEX idx_emergency_contacts_requested ON emergency_contacts(status, requested_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS recovery_requests;
+DROP TABLE IF EXISTS recovery_enrollments;
+DROP TABLE IF EXISTS org_recovery_policies;
@@ -0,0 +1,47 @@
+-- Opt-in account recovery. The org's recovery key pair is generated client side;
+-- only the public key is stored, the private key stays with the org admins.
+CREATE TABLE org_recovery_policies (
+    org_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    public_key BYTEA NOT NULL,
+    updated_by UUID NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A member's keyring wrapped with the org recovery public key
+CREATE TABLE recovery_enrollments (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    wrapped_keyring BYTEA NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE TABLE recovery_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+
+    -- Public key of the member's re-enrolled device
+    device_public_key BYTEA NOT NULL,
+    -- The keyring re-wrapped for the device, set when the request completes
+    recovered_keyring BYTEA,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    initiated_by UUID NOT NULL,
+    approved_by UUID,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    approved_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+
+    CONSTRAINT check_recovery_status CHECK (status IN ('PENDING', 'APPROVED', 'COMPLETED', 'CANCELLED')),
+    CONSTRAINT check_recovery_second_admin CHECK (approved_by IS NULL OR approved_by <> initiated_by)
+);
+
+CREATE INDEX idx_recovery_requests_org ON recovery_requests(org_id, created_at);
+CREATE INDEX idx_recovery_requests_user ON recovery_requests(user_id);
@@ -0,0 +1,17 @@
+ALTER TABLE audit_events ADD COLUMN org_id UUID;
+ALTER TABLE audit_events ADD COLUMN ip VARCHAR(45);
+ALTER TABLE audit_events ADD COLUMN user_agent TEXT;
+
+UPDATE audit_events e
+SET org_id = COALESCE(
+    (SELECT f.org_id FROM folders f WHERE f.id = e.resource_id),
+    (SELECT i.org_id FROM items i WHERE i.id = e.resource_id)
+)
+WHERE e.resource_type IN ('FOLDER', 'ITEM');
+
+UPDATE audit_events
+SET org_id = resource_id
+WHERE resource_type = 'ORGANIZATION';
+
+CREATE INDEX idx_audit_events_org ON audit_events(org_id, created_at);
+CREATE INDEX idx_audit_events_actor ON audit_events(actor_id, created_at);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS audit_checkpoints;
+
+ALTER TABLE audit_events
+    DROP CONSTRAINT IF EXISTS uq_audit_events_seq,
+    DROP COLUMN IF EXISTS hash,
+    DROP COLUMN IF EXISTS prev_hash,
+    DROP COLUMN IF EXISTS seq;
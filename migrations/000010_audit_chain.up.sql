@@ -0,0 +1,15 @@
+-- Each event stores the hash of the previous one, so editing or deleting a
+-- row breaks the chain. Rows written before this migration stay unchained.
+ALTER TABLE audit_events ADD COLUMN seq BIGINT;
+ALTER TABLE audit_events ADD COLUMN prev_hash BYTEA;
+ALTER TABLE audit_events ADD COLUMN hash BYTEA;
+
+ALTER TABLE audit_events ADD CONSTRAINT uq_audit_events_seq UNIQUE (seq);
+
+-- Chain heads signed with the service RSA key, to detect truncation
+CREATE TABLE audit_checkpoints (
+    seq BIGINT PRIMARY KEY,
+    hash BYTEA NOT NULL,
+    signature BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
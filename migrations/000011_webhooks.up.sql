@@ -0,0 +1,47 @@
+-- A webhook belongs to either a user or an organization
+CREATE TABLE webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    user_id UUID,
+    org_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+
+    url TEXT NOT NULL,
+    -- HMAC-SHA256 key for the X-Vault-Signature header
+    secret TEXT NOT NULL,
+    -- Actions to deliver, empty means every action
+    events TEXT[] NOT NULL DEFAULT '{}',
+
+    created_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT check_webhook_owner
+        CHECK (
+            (user_id IS NOT NULL AND org_id IS NULL) OR
+            (user_id IS NULL AND org_id IS NOT NULL)
+        )
+);
+
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+
+    event_id UUID NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_status_code INT,
+    last_error TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+
+    CONSTRAINT check_webhook_delivery_status CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD'))
+);
+
+CREATE INDEX idx_webhooks_user ON webhooks(user_id);
+CREATE INDEX idx_webhooks_org ON webhooks(org_id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at);
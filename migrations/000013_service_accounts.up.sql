@@ -0,0 +1,47 @@
+-- A service account belongs to either a user or an organization. Resources
+-- are shared with it like with a user, using its id and public key
+CREATE TABLE service_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    user_id UUID,
+    org_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+
+    name VARCHAR(255) NOT NULL,
+    public_key BYTEA NOT NULL,
+
+    created_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT check_service_account_owner
+        CHECK (
+            (user_id IS NOT NULL AND org_id IS NULL) OR
+            (user_id IS NULL AND org_id IS NOT NULL)
+        )
+);
+
+CREATE TABLE api_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    service_account_id UUID NOT NULL REFERENCES service_accounts(id) ON DELETE CASCADE,
+
+    name VARCHAR(255) NOT NULL,
+    -- SHA-256 of the token, the token itself is only shown once
+    token_hash BYTEA NOT NULL UNIQUE,
+    -- First characters of the token so users can tell tokens apart
+    token_prefix VARCHAR(16) NOT NULL,
+
+    -- Scopes, empty lists mean every resource shared with the account
+    read_only BOOLEAN NOT NULL DEFAULT TRUE,
+    folder_ids UUID[] NOT NULL DEFAULT '{}',
+    item_ids UUID[] NOT NULL DEFAULT '{}',
+
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+
+    created_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_service_accounts_user ON service_accounts(user_id);
+CREATE INDEX idx_service_accounts_org ON service_accounts(org_id);
+CREATE INDEX idx_api_tokens_account ON api_tokens(service_account_id);
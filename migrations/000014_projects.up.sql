@@ -0,0 +1,29 @@
+-- Secrets-manager mode. A project groups environments such as dev, staging
+-- and prod; each environment is backed by a folder whose SECRET items hold
+-- the key/value pairs, so access follows the usual folder and item keys
+CREATE TABLE projects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL,
+    org_id UUID REFERENCES organizations(id) ON DELETE SET NULL,
+
+    name VARCHAR(255) NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE environments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    folder_id UUID NOT NULL UNIQUE REFERENCES folders(id) ON DELETE CASCADE,
+
+    -- Plain text so tokens can fetch an environment by name, e.g. "prod"
+    name VARCHAR(64) NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (project_id, name)
+);
+
+CREATE INDEX idx_projects_owner ON projects(owner_id);
+CREATE INDEX idx_projects_org ON projects(org_id);
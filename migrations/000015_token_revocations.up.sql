@@ -0,0 +1,21 @@
+-- Denied JWT ids. Rows are kept until the token would have expired anyway
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(255) PRIMARY KEY,
+    user_id UUID NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    -- NULL when revoked with vaultctl
+    revoked_by UUID,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Tokens of the user issued before revoked_before are rejected
+CREATE TABLE session_revocations (
+    user_id UUID PRIMARY KEY,
+    revoked_before TIMESTAMPTZ NOT NULL,
+
+    revoked_by UUID,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_revoked_tokens_expires ON revoked_tokens(expires_at);
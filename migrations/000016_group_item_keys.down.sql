@@ -0,0 +1,9 @@
+DELETE FROM group_keys WHERE item_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_group_keys_item;
+
+ALTER TABLE group_keys
+    DROP CONSTRAINT IF EXISTS chk_group_keys_target,
+    DROP CONSTRAINT IF EXISTS uq_group_keys_group_item,
+    DROP COLUMN IF EXISTS item_id,
+    ALTER COLUMN folder_id SET NOT NULL;
@@ -0,0 +1,9 @@
+-- Item keys wrapped with a group key, for the items of folders shared with
+-- the group. Exactly one of folder_id and item_id is set
+ALTER TABLE group_keys
+    ALTER COLUMN folder_id DROP NOT NULL,
+    ADD COLUMN item_id UUID REFERENCES items(id) ON DELETE CASCADE,
+    ADD CONSTRAINT uq_group_keys_group_item UNIQUE (group_id, item_id),
+    ADD CONSTRAINT chk_group_keys_target CHECK ((folder_id IS NULL) <> (item_id IS NULL));
+
+CREATE INDEX idx_group_keys_item ON group_keys(item_id);
@@ -0,0 +1,9 @@
+// Package migrations embeds the schema migrations into the binaries.
+package migrations
+
+import "embed"
+
+// FS holds the NNN_name.up.sql and NNN_name.down.sql files
+//
+//go:embed *.sql
+var FS embed.FS